@@ -0,0 +1,75 @@
+// Package api contains the representation of the resources shared by the
+// versions of the API. The version packages convert it where they differ.
+package api
+
+import (
+	"fmt"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/versioning"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// User is the representation of a user shared by the API versions.
+type User struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+
+	Nickname string `json:"nickname" validate:"required,min=1,max=30"`
+	Email    string `json:"email"    validate:"required,email,min=1,max=100"`
+	Phone    string `json:"phone"    validate:"omitempty"`
+}
+
+// UserFromInternal converts a *model.User into a *User.
+func UserFromInternal(u *model.User) *User {
+	return &User{
+		Metadata: u.ObjectMeta,
+		Nickname: u.Nickname,
+		Email:    u.Email,
+		Phone:    u.Phone,
+	}
+}
+
+// ToInternal converts the user into u.
+func (in *User) ToInternal(u *model.User) {
+	u.ObjectMeta = in.Metadata
+	u.Nickname = in.Nickname
+	u.Email = in.Email
+	u.Phone = in.Phone
+}
+
+// UserConverter converts between User and model.User for the API version
+// named Version.
+type UserConverter struct {
+	Version string
+}
+
+var _ versioning.Converter = UserConverter{}
+
+// New returns an empty *User.
+func (c UserConverter) New() interface{} {
+	return &User{}
+}
+
+// FromInternal converts a *model.User into a *User.
+func (c UserConverter) FromInternal(obj interface{}) (interface{}, error) {
+	u, ok := obj.(*model.User)
+	if !ok {
+		return nil, fmt.Errorf("%s: cannot convert %T to User", c.Version, obj)
+	}
+
+	return UserFromInternal(u), nil
+}
+
+// ToInternal converts a *User into the *model.User obj.
+func (c UserConverter) ToInternal(dto interface{}, obj interface{}) error {
+	in, ok := dto.(*User)
+	if !ok {
+		return fmt.Errorf("%s: cannot convert %T from User", c.Version, dto)
+	}
+	u, ok := obj.(*model.User)
+	if !ok {
+		return fmt.Errorf("%s: cannot convert User to %T", c.Version, obj)
+	}
+	in.ToInternal(u)
+
+	return nil
+}
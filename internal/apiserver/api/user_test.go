@@ -0,0 +1,44 @@
+package api
+
+import (
+	"golang-standards-project-example/internal/apiserver/model"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestUserConverter(t *testing.T) {
+	c := UserConverter{Version: "v1"}
+	u := &model.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "a1", ResourceVersion: "3"},
+		Nickname:   "a1",
+		Email:      "a1@email.com",
+		Phone:      "13511235123",
+	}
+
+	dto, err := c.FromInternal(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &model.User{}
+	if err := c.ToInternal(dto, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, u) {
+		t.Errorf("round trip = %+v, want %+v", got, u)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"from other type", func() error { _, err := c.FromInternal(&User{}); return err }()},
+		{"to from other dto", c.ToInternal(u, &model.User{})},
+		{"to other type", c.ToInternal(&User{}, &User{})},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: want an error", tt.name)
+		}
+	}
+}
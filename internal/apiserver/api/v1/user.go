@@ -0,0 +1,11 @@
+// Package v1 contains the conversions of version v1 of the API. Users have
+// the representation shared by the versions, see api.User.
+package v1
+
+import "golang-standards-project-example/internal/apiserver/api"
+
+// User is the v1 representation of a user.
+type User = api.User
+
+// UserConverter converts between User and model.User.
+var UserConverter = api.UserConverter{Version: "v1"}
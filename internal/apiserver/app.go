@@ -22,7 +22,6 @@ func NewApp(basename string) *app.App {
 		basename,
 		app.WithOptions(opts),
 		app.WithDescription(commandDesc),
-		app.WithNoConfig(true),
 		app.WithRunFunc(run(opts)),
 	)
 	return application
@@ -30,17 +29,27 @@ func NewApp(basename string) *app.App {
 
 func run(opts *options.Options) app.RunFunc {
 	return func(basename string) error {
+		completeOptions(basename, opts)
 		cfg, err := config.CreateConfigFromOptions(opts)
 		if err != nil {
 			return err
 		}
-		return Run(cfg)
+		return Run(basename, cfg)
+	}
+}
+
+// completeOptions sets the options defaulting to the basename.
+func completeOptions(basename string, opts *options.Options) {
+	if opts.TraceOptions.ServiceName == "" {
+		opts.TraceOptions.ServiceName = basename
 	}
 }
 
 // Run runs the specified APIServer. This should never exit.
-func Run(cfg *config.Config) error {
-	server, err := NewApiServer(cfg)
+// The options are reloaded from the command line and the environment
+// variables of the application with the basename on SIGHUP.
+func Run(basename string, cfg *config.Config) error {
+	server, err := NewApiServer(basename, cfg)
 	if err != nil {
 		return err
 	}
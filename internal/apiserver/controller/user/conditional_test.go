@@ -0,0 +1,131 @@
+package user
+
+import (
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/openapi"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// itemRoutes registers the routes creating and changing users.
+func itemRoutes(rt *versioning.Router, h *UserController) {
+	rt.POST(testUsers, "/users", openapi.Operation{}, h.Create)
+	rt.GET(testUsers, "/users/:name", openapi.Operation{}, h.Get)
+	rt.PUT(testUsers, "/users/:name", openapi.Operation{}, h.Update)
+	rt.PATCH(testUsers, "/users/:name", openapi.Operation{}, h.Patch)
+	rt.DELETE(testUsers, "/users/:name", openapi.Operation{}, h.Delete)
+	rt.Custom(testUsers, http.MethodPost, "/users/:name", "restore", openapi.Operation{}, h.Restore)
+}
+
+// request returns a request with a JSON body, if body is not empty, and the headers.
+func request(t *testing.T, method, url, body string, header ...string) *http.Request {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	return req
+}
+
+func TestCreate(t *testing.T) {
+	srv := newTestServer(t, itemRoutes)
+
+	tests := []struct {
+		path     string
+		name     string
+		location string
+	}{
+		{"/v2/users", "a1", "/v2/users/a1"},
+		{"/users", "a2", "/users/a2"},
+		{"/v1/users", "a 3", "/v1/users/a%203"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body := `{"metadata":{"name":"` + tt.name + `"},"nickname":"n","email":"n@email.com"}`
+			resp := do(t, request(t, http.MethodPost, srv.URL+tt.path, body), nil)
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("status = %d, want 201", resp.StatusCode)
+			}
+			if got := resp.Header.Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+			if resp.Header.Get("ETag") == "" {
+				t.Error("ETag is not set")
+			}
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	srv := newTestServer(t, itemRoutes)
+	user := srv.URL + "/v2/users/a1"
+	etag := func() string {
+		return do(t, request(t, http.MethodGet, user, ""), nil).Header.Get("ETag")
+	}
+	text := func(s string) func() string { return func() string { return s } }
+	body := text(`{"nickname":"n","email":"n@email.com"}`)
+	if resp := do(t, request(t, http.MethodPost, srv.URL+"/v2/users",
+		`{"metadata":{"name":"a1"},"nickname":"n","email":"n@email.com"}`), nil); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status = %d", resp.StatusCode)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		body    func() string
+		ifMatch func() string
+		status  int
+	}{
+		{"put without If-Match", http.MethodPut, user, body, nil, http.StatusPreconditionRequired},
+		{"put with stale If-Match", http.MethodPut, user, body, func() string { return `"999"` }, http.StatusPreconditionFailed},
+		{"put with If-Match", http.MethodPut, user, body, etag, http.StatusOK},
+		{"put with resourceVersion", http.MethodPut, user, func() string {
+			return `{"metadata":{"resourceVersion":"` + strings.Trim(etag(), `"`) + `"},"nickname":"m","email":"n@email.com"}`
+		}, nil, http.StatusOK},
+		{"patch without If-Match", http.MethodPatch, user, text(`{"nickname":"p"}`), nil, http.StatusPreconditionRequired},
+		{"patch with If-Match", http.MethodPatch, user, text(`{"nickname":"p"}`), etag, http.StatusOK},
+		{"patch with any", http.MethodPatch, user, text(`{"nickname":"q"}`), func() string { return "*" }, http.StatusOK},
+		{"delete without If-Match", http.MethodDelete, user, text(""), nil, http.StatusPreconditionRequired},
+		{"delete with stale If-Match", http.MethodDelete, user, text(""), func() string { return `"999"` }, http.StatusPreconditionFailed},
+		{"delete with If-Match", http.MethodDelete, user, text(""), etag, http.StatusNoContent},
+		{"restore without If-Match", http.MethodPost, user + ":restore", text(""), nil, http.StatusPreconditionRequired},
+		{"restore with any", http.MethodPost, user + ":restore", text(""), func() string { return "*" }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := request(t, tt.method, tt.url, tt.body())
+			if tt.method == http.MethodPatch {
+				req.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			if tt.ifMatch != nil {
+				req.Header.Set("If-Match", tt.ifMatch())
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, data)
+			}
+			if tt.status == http.StatusNoContent && len(data) != 0 {
+				t.Errorf("body = %q, want none", data)
+			}
+		})
+	}
+}
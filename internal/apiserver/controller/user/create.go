@@ -0,0 +1,34 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"net/url"
+	"path"
+)
+
+// Create add new user to the storage. It answers 201 with the URL of the
+// user in the Location header.
+func (h *UserController) Create(c *gin.Context) {
+	var user model.User
+	if err := versioning.Bind(c, &user); err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	if err := user.Validate(); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation, "%s", err.Error()), nil)
+		return
+	}
+
+	if err := h.store.Users().Create(c.Request.Context(), &user); err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	core.SetETag(c, user.ResourceVersion)
+	versioning.WriteCreated(c, path.Join(c.Request.URL.Path, url.PathEscape(user.Name)), &user)
+}
@@ -0,0 +1,23 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"net/http"
+)
+
+// Delete delete an user by the user identifier, if it matches the If-Match
+// header, which is required. It answers 204 No Content.
+func (h *UserController) Delete(c *gin.Context) {
+	pre, err := preconditions(c)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	if err := h.store.Users().Delete(c.Request.Context(), c.Param("name"), pre); err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
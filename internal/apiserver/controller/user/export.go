@@ -0,0 +1,109 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/stream"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// Trailers of export responses.
+const (
+	trailerExportRows   = "X-Export-Rows"
+	trailerExportStatus = "X-Export-Status"
+)
+
+// Statuses of exports.
+const (
+	exportComplete = "complete"
+	exportAborted  = "aborted"
+)
+
+// exportPageSize is the number of users read from the store at once.
+const exportPageSize = 500
+
+// csvColumns are the columns of CSV exports if the request selects no fields.
+var csvColumns = []string{
+	"metadata.name", "metadata.labels", "nickname", "email", "phone",
+	"metadata.id", "metadata.createdAt", "metadata.updatedAt", "metadata.resourceVersion",
+}
+
+// Export streams the users matching the list options as NDJSON or, if the
+// Accept header prefers it, CSV, reading them from the store page by page.
+// The number of rows is sent in the trailers.
+func (h *UserController) Export(c *gin.Context) {
+	var opts metav1.ListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrBind, "%s", err.Error()), nil)
+		return
+	}
+	if err := opts.Validate(); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation, "%s", err.Error()), nil)
+		return
+	}
+	if opts.Watch {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation, "exports cannot watch"), nil)
+		return
+	}
+	opts.Limit = exportPageSize
+
+	format := stream.Negotiate(c.GetHeader("Accept"))
+	columns := opts.FieldSet()
+	if format == stream.MIMECSV && len(columns) == 0 {
+		columns = csvColumns
+	}
+
+	rows, status := 0, exportComplete
+	enc := stream.NewEncoder(format, c.Writer, columns)
+	for {
+		list, err := h.store.Users().List(c.Request.Context(), opts)
+		if err != nil && rows == 0 {
+			versioning.WriteResponse(c, err, nil)
+			return
+		}
+		if rows == 0 {
+			c.Header("Content-Type", format)
+			c.Header("Trailer", trailerExportRows+", "+trailerExportStatus)
+			c.Status(http.StatusOK)
+		}
+		if err == nil {
+			err = h.exportPage(c, enc, list.Items)
+		}
+		if err != nil {
+			log.Printf("export users: %v\n", err)
+			status = exportAborted
+			break
+		}
+		rows += len(list.Items)
+		c.Writer.Flush()
+
+		if list.NextCursor == "" {
+			break
+		}
+		opts.Cursor, opts.Offset = list.NextCursor, 0
+	}
+
+	c.Header(trailerExportRows, strconv.Itoa(rows))
+	c.Header(trailerExportStatus, status)
+}
+
+// exportPage writes the users in the representation of the API version.
+func (h *UserController) exportPage(c *gin.Context, enc stream.Encoder, users []*model.User) error {
+	for _, user := range users {
+		dto, err := versioning.ToDTO(c, user)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(dto); err != nil {
+			return err
+		}
+	}
+
+	return enc.Flush()
+}
@@ -0,0 +1,23 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+)
+
+// Get get an user by the user identifier. It answers 304 if the If-None-Match
+// header matches the ETag of the user.
+func (h *UserController) Get(c *gin.Context) {
+	user, err := h.store.Users().Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	if core.NotModified(c, user.ResourceVersion) {
+		return
+	}
+
+	core.SetETag(c, user.ResourceVersion)
+	versioning.WriteResponse(c, nil, user)
+}
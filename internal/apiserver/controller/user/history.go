@@ -0,0 +1,19 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/versioning"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// History lists the versions of a user, newest first, including the deleted
+// ones. metadata.updatedBy of each version is who made it.
+func (h *UserController) History(c *gin.Context) {
+	versions, err := h.store.Users().History(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	versioning.WriteList(c, nil, metav1.ListMeta{TotalCount: int64(len(versions))}, versions, nil)
+}
@@ -0,0 +1,134 @@
+package user
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/stream"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Trailers of import responses.
+const (
+	trailerImportRows    = "X-Import-Rows"
+	trailerImportCreated = "X-Import-Created"
+	trailerImportFailed  = "X-Import-Failed"
+	trailerImportStatus  = "X-Import-Status"
+)
+
+// Statuses of import rows and of the import.
+const (
+	importCreated  = "created"
+	importFailed   = "failed"
+	importComplete = "complete"
+	importAborted  = "aborted"
+)
+
+// ImportResult is the outcome of a row of an import.
+type ImportResult struct {
+	// Row is the line of an NDJSON stream or the record of a CSV stream, starting at 1.
+	Row    int    `json:"row"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	// Error is the error of a failed row.
+	Error *core.ErrResponse `json:"error,omitempty"`
+	// Detail describes what is wrong with the row if it is malformed or invalid.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Import creates the users of an NDJSON or CSV stream and streams the result
+// of each row as NDJSON while reading the next ones. The counts of rows are
+// sent in the trailers.
+func (h *UserController) Import(c *gin.Context) {
+	format, ok := stream.FormatOf(c.GetHeader("Content-Type"))
+	if !ok {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrUnsupportedMediaType,
+			"content type %q is neither %s nor %s", c.GetHeader("Content-Type"), stream.MIMENDJSON, stream.MIMECSV), nil)
+		return
+	}
+	// HTTP/1.1 stops reading the request once the response is written, unless full duplex
+	_ = http.NewResponseController(c.Writer).EnableFullDuplex()
+
+	c.Header("Content-Type", stream.MIMENDJSON)
+	c.Header("Trailer", trailerImportRows+", "+trailerImportCreated+", "+trailerImportFailed+", "+trailerImportStatus)
+	c.Status(http.StatusOK)
+
+	var rows, created, failed int
+	status := importComplete
+	dec := stream.NewDecoder(format, c.Request.Body)
+	enc := json.NewEncoder(c.Writer)
+	for {
+		var user model.User
+		err := versioning.Decode(c, &user, dec.Decode)
+		if err == io.EOF {
+			break
+		}
+		result := &ImportResult{Row: dec.Row(), Name: user.Name, Status: importCreated}
+		if _, ok := err.(*stream.RowError); err != nil && !ok {
+			// the stream cannot be read on from the next row
+			result.Row, result.Status, status = dec.Row()+1, importAborted, importAborted
+			h.importFailed(c, result, streamError(err))
+			_ = enc.Encode(result)
+			break
+		}
+
+		rows++
+		if err == nil {
+			err = h.importUser(c, &user)
+		}
+		if err != nil {
+			failed++
+			result.Status = importFailed
+			h.importFailed(c, result, err)
+		} else {
+			created++
+		}
+		if err := enc.Encode(result); err != nil {
+			status = importAborted
+			break
+		}
+		c.Writer.Flush()
+	}
+
+	c.Header(trailerImportRows, strconv.Itoa(rows))
+	c.Header(trailerImportCreated, strconv.Itoa(created))
+	c.Header(trailerImportFailed, strconv.Itoa(failed))
+	c.Header(trailerImportStatus, status)
+}
+
+// importUser validates and creates a user of an import.
+func (h *UserController) importUser(c *gin.Context, user *model.User) error {
+	if err := user.Validate(); err != nil {
+		return errors.WrapC(err, code.ErrValidation, "%s", err.Error())
+	}
+
+	return h.store.Users().Create(c.Request.Context(), user)
+}
+
+// streamError returns err with the code of the reason the stream could not be read.
+func streamError(err error) error {
+	if limit, ok := core.IsBodyTooLarge(err); ok {
+		return errors.WrapC(err, code.ErrRequestEntityTooLarge, "request body exceeds the limit of %d bytes", limit)
+	}
+
+	return errors.WrapC(err, code.ErrBind, "%s", err.Error())
+}
+
+// importFailed sets the error of the result.
+func (h *UserController) importFailed(c *gin.Context, result *ImportResult, err error) {
+	if _, ok := err.(*stream.RowError); ok {
+		err = streamError(err)
+	}
+	resp := core.NewErrResponse(c, err)
+	result.Error = &resp
+	if cause, ok := err.(interface{ Cause() error }); ok && cause.Cause() != nil &&
+		errors.ParseCoder(err).HTTPStatus() < http.StatusInternalServerError {
+		result.Detail = cause.Cause().Error()
+	}
+}
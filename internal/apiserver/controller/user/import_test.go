@@ -0,0 +1,148 @@
+package user
+
+import (
+	"bufio"
+	"encoding/json"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/stream"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/openapi"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// importResult is the row, status and error code of an ImportResult.
+type importResult struct {
+	row    int
+	status string
+	code   int
+}
+
+func TestImport(t *testing.T) {
+	srv := newTestServer(t, func(rt *versioning.Router, h *UserController) {
+		rt.Custom(testUsers, http.MethodPost, "/users", "import", openapi.Operation{}, h.Import)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []importResult
+		trailers    map[string]string
+	}{
+		{
+			name:        "ndjson",
+			contentType: stream.MIMENDJSON,
+			body: `{"metadata":{"name":"a"},"nickname":"A","email":"a@example.com"}` + "\n" +
+				"{oops\n" +
+				`{"metadata":{"name":"b"},"nickname":"B"}` + "\n" +
+				"\n" +
+				`{"metadata":{"name":"a"},"nickname":"A","email":"a@example.com"}` + "\n" +
+				`{"metadata":{"name":"c"},"nickname":"C","email":"c@example.com"}` + "\n",
+			want: []importResult{
+				{row: 1, status: importCreated},
+				{row: 2, status: importFailed, code: code.ErrBind},
+				{row: 3, status: importFailed, code: code.ErrValidation},
+				{row: 5, status: importFailed, code: code.ErrUserAlreadyExist},
+				{row: 6, status: importCreated},
+			},
+			trailers: map[string]string{
+				trailerImportRows: "5", trailerImportCreated: "2", trailerImportFailed: "3", trailerImportStatus: importComplete,
+			},
+		},
+		{
+			name:        "csv",
+			contentType: stream.MIMECSV,
+			body: "metadata.name,nickname,email\n" +
+				"d,D,d@example.com\n" +
+				"e,E\n" +
+				"f,F,not an email\n" +
+				"g,G,g@example.com\n",
+			want: []importResult{
+				{row: 1, status: importCreated},
+				{row: 2, status: importFailed, code: code.ErrBind},
+				{row: 3, status: importFailed, code: code.ErrValidation},
+				{row: 4, status: importCreated},
+			},
+			trailers: map[string]string{
+				trailerImportRows: "4", trailerImportCreated: "2", trailerImportFailed: "2", trailerImportStatus: importComplete,
+			},
+		},
+		{
+			name:        "csv without header",
+			contentType: stream.MIMECSV,
+			body:        "",
+			trailers: map[string]string{
+				trailerImportRows: "0", trailerImportCreated: "0", trailerImportFailed: "0", trailerImportStatus: importComplete,
+			},
+		},
+		{
+			name:        "csv with a broken quote",
+			contentType: stream.MIMECSV,
+			body:        "metadata.name,nickname,email\nh,H,h@example.com\n\"i,I,i@example.com\n",
+			want: []importResult{
+				{row: 1, status: importCreated},
+				{row: 2, status: importFailed, code: code.ErrBind},
+			},
+			trailers: map[string]string{
+				trailerImportRows: "2", trailerImportCreated: "1", trailerImportFailed: "1", trailerImportStatus: importComplete,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := request(t, http.MethodPost, srv.URL+"/v2/users:import", tt.body, "Content-Type", tt.contentType)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != stream.MIMENDJSON {
+				t.Fatalf("status = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			var got []importResult
+			s := bufio.NewScanner(resp.Body)
+			for s.Scan() {
+				var result ImportResult
+				if err := json.Unmarshal(s.Bytes(), &result); err != nil {
+					t.Fatalf("decode %s: %v", s.Bytes(), err)
+				}
+				r := importResult{row: result.Row, status: result.Status}
+				if result.Error != nil {
+					r.code = result.Error.Code
+				}
+				got = append(got, r)
+			}
+			if err := s.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %+v, want %+v", got, tt.want)
+			}
+			// the trailers are only available once the body is read
+			for name, want := range tt.trailers {
+				if v := resp.Trailer.Get(name); v != want {
+					t.Errorf("trailer %s = %q, want %q", name, v, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportUnsupportedContentType(t *testing.T) {
+	srv := newTestServer(t, func(rt *versioning.Router, h *UserController) {
+		rt.Custom(testUsers, http.MethodPost, "/users", "import", openapi.Operation{}, h.Import)
+	})
+
+	req := request(t, http.MethodPost, srv.URL+"/users:import", `{"metadata":{"name":"a"}}`)
+	resp := do(t, req, nil)
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnsupportedMediaType)
+	}
+	if strings.Contains(resp.Header.Get("Trailer"), trailerImportRows) {
+		t.Error("rejected import announced trailers")
+	}
+}
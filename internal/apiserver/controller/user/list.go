@@ -0,0 +1,39 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// List list the users in the storage, filtered, sorted and paginated by the
+// list options in the query, or watches them if the options ask to.
+func (h *UserController) List(c *gin.Context) {
+	var opts metav1.ListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrBind, "%s", err.Error()), nil)
+		return
+	}
+	if opts.Watch && opts.ResourceVersion == "" {
+		// EventSource resumes with the id of the last event
+		opts.ResourceVersion = c.GetHeader(headerLastEventID)
+	}
+	if err := opts.Validate(); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation, "%s", err.Error()), nil)
+		return
+	}
+	if opts.Watch {
+		h.watch(c, opts)
+		return
+	}
+
+	users, err := h.store.Users().List(c.Request.Context(), opts)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	versioning.WriteList(c, nil, users.ListMeta, users.Items, opts.FieldSet())
+}
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// maxPatchRetries is the number of times a patch is applied again after a
+// concurrent update, if the client sent "If-Match: *".
+const maxPatchRetries = 5
+
+// Patch applies a JSON Patch or JSON Merge Patch to an user. The user must
+// match the If-Match header, which is required, and the patch must not
+// change its resourceVersion.
+func (h *UserController) Patch(c *gin.Context) {
+	patch, err := versioning.ReadPatch(c)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	pre, err := preconditions(c)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	name := c.Param("name")
+	for attempt := 0; ; attempt++ {
+		var user *model.User
+		user, err = h.patch(c, name, pre, patch)
+		if errors.IsCode(err, code.ErrPreconditionFailed) && len(pre.ResourceVersions) == 0 && attempt < maxPatchRetries {
+			continue
+		}
+		if err != nil {
+			versioning.WriteResponse(c, err, nil)
+			return
+		}
+
+		core.SetETag(c, user.ResourceVersion)
+		versioning.WriteResponse(c, nil, user)
+		return
+	}
+}
+
+// patch applies the patch to the stored user and updates it, if it was not
+// modified in the meantime.
+func (h *UserController) patch(c *gin.Context, name string, pre *metav1.Preconditions, patch *versioning.Patch) (*model.User, error) {
+	user, err := h.store.Users().Get(c.Request.Context(), name)
+	if err != nil {
+		return nil, err
+	}
+	if err := pre.Check(user.ResourceVersion); err != nil {
+		return nil, errors.WrapC(err, code.ErrPreconditionFailed, "patch user %q", name)
+	}
+
+	current := user.ResourceVersion
+	if err := patch.Apply(user); err != nil {
+		return nil, err
+	}
+	if user.Name != name {
+		return nil, errors.WithCode(code.ErrValidation, "metadata.name of user %q cannot be changed", name)
+	}
+	if user.ResourceVersion != current {
+		return nil, errors.WithCode(code.ErrPreconditionFailed,
+			"resource version %s of user %q does not match %s", current, name, user.ResourceVersion)
+	}
+	if err := user.Validate(); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "%s", err.Error())
+	}
+
+	err = h.store.Users().Update(c.Request.Context(), user, &metav1.Preconditions{ResourceVersions: []string{current}})
+
+	return user, err
+}
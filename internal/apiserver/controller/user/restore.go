@@ -0,0 +1,25 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+)
+
+// Restore restores a deleted user, if it matches the If-Match header.
+// Requests without If-Match fail with ErrPreconditionRequired.
+func (h *UserController) Restore(c *gin.Context) {
+	pre, err := preconditions(c)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	user, err := h.store.Users().Restore(c.Request.Context(), c.Param("name"), pre)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	core.SetETag(c, user.ResourceVersion)
+	versioning.WriteResponse(c, nil, user)
+}
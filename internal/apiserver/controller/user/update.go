@@ -0,0 +1,50 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// Update replaces an user. The If-Match header or, if absent, the
+// resourceVersion of the body must match the current version of the user,
+// one of them is required.
+func (h *UserController) Update(c *gin.Context) {
+	var user model.User
+	if err := versioning.Bind(c, &user); err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	name := c.Param("name")
+	if user.Name != "" && user.Name != name {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation,
+			"metadata.name %q does not match the user %q", user.Name, name), nil)
+		return
+	}
+	user.Name = name
+	if err := user.Validate(); err != nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrValidation, "%s", err.Error()), nil)
+		return
+	}
+
+	pre := core.Preconditions(c)
+	if pre == nil && user.ResourceVersion != "" {
+		pre = &metav1.Preconditions{ResourceVersions: []string{user.ResourceVersion}}
+	}
+	if pre == nil {
+		versioning.WriteResponse(c, errors.WithCode(code.ErrPreconditionRequired,
+			"update of user %q requires If-Match or metadata.resourceVersion", name), nil)
+		return
+	}
+	if err := h.store.Users().Update(c.Request.Context(), &user, pre); err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+
+	core.SetETag(c, user.ResourceVersion)
+	versioning.WriteResponse(c, nil, &user)
+}
@@ -0,0 +1,51 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/apiserver/store"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+// UserController serves the user resource in all API versions.
+type UserController struct {
+	store store.Factory
+}
+
+// NewUserController creates a user handler.
+func NewUserController(store store.Factory) *UserController {
+	return &UserController{store: store}
+}
+
+// preconditions returns the preconditions of the If-Match header. Users
+// are only changed conditionally, so a client never overwrites a change it
+// has not seen: requests without If-Match fail with ErrPreconditionRequired,
+// "*" matches any version.
+func preconditions(c *gin.Context) (*metav1.Preconditions, error) {
+	pre := core.Preconditions(c)
+	if pre == nil {
+		return nil, errors.WithCode(code.ErrPreconditionRequired, "%s of user %q requires If-Match",
+			c.Request.Method, c.Param("name"))
+	}
+
+	return pre, nil
+}
+
+// hello holds the sample user of each API version.
+var hello = map[string]*model.User{
+	"v1": {Nickname: "a1", Email: "a1@email.com", Phone: "13511235123"},
+	"v2": {Nickname: "a2", Email: "a2@email.com", Phone: "13511235123"},
+}
+
+// Hello responds with the sample user of the API version of the request.
+func (h *UserController) Hello(c *gin.Context) {
+	u := hello["v2"]
+	if v, ok := versioning.FromContext(c); ok && hello[v.Name] != nil {
+		u = hello[v.Name]
+	}
+	versioning.WriteResponse(c, nil, u)
+}
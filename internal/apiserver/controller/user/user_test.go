@@ -0,0 +1,91 @@
+package user
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	v1 "golang-standards-project-example/internal/apiserver/api/v1"
+	v2 "golang-standards-project-example/internal/apiserver/api/v2"
+	"golang-standards-project-example/internal/apiserver/store/memory"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/openapi"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// testUsers is the user resource of the API server.
+var testUsers = versioning.NewResource("users",
+	versioning.Version{Name: "v1", Converter: v1.UserConverter, Deprecated: time.Unix(0, 0)},
+	versioning.Version{Name: "v2", Converter: v2.UserConverter},
+)
+
+// newTestServer serves the routes registered by routes on a memory store.
+func newTestServer(t *testing.T, routes func(rt *versioning.Router, h *UserController)) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	// the errors of the responses are logged
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	engine := gin.New()
+	rt := versioning.NewRouter(&engine.RouterGroup, openapi.NewSpec(openapi.Info{}))
+	routes(rt, NewUserController(memory.NewFactory()))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		engine.ServeHTTP(w, rt.Rewrite(r))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// do sends the request and decodes the JSON response body into out, if not nil.
+func do(t *testing.T, req *http.Request, out interface{}) *http.Response {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+	}
+
+	return resp
+}
+
+func TestHello(t *testing.T) {
+	srv := newTestServer(t, func(rt *versioning.Router, h *UserController) {
+		rt.GET(testUsers, "/hello", openapi.Operation{}, h.Hello)
+	})
+
+	tests := []struct {
+		path     string
+		nickname string
+	}{
+		{"/v1/hello", "a1"},
+		{"/v2/hello", "a2"},
+		{"/hello", "a2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			var got v2.User
+			if resp := do(t, req, &got); resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d", resp.StatusCode)
+			}
+			if got.Nickname != tt.nickname {
+				t.Errorf("nickname = %q, want %q", got.Nickname, tt.nickname)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"golang-standards-project-example/internal/pkg/versioning"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"golang.org/x/net/websocket"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// mimeEventStream is the media type of Server-Sent Events.
+	mimeEventStream = "text/event-stream"
+	// headerLastEventID is the header EventSource resumes with.
+	headerLastEventID = "Last-Event-ID"
+	// watchHeartbeat is the interval of heartbeats keeping idle watches open
+	// through proxies.
+	watchHeartbeat = 15 * time.Second
+)
+
+// eventSink writes the events of a watch to the client.
+type eventSink interface {
+	// Event writes the event of the resource version.
+	Event(e watch.Event, resourceVersion string) error
+	// Heartbeat writes a message without an event.
+	Heartbeat() error
+}
+
+// watch streams the changes of the users matching the list options as
+// Server-Sent Events or, if the request upgrades, WebSocket messages,
+// until the client goes away, the server shuts down or the watch falls too
+// far behind.
+func (h *UserController) watch(c *gin.Context, opts metav1.ListOptions) {
+	w, err := h.store.Users().Watch(c.Request.Context(), opts)
+	if err != nil {
+		versioning.WriteResponse(c, err, nil)
+		return
+	}
+	defer w.Stop()
+
+	if c.IsWebsocket() {
+		server := websocket.Server{
+			// browsers send Origin, CORS does not apply to WebSocket
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(ws *websocket.Conn) {
+				closed := make(chan struct{})
+				go func() {
+					// discard messages, handle pings and notice the client closing
+					_, _ = io.Copy(io.Discard, ws)
+					close(closed)
+				}()
+				serveWatch(c, w, &wsSink{ws: ws}, closed)
+				_ = ws.Close()
+			},
+		}
+		server.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
+	c.Header("Content-Type", mimeEventStream)
+	c.Header("Cache-Control", "no-cache")
+	// disable response buffering of nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	serveWatch(c, w, &sseSink{w: c.Writer}, c.Request.Context().Done())
+}
+
+// serveWatch writes the events of w to sink until closed is closed, the
+// server drains or w ends.
+func serveWatch(c *gin.Context, w watch.Interface, sink eventSink, closed <-chan struct{}) {
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			dto, err := versioning.ToDTO(c, e.Object)
+			if err != nil {
+				log.Printf("watch users: %v\n", err)
+				return
+			}
+			if err := sink.Event(watch.Event{Type: e.Type, Object: dto}, e.Object.(*model.User).ResourceVersion); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := sink.Heartbeat(); err != nil {
+				return
+			}
+		case <-middleware.DrainingFrom(c):
+			return
+		case <-closed:
+			return
+		}
+	}
+}
+
+// sseSink writes events as Server-Sent Events whose id is the resource version.
+type sseSink struct {
+	w gin.ResponseWriter
+}
+
+func (s *sseSink) Event(e watch.Event, resourceVersion string) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", resourceVersion, e.Type, data); err != nil {
+		return err
+	}
+	s.w.Flush()
+
+	return nil
+}
+
+func (s *sseSink) Heartbeat() error {
+	if _, err := io.WriteString(s.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	s.w.Flush()
+
+	return nil
+}
+
+// ping sends WebSocket ping frames. Unlike setting the PayloadType of the
+// connection it leaves the frames of other messages alone.
+var ping = websocket.Codec{Marshal: func(interface{}) ([]byte, byte, error) {
+	return nil, websocket.PingFrame, nil
+}}
+
+// wsSink writes events as WebSocket text messages and heartbeats as pings.
+type wsSink struct {
+	ws *websocket.Conn
+}
+
+func (s *wsSink) Event(e watch.Event, _ string) error {
+	return websocket.JSON.Send(s.ws, e)
+}
+
+func (s *wsSink) Heartbeat() error {
+	return ping.Send(s.ws, nil)
+}
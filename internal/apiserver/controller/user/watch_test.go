@@ -0,0 +1,143 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"golang-standards-project-example/internal/pkg/versioning"
+	"golang-standards-project-example/pkg/openapi"
+	"golang-standards-project-example/pkg/watch"
+	"golang.org/x/net/websocket"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is a Server-Sent Event.
+type sseEvent struct {
+	id, event string
+}
+
+// readEvents reads n Server-Sent Events of the watch request.
+func readEvents(t *testing.T, req *http.Request, n int) []sseEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	var events []sseEvent
+	var e sseEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case line == "" && e.id != "":
+			events = append(events, e)
+			e = sseEvent{}
+		}
+	}
+	if len(events) < n {
+		t.Fatalf("got %v, want %d events: %v", events, n, scanner.Err())
+	}
+
+	return events
+}
+
+func TestWatchResume(t *testing.T) {
+	srv := newTestServer(t, func(rt *versioning.Router, h *UserController) {
+		itemRoutes(rt, h)
+		rt.GET(testUsers, "/users", openapi.Operation{}, h.List)
+	})
+	for _, name := range []string{"a", "b"} {
+		body := `{"metadata":{"name":"` + name + `"},"nickname":"n","email":"n@email.com"}`
+		do(t, request(t, http.MethodPost, srv.URL+"/v2/users", body), nil)
+	}
+	do(t, request(t, http.MethodPatch, srv.URL+"/v2/users/a", `{"nickname":"m"}`,
+		"Content-Type", "application/merge-patch+json", "If-Match", "*"), nil)
+
+	tests := []struct {
+		name   string
+		url    string
+		header []string
+		want   []sseEvent
+	}{
+		{"snapshot", "/v2/users?watch=true", nil, []sseEvent{{"2", "ADDED"}, {"3", "ADDED"}}},
+		{"resume after the first", "/v2/users?watch=true", []string{"Last-Event-ID", "2"}, []sseEvent{{"3", "MODIFIED"}}},
+		{"resume from the start", "/v2/users?watch=true&resourceVersion=0", nil,
+			[]sseEvent{{"1", "ADDED"}, {"2", "ADDED"}, {"3", "MODIFIED"}}},
+		{"query before header", "/v2/users?watch=true&resourceVersion=1", []string{"Last-Event-ID", "2"},
+			[]sseEvent{{"2", "ADDED"}, {"3", "MODIFIED"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := request(t, http.MethodGet, srv.URL+tt.url, "", tt.header...)
+			if got := readEvents(t, req, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// a future resource version is rejected before the stream starts
+	req := request(t, http.MethodGet, srv.URL+"/v2/users?watch=true", "", "Last-Event-ID", "9")
+	if resp := do(t, req, nil); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestWSSinkHeartbeat(t *testing.T) {
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		sink := &wsSink{ws: ws}
+		for i := 0; i < 2; i++ {
+			if err := sink.Heartbeat(); err != nil {
+				t.Error(err)
+			}
+			if ws.PayloadType != websocket.TextFrame {
+				t.Errorf("PayloadType = %d after a heartbeat", ws.PayloadType)
+			}
+			if err := sink.Event(watch.Event{Type: watch.Added, Object: i}, ""); err != nil {
+				t.Error(err)
+			}
+		}
+		_ = websocket.Message.Send(ws, "text")
+		// keep the connection open until the client answered the pings
+		_, _ = io.Copy(io.Discard, ws)
+	}))
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	for i := 0; i < 2; i++ {
+		var e struct {
+			Type   watch.EventType
+			Object int
+		}
+		// the client answers the pings and skips them
+		if err := websocket.JSON.Receive(ws, &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type != watch.Added || e.Object != i {
+			t.Errorf("event = %+v", e)
+		}
+	}
+	var msg string
+	if err := websocket.Message.Receive(ws, &msg); err != nil || msg != "text" {
+		t.Errorf("message = %q, %v, want a text frame", msg, err)
+	}
+}
@@ -1,7 +1,28 @@
 package model
 
+import (
+	"github.com/go-playground/validator/v10"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+)
+
+var validate = validator.New()
+
 type User struct {
+	metav1.ObjectMeta `json:"metadata"`
+
 	Nickname string `json:"nickname" gorm:"column:nickname" validate:"required,min=1,max=30"`
 	Email    string `json:"email" gorm:"column:email" validate:"required,email,min=1,max=100"`
 	Phone    string `json:"phone" gorm:"column:phone" validate:"omitempty"`
 }
+
+// UserList is a page of users.
+type UserList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*User `json:"items"`
+}
+
+// Validate checks the user against the rules of its validate tags.
+func (u *User) Validate() error {
+	return validate.Struct(u)
+}
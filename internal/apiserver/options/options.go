@@ -1,23 +1,43 @@
 package options
 
 import (
-	"encoding/json"
 	"golang-standards-project-example/internal/pkg/options"
 	"golang-standards-project-example/pkg/app"
 )
 
 type Options struct {
-	HttpServingOptions *options.HttpServingOptions `json:"http"   mapstructure:"http"`
+	ServerRunOptions     *options.ServerRunOptions     `json:"server"   mapstructure:"server"`
+	HttpServingOptions   *options.HttpServingOptions   `json:"http"     mapstructure:"http"`
+	SecureServingOptions *options.SecureServingOptions `json:"secure"   mapstructure:"secure"`
+	ShutdownOptions      *options.ShutdownOptions      `json:"shutdown" mapstructure:"shutdown"`
+	LogOptions           *options.LogOptions           `json:"log"      mapstructure:"log"`
+	TraceOptions         *options.TraceOptions         `json:"trace"    mapstructure:"trace"`
+	IdempotencyOptions   *options.IdempotencyOptions   `json:"idempotency" mapstructure:"idempotency"`
+	DeletionOptions      *options.DeletionOptions      `json:"deletion"    mapstructure:"deletion"`
 }
 
 func NewOptions() *Options {
 	return &Options{
-		HttpServingOptions: options.NewHttpServingOptions(),
+		ServerRunOptions:     options.NewServerRunOptions(),
+		HttpServingOptions:   options.NewHttpServingOptions(),
+		SecureServingOptions: options.NewSecureServingOptions(),
+		ShutdownOptions:      options.NewShutdownOptions(),
+		LogOptions:           options.NewLogOptions(),
+		TraceOptions:         options.NewTraceOptions(),
+		IdempotencyOptions:   options.NewIdempotencyOptions(),
+		DeletionOptions:      options.NewDeletionOptions(),
 	}
 }
 
 func (o *Options) Flags() (fss app.NamedFlagSets) {
+	o.ServerRunOptions.AddFlags(fss.FlagSet("generic"))
 	o.HttpServingOptions.AddFlags(fss.FlagSet("http"))
+	o.SecureServingOptions.AddFlags(fss.FlagSet("secure"))
+	o.ShutdownOptions.AddFlags(fss.FlagSet("shutdown"))
+	o.LogOptions.AddFlags(fss.FlagSet("log"))
+	o.TraceOptions.AddFlags(fss.FlagSet("trace"))
+	o.IdempotencyOptions.AddFlags(fss.FlagSet("idempotency"))
+	o.DeletionOptions.AddFlags(fss.FlagSet("deletion"))
 	return
 }
 
@@ -25,13 +45,19 @@ func (o *Options) Flags() (fss app.NamedFlagSets) {
 func (o *Options) Validate() []error {
 	var errs []error
 
+	errs = append(errs, o.ServerRunOptions.Validate()...)
 	errs = append(errs, o.HttpServingOptions.Validate()...)
+	errs = append(errs, o.SecureServingOptions.Validate()...)
+	errs = append(errs, o.ShutdownOptions.Validate()...)
+	errs = append(errs, o.LogOptions.Validate()...)
+	errs = append(errs, o.TraceOptions.Validate()...)
+	errs = append(errs, o.IdempotencyOptions.Validate()...)
+	errs = append(errs, o.DeletionOptions.Validate()...)
 
 	return errs
 }
 
+// String returns the options as JSON with sensitive values redacted.
 func (o *Options) String() string {
-	data, _ := json.Marshal(o)
-
-	return string(data)
+	return app.RedactedJSON(o)
 }
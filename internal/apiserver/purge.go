@@ -0,0 +1,116 @@
+package apiserver
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/store"
+	"golang-standards-project-example/internal/pkg/options"
+	"log"
+	"sync"
+	"time"
+)
+
+// purgeJob purges the users deleted longer than the retention ago.
+type purgeJob struct {
+	users store.UserStore
+
+	mu        sync.Mutex
+	retention time.Duration
+	interval  time.Duration
+	started   bool
+	stopped   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func newPurgeJob(users store.UserStore, opts *options.DeletionOptions) *purgeJob {
+	j := &purgeJob{users: users}
+	j.set(opts)
+
+	return j
+}
+
+// set sets the retention and interval, purging is disabled if the retention is zero.
+func (j *purgeJob) set(opts *options.DeletionOptions) {
+	j.retention, j.interval = 0, 0
+	if opts.PurgeEnabled() {
+		j.retention, j.interval = opts.Retention, opts.PurgeInterval
+	}
+}
+
+// Start purges every interval until Stop is called.
+func (j *purgeJob) Start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.started = true
+	j.run()
+}
+
+// Reset changes the retention and interval of the job, it is restarted if
+// it is running.
+func (j *purgeJob) Reset(opts *options.DeletionOptions) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.halt()
+	j.set(opts)
+	if j.started {
+		j.run()
+	}
+}
+
+// Stop stops purging and waits for a running purge to finish.
+func (j *purgeJob) Stop() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stopped = true
+	j.halt()
+
+	return nil
+}
+
+// run starts purging if it is enabled. The caller must hold the lock.
+func (j *purgeJob) run() {
+	if j.retention <= 0 || j.stopped {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel, j.done = cancel, make(chan struct{})
+
+	retention, interval, done := j.retention, j.interval, j.done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.purge(ctx, retention)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// halt stops purging and waits for a running purge to finish. The caller
+// must hold the lock.
+func (j *purgeJob) halt() {
+	if j.cancel != nil {
+		j.cancel()
+		<-j.done
+		j.cancel, j.done = nil, nil
+	}
+}
+
+func (j *purgeJob) purge(ctx context.Context, retention time.Duration) {
+	n, err := j.users.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("Purge deleted users failed: %s\n", err.Error())
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d deleted user(s)\n", n)
+	}
+}
@@ -2,29 +2,141 @@ package apiserver
 
 import (
 	"github.com/gin-gonic/gin"
-	v1_example "golang-standards-project-example/internal/apiserver/controller/v1/user"
-	v2_example "golang-standards-project-example/internal/apiserver/controller/v2/user"
+	v1 "golang-standards-project-example/internal/apiserver/api/v1"
+	v2 "golang-standards-project-example/internal/apiserver/api/v2"
+	"golang-standards-project-example/internal/apiserver/config"
+	"golang-standards-project-example/internal/apiserver/controller/user"
+	"golang-standards-project-example/internal/apiserver/store"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/idempotency"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"golang-standards-project-example/internal/pkg/options"
+	"golang-standards-project-example/internal/pkg/server"
+	"golang-standards-project-example/internal/pkg/stream"
+	"golang-standards-project-example/internal/pkg/versioning"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/openapi"
+	"net/http"
+	"time"
 )
 
-func initRouter(g *gin.Engine) {
-	installMiddleware(g)
-	installController(g)
+// users is the user resource: v1 is deprecated in favour of v2.
+var users = versioning.NewResource("users",
+	versioning.Version{
+		Name:       "v1",
+		Converter:  v1.UserConverter,
+		Deprecated: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:     time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+	},
+	versioning.Version{Name: "v2", Converter: v2.UserConverter},
+)
+
+// importContentTypes returns the stream formats accepted by the user import
+// by its paths, the other routes accept the content types of the server only.
+func importContentTypes() map[string][]string {
+	formats := []string{stream.MIMENDJSON, stream.MIMECSV}
+	accepted := map[string][]string{"/users:import": formats}
+	for _, v := range users.Versions() {
+		accepted["/"+v.Name+"/users:import"] = formats
+	}
+
+	return accepted
 }
 
-func installMiddleware(g *gin.Engine) {
+func initRouter(s *server.GenericHttpServer, cfg *config.Config) {
+	s.Spec().Info.Title = "User API Server"
+	s.Spec().Info.Description = commandDesc
+	installMiddleware(s.Engine, cfg)
+	installController(s)
 }
 
-func installController(g *gin.Engine) *gin.Engine {
-	v1 := g.Group("/v1")
-	{
-		userController := v1_example.NewUserController()
-		v1.GET("/hello", userController.Hello)
+func installMiddleware(g *gin.Engine, cfg *config.Config) {
+	if opts := cfg.IdempotencyOptions; opts.Enabled() {
+		records := record.NewMemoryStore()
+		if opts.Backend == options.IdempotencyBackendStore {
+			records = store.Client().IdempotencyRecords()
+		}
+		g.Use(idempotency.Middleware(records, opts.TTL))
 	}
+}
 
-	v2 := g.Group("/v2")
-	{
-		userController := v2_example.NewUserController()
-		v2.GET("/hello", userController.Hello)
-	}
-	return g
+func installController(s *server.GenericHttpServer) *gin.Engine {
+	router := versioning.NewRouter(&s.RouterGroup, s.Spec())
+	s.AddRewrite(router.Rewrite)
+
+	userController := user.NewUserController(store.Client())
+	router.GET(users, "/hello", openapi.Operation{
+		Summary: "Say hello with a sample user", Tags: []string{"user"}, Response: versioning.DTO,
+	}, userController.Hello)
+	router.POST(users, "/users", openapi.Operation{
+		Summary: "Create a user", Tags: []string{"user"}, Request: versioning.DTO,
+		Status: http.StatusCreated, Response: versioning.DTO,
+		Description: "Responds with the URL of the user in the Location header and its ETag.",
+		Codes:       []int{code.ErrBind, code.ErrValidation, code.ErrUserAlreadyExist},
+	}, userController.Create)
+	router.GET(users, "/users", openapi.Operation{
+		Summary: "List or watch users", Tags: []string{"user"}, Query: metav1.ListOptions{}, Response: versioning.DTOList,
+		Description: "With watch=true streams the ADDED, MODIFIED and DELETED events of the selected users as " +
+			"Server-Sent Events, or as WebSocket messages if the request upgrades, after resourceVersion or " +
+			"Last-Event-ID. Without either it starts with the users as ADDED events. Fails with 410 if the " +
+			"changes since the resource version are no longer kept.",
+		Codes: []int{code.ErrBind, code.ErrValidation, code.ErrResourceExpired},
+	}, userController.List)
+	router.GET(users, "/users/:name", openapi.Operation{
+		Summary: "Get a user", Tags: []string{"user"}, Response: versioning.DTO,
+		Description: "Responds with the ETag of the user, and 304 if it matches If-None-Match.",
+		Codes:       []int{code.ErrUserNotFound},
+	}, userController.Get)
+	router.PUT(users, "/users/:name", openapi.Operation{
+		Summary: "Replace a user", Tags: []string{"user"}, Request: versioning.DTO, Response: versioning.DTO,
+		Description: "Fails with 428 without If-Match or metadata.resourceVersion, and with 412 unless the user " +
+			"matches If-Match or, if absent, metadata.resourceVersion.",
+		Codes: []int{
+			code.ErrBind, code.ErrValidation, code.ErrUserNotFound, code.ErrPreconditionFailed, code.ErrPreconditionRequired,
+		},
+	}, userController.Update)
+	router.PATCH(users, "/users/:name", openapi.Operation{
+		Summary: "Patch a user", Tags: []string{"user"}, Response: versioning.DTO,
+		Description: "Applies a JSON Patch (application/json-patch+json) or JSON Merge Patch " +
+			"(application/merge-patch+json) to the user in the representation of the API version. " +
+			"Fails with 428 without If-Match, and with 412 unless the user matches If-Match and the patch " +
+			"keeps metadata.resourceVersion. With \"If-Match: *\" concurrent updates are retried.",
+		Codes: []int{
+			code.ErrBind, code.ErrValidation, code.ErrUserNotFound, code.ErrUnsupportedMediaType,
+			code.ErrPreconditionFailed, code.ErrPreconditionRequired, code.ErrPatchConflict,
+		},
+	}, userController.Patch)
+	router.DELETE(users, "/users/:name", openapi.Operation{
+		Summary: "Delete a user", Tags: []string{"user"}, Status: http.StatusNoContent,
+		Description: "Hides the user until it is restored, or purged after the retention window. " +
+			"Fails with 428 without If-Match, and with 412 unless the user matches it.",
+		Codes: []int{code.ErrUserNotFound, code.ErrPreconditionFailed, code.ErrPreconditionRequired},
+	}, userController.Delete)
+	router.Custom(users, http.MethodPost, "/users/:name", "restore", openapi.Operation{
+		Summary: "Restore a deleted user", Tags: []string{"user"}, Response: versioning.DTO,
+		Description: "Fails with 428 without If-Match, and with 412 unless the deleted user matches it.",
+		Codes:       []int{code.ErrUserNotFound, code.ErrPreconditionFailed, code.ErrPreconditionRequired},
+	}, userController.Restore)
+	router.GET(users, "/users/:name/history", openapi.Operation{
+		Summary: "List the versions of a user", Tags: []string{"user"}, Response: versioning.DTOList,
+		Description: "Lists the versions of the user, newest first, including deleted ones until the user is " +
+			"purged. metadata.updatedBy is who made the version, metadata.deletedAt is set if it deleted the user.",
+		Codes: []int{code.ErrUserNotFound},
+	}, userController.History)
+	router.Custom(users, http.MethodPost, "/users", "import", openapi.Operation{
+		Summary: "Import users", Tags: []string{"user"}, Response: user.ImportResult{},
+		Description: "Creates the users of an NDJSON (application/x-ndjson) or CSV (text/csv) stream and " +
+			"streams the result of each row as NDJSON. The counts of rows are sent in the trailers " +
+			"X-Import-Rows, X-Import-Created, X-Import-Failed and X-Import-Status. " +
+			"Large imports may need a higher --server.prefix-max-body-bytes.",
+		Codes: []int{code.ErrUnsupportedMediaType, code.ErrRequestEntityTooLarge},
+	}, userController.Import)
+	router.Custom(users, http.MethodGet, "/users", "export", openapi.Operation{
+		Summary: "Export users", Tags: []string{"user"}, Query: metav1.ListOptions{},
+		Description: "Streams the users matching the list options as NDJSON or, if accepted, CSV. " +
+			"The number of rows is sent in the trailers X-Export-Rows and X-Export-Status.",
+		Codes: []int{code.ErrBind, code.ErrValidation},
+	}, userController.Export)
+
+	return s.Engine
 }
@@ -1,16 +1,33 @@
 package apiserver
 
 import (
+	"context"
+	"fmt"
 	"golang-standards-project-example/internal/apiserver/config"
+	"golang-standards-project-example/internal/apiserver/store"
+	"golang-standards-project-example/internal/apiserver/store/memory"
 	"golang-standards-project-example/internal/pkg/server"
 	"golang-standards-project-example/pkg/shutdown"
+	"golang-standards-project-example/pkg/shutdown/adminhttp"
 	"golang-standards-project-example/pkg/shutdown/posixsignal"
+	"golang-standards-project-example/pkg/shutdown/signaldispatch"
+	"golang-standards-project-example/pkg/shutdown/supervisor"
+	"golang-standards-project-example/pkg/shutdown/upgrade"
+	"golang-standards-project-example/pkg/tracing"
+	"golang-standards-project-example/pkg/util/activation"
+	"golang-standards-project-example/pkg/util/logfile"
 	"log"
 )
 
 type apiServer struct {
+	basename          string
+	cfg               *config.Config
 	gs                *shutdown.GracefulShutdown
+	signals           *signaldispatch.Dispatcher
+	logFile           *logfile.File
+	tracer            *tracing.Provider
 	genericHttpServer *server.GenericHttpServer
+	purgeJob          *purgeJob
 	//gRPCAPIServer    *grpcAPIServer
 }
 
@@ -18,9 +35,8 @@ type preparedApiServer struct {
 	*apiServer
 }
 
-func NewApiServer(cfg *config.Config) (*apiServer, error) {
-	gs := shutdown.New()
-	gs.AddShutdownManager(posixsignal.NewPosixSignalManager())
+func NewApiServer(basename string, cfg *config.Config) (*apiServer, error) {
+	gs := buildGracefulShutdown(cfg)
 	serverConfig, err := buildApiServerConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -30,33 +46,149 @@ func NewApiServer(cfg *config.Config) (*apiServer, error) {
 		return nil, err
 	}
 	server := &apiServer{
+		basename:          basename,
+		cfg:               cfg,
 		gs:                gs,
+		signals:           signaldispatch.New(),
 		genericHttpServer: genericHttpServer,
 	}
+	if cfg.LogOptions.OutputPath != "" {
+		if server.logFile, err = logfile.Open(cfg.LogOptions.OutputPath); err != nil {
+			return nil, err
+		}
+		log.SetOutput(server.logFile)
+	}
+	store.SetClient(memory.NewFactory())
+	if server.tracer, err = tracing.NewProvider(context.Background(), cfg.TraceOptions.Config()); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
+func buildGracefulShutdown(cfg *config.Config) *shutdown.GracefulShutdown {
+	opts := cfg.ShutdownOptions
+	gs := shutdown.New()
+	gs.SetTimeout(opts.Timeout)
+	gs.SetErrorHandler(shutdown.ErrorFunc(func(err error) {
+		log.Printf("Shutdown error: %s\n", err.Error())
+	}))
+
+	// let Run return the shutdown result instead of exiting the process
+	signalManager := posixsignal.NewPosixSignalManager()
+	signalManager.SetExit(false)
+	gs.AddShutdownManager(signalManager)
+	if opts.AdminAddress != "" {
+		gs.AddShutdownManager(adminhttp.NewAdminHTTPManager(opts.AdminAddress, opts.AdminToken))
+	}
+	if opts.WatchFile != "" || opts.WatchStdin {
+		gs.AddShutdownManager(supervisor.NewSupervisorManager(opts.WatchFile, opts.WatchStdin))
+	}
+
+	return gs
+}
+
 func buildApiServerConfig(cfg *config.Config) (*server.Config, error) {
 	httpConfig := server.NewConfig()
-	cfg.HttpServingOptions.ApplyTo(httpConfig)
+	if err := cfg.ServerRunOptions.ApplyTo(httpConfig); err != nil {
+		return nil, err
+	}
+	httpConfig.PrefixContentTypes = importContentTypes()
+	if err := cfg.HttpServingOptions.ApplyTo(httpConfig); err != nil {
+		return nil, err
+	}
+	if err := cfg.SecureServingOptions.ApplyTo(httpConfig); err != nil {
+		return nil, err
+	}
+	if err := cfg.ShutdownOptions.ApplyTo(httpConfig); err != nil {
+		return nil, err
+	}
+	if err := inheritSockets(httpConfig, cfg.HttpServingOptions.SocketActivation); err != nil {
+		return nil, err
+	}
 	return httpConfig, nil
 }
 
-func (s *apiServer) PrepareRun() preparedApiServer {
-	initRouter(s.genericHttpServer.Engine)
-	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
-		s.genericHttpServer.Close()
+// inheritSockets takes over the sockets handed over by a previous process
+// during an upgrade, the server adopts them by address. Otherwise, if
+// enabled, the sockets passed by systemd socket activation are served.
+func inheritSockets(c *server.Config, socketActivation bool) error {
+	inherited, err := upgrade.Inherit()
+	if err != nil {
+		return err
+	}
+	if inherited != nil {
+		c.Inherited = inherited
 		return nil
+	}
+	if socketActivation {
+		listeners, err := activation.Listeners()
+		if err != nil {
+			return err
+		}
+		if len(listeners) == 0 {
+			return fmt.Errorf("--http.socket-activation is set but no sockets were passed")
+		}
+		c.HttpServing.Listeners = listeners
+	}
+
+	return nil
+}
+
+func (s *apiServer) PrepareRun() preparedApiServer {
+	initRouter(s.genericHttpServer, s.cfg)
+	s.gs.AddShutdownCallbackWithOptions(shutdown.ShutdownContextFunc(func(ctx context.Context, _ string) error {
+		return s.genericHttpServer.Shutdown(ctx)
+	}), shutdown.WithName("http-server"), shutdown.WithPhase(shutdown.PhaseDrain))
+
+	s.signals.SetErrorHandler(shutdown.ErrorFunc(func(err error) {
+		log.Printf("Signal handler error: %s\n", err.Error())
 	}))
+	installSignalHandlers(s)
+	// tell the previous process, if any, that this one took over its listeners
+	s.genericHttpServer.AddReadyHook(func() {
+		if err := upgrade.NotifyReady(); err != nil {
+			log.Printf("Notify upgrade readiness failed: %s\n", err.Error())
+		}
+	})
+	s.gs.AddShutdownCallbackWithOptions(s.signals.ShutdownCallback(),
+		shutdown.WithName("signal-dispatcher"), shutdown.WithPhase(shutdown.PhaseStopAccepting))
+	// the job is idle unless purging is enabled, which a reload may change
+	s.purgeJob = newPurgeJob(store.Client().Users(), s.cfg.DeletionOptions)
+	s.gs.AddShutdownCallbackWithOptions(shutdown.ShutdownFunc(func(string) error {
+		return s.purgeJob.Stop()
+	}), shutdown.WithName("purge-job"), shutdown.WithPhase(shutdown.PhaseStopAccepting))
+	s.gs.AddShutdownCallbackWithOptions(shutdown.ShutdownFunc(func(string) error {
+		return store.Client().Close()
+	}), shutdown.WithName("store"), shutdown.WithPhase(shutdown.PhaseCloseResources))
+	if s.tracer != nil {
+		// flush the spans of the drained requests
+		s.gs.AddShutdownCallbackWithOptions(shutdown.ShutdownContextFunc(func(ctx context.Context, _ string) error {
+			return s.tracer.Shutdown(ctx)
+		}), shutdown.WithName("tracer-provider"), shutdown.WithPhase(shutdown.PhaseCloseResources))
+	}
 	return preparedApiServer{s}
 }
 
 func (s preparedApiServer) Run() error {
 	//go s.gRPCAPIServer.Run()
+	s.purgeJob.Start()
 	// start shutdown managers
 	if err := s.gs.Start(); err != nil {
-		log.Fatalf("start shutdown manager failed: %s", err.Error())
+		return fmt.Errorf("start shutdown manager failed: %w", err)
+	}
+	if err := s.signals.Start(); err != nil {
+		return err
+	}
+
+	if err := s.genericHttpServer.Run(); err != nil {
+		return err
+	}
+
+	// wait for the shutdown callbacks to finish
+	<-s.gs.Done()
+	if report := s.gs.Report(); report != nil && len(report.Failed()) > 0 {
+		return report
 	}
 
-	return s.genericHttpServer.Run()
+	return nil
 }
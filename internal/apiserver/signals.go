@@ -0,0 +1,127 @@
+//go:build !windows
+
+package apiserver
+
+import (
+	"bytes"
+	"fmt"
+	"golang-standards-project-example/internal/apiserver/config"
+	"golang-standards-project-example/internal/apiserver/options"
+	"golang-standards-project-example/pkg/app"
+	"golang-standards-project-example/pkg/shutdown/signaldispatch"
+	"golang-standards-project-example/pkg/shutdown/upgrade"
+	"golang-standards-project-example/pkg/util/logfile"
+	"io"
+	"log"
+	"os"
+	"runtime/pprof"
+	"syscall"
+)
+
+// installSignalHandlers reloads the options and reopens the log file on
+// SIGHUP, and dumps goroutine stacks and the configuration on SIGUSR1.
+// If enabled, SIGUSR2 upgrades the server to the current executable.
+func installSignalHandlers(s *apiServer) {
+	s.signals.Handle(signaldispatch.SignalFunc(s.reload), syscall.SIGHUP)
+	s.signals.Handle(signaldispatch.SignalFunc(s.dumpDiagnostics), syscall.SIGUSR1)
+
+	if opts := s.cfg.ShutdownOptions; opts.Upgrade {
+		upgradeManager := upgrade.NewUpgradeManager(func() upgrade.Sockets {
+			return upgrade.Sockets{
+				Listeners:   s.genericHttpServer.Listeners(),
+				PacketConns: s.genericHttpServer.PacketConns(),
+			}
+		}, syscall.SIGUSR2)
+		upgradeManager.SetTimeout(opts.UpgradeTimeout)
+		s.gs.AddShutdownManager(upgradeManager)
+	}
+}
+
+// reload reads the options from the command line and the environment again
+// and applies the ones which can change at runtime: the log file, which is
+// reopened as well, e.g. after it was rotated, and the deletion options.
+// The other options take effect after a restart.
+func (s *apiServer) reload(sig os.Signal) error {
+	log.Printf("Received %s, reloading\n", sig)
+	opts := options.NewOptions()
+	if err := app.LoadOptions(s.basename, opts, os.Args[1:]); err != nil {
+		return fmt.Errorf("reload options failed, keeping the running ones: %w", err)
+	}
+	completeOptions(s.basename, opts)
+
+	if err := s.reopenLog(opts.LogOptions.OutputPath); err != nil {
+		return err
+	}
+	s.purgeJob.Reset(opts.DeletionOptions)
+
+	running := *s.cfg.Options
+	running.LogOptions = opts.LogOptions
+	running.DeletionOptions = opts.DeletionOptions
+	s.cfg = &config.Config{Options: &running}
+	if running.String() != opts.String() {
+		log.Printf("Reloaded the log and deletion options, other changed options take effect after a restart\n")
+	} else {
+		log.Printf("Reloaded options: `%s`\n", s.cfg.String())
+	}
+
+	return nil
+}
+
+// reopenLog reopens the log file at path, or switches the log to it if the
+// path changed. An empty path logs to stderr.
+func (s *apiServer) reopenLog(path string) error {
+	if path == s.cfg.LogOptions.OutputPath {
+		if s.logFile == nil {
+			return nil
+		}
+		if err := s.logFile.Reopen(); err != nil {
+			return err
+		}
+		log.Printf("Reopened log file %s\n", path)
+
+		return nil
+	}
+
+	var out io.Writer = os.Stderr
+	var file *logfile.File
+	if path != "" {
+		var err error
+		if file, err = logfile.Open(path); err != nil {
+			return err
+		}
+		out = file
+	}
+	log.Printf("Switching log to %s\n", logName(path))
+	log.SetOutput(out)
+	if s.logFile != nil {
+		if err := s.logFile.Close(); err != nil {
+			log.Printf("Close log file %s failed: %s\n", s.cfg.LogOptions.OutputPath, err.Error())
+		}
+	}
+	s.logFile = file
+	log.Printf("Switched log from %s\n", logName(s.cfg.LogOptions.OutputPath))
+
+	return nil
+}
+
+// logName returns the name of the log at path for messages.
+func logName(path string) string {
+	if path == "" {
+		return "stderr"
+	}
+
+	return path
+}
+
+// dumpDiagnostics logs the stacks of all goroutines and the configuration.
+func (s *apiServer) dumpDiagnostics(sig os.Signal) error {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return err
+	}
+	log.Printf("Received %s, dumping diagnostics\n", sig)
+	log.Printf("Goroutines:\n%s", buf.String())
+	log.Printf("Config: `%s`\n", s.cfg.String())
+
+	return nil
+}
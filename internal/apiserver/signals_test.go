@@ -0,0 +1,149 @@
+//go:build !windows
+
+package apiserver
+
+import (
+	"bytes"
+	"github.com/spf13/viper"
+	"golang-standards-project-example/internal/apiserver/config"
+	"golang-standards-project-example/internal/apiserver/options"
+	"golang-standards-project-example/internal/apiserver/store/memory"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newReloadServer returns a server started with the default options as
+// the application "test" without arguments.
+func newReloadServer(t *testing.T) *apiServer {
+	t.Helper()
+	args := os.Args
+	os.Args = []string{"test"}
+	var stderr bytes.Buffer
+	log.SetOutput(&stderr)
+	t.Cleanup(func() {
+		os.Args = args
+		log.SetOutput(os.Stderr)
+		viper.Reset()
+	})
+
+	opts := options.NewOptions()
+	completeOptions("test", opts)
+	s := &apiServer{basename: "test", cfg: &config.Config{Options: opts}}
+	s.purgeJob = newPurgeJob(memory.NewFactory().Users(), opts.DeletionOptions)
+	t.Cleanup(func() {
+		_ = s.purgeJob.Stop()
+		if s.logFile != nil {
+			s.logFile.Close()
+		}
+	})
+
+	return s
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	s := newReloadServer(t)
+
+	steps := []struct {
+		name          string
+		env           map[string]string
+		config        string
+		wantErr       bool
+		wantLog       string
+		wantRetention time.Duration
+	}{
+		{name: "unchanged", wantRetention: 30 * 24 * time.Hour},
+		{name: "log file from env", env: map[string]string{"TEST_LOG_OUTPUT_PATH": first}, wantLog: first, wantRetention: 30 * 24 * time.Hour},
+		{name: "config file", config: "log:\n  output-path: " + second + "\ndeletion:\n  retention: 2h\n", wantLog: second, wantRetention: 2 * time.Hour},
+		{name: "env wins over config file", env: map[string]string{"TEST_DELETION_RETENTION": "3h"}, config: "deletion:\n  retention: 2h\n", wantRetention: 3 * time.Hour},
+		{name: "purge disabled", config: "deletion:\n  retention: 0s\n"},
+		{name: "invalid keeps running options", config: "deletion:\n  retention: -1h\n", wantErr: true},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			for k, v := range step.env {
+				t.Setenv(k, v)
+			}
+			if step.config != "" {
+				file := filepath.Join(dir, "config.yaml")
+				if err := os.WriteFile(file, []byte(step.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				viper.SetConfigFile(file)
+			}
+			before := s.cfg
+
+			err := s.reload(syscall.SIGHUP)
+			if (err != nil) != step.wantErr {
+				t.Fatalf("reload() error = %v, wantErr %v", err, step.wantErr)
+			}
+			if step.wantErr {
+				if s.cfg != before {
+					t.Error("reload() changed the running options")
+				}
+				return
+			}
+			if got := s.cfg.LogOptions.OutputPath; got != step.wantLog {
+				t.Errorf("log output path = %q, want %q", got, step.wantLog)
+			}
+			if got := s.purgeJob.retention; got != step.wantRetention {
+				t.Errorf("purge retention = %v, want %v", got, step.wantRetention)
+			}
+			if s.cfg.HttpServingOptions != before.HttpServingOptions {
+				t.Error("reload() replaced options which need a restart")
+			}
+		})
+	}
+
+	// the first log was left by the switch to the second, which was
+	// left by the switch back to stderr
+	if content := readFile(t, first); !strings.Contains(content, "Switching log to "+second) {
+		t.Errorf("first log = %q, want the switch to the second", content)
+	}
+	if content := readFile(t, second); !strings.Contains(content, "Switched log from "+first) {
+		t.Errorf("second log = %q, want the switch from the first", content)
+	}
+}
+
+func TestReloadReopensLog(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	t.Setenv("TEST_LOG_OUTPUT_PATH", name)
+	s := newReloadServer(t)
+	if err := s.reload(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate the log
+	rotated := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(name, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.reload(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	log.Printf("after rotation")
+
+	if content := readFile(t, name); !strings.Contains(content, "after rotation") {
+		t.Errorf("log = %q, want the messages after the rotation", content)
+	}
+	if content := readFile(t, rotated); strings.Contains(content, "after rotation") {
+		t.Errorf("rotated log = %q, want no messages after the rotation", content)
+	}
+}
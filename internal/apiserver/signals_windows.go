@@ -0,0 +1,4 @@
+package apiserver
+
+// installSignalHandlers does nothing, SIGHUP and SIGUSR1 do not exist on windows.
+func installSignalHandlers(s *apiServer) {}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"time"
+)
+
+// expireBatch is the number of records Reserve checks for expiry besides
+// the one of its key.
+const expireBatch = 8
+
+// idempotencyRecords keeps the records of idempotency keys in the datastore
+// under its lock, like the resources. Records are copied in and out, as a
+// store keeping them outside the process would.
+type idempotencyRecords struct {
+	ds *datastore
+}
+
+func newIdempotencyRecords(ds *datastore) *idempotencyRecords {
+	return &idempotencyRecords{ds}
+}
+
+// Reserve stores a copy of the record, unless the key has a record which
+// has not expired yet, which it returns a copy of instead.
+func (s *idempotencyRecords) Reserve(ctx context.Context, key string, r *record.Record) (*record.Record, error) {
+	s.ds.mu.Lock()
+	defer s.ds.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	if existing, ok := s.ds.idempotencyRecords[key]; ok && now.Before(existing.ExpiresAt) {
+		return copyRecord(existing), nil
+	}
+	s.ds.idempotencyRecords[key] = copyRecord(r)
+
+	return nil, nil
+}
+
+// expire removes the expired records among up to expireBatch records. The
+// caller must hold the write lock.
+func (s *idempotencyRecords) expire(now time.Time) {
+	checked := 0
+	for k, r := range s.ds.idempotencyRecords {
+		if checked == expireBatch {
+			return
+		}
+		checked++
+		if now.After(r.ExpiresAt) {
+			delete(s.ds.idempotencyRecords, k)
+		}
+	}
+}
+
+// Complete replaces the record of the key with a copy of the completed record.
+func (s *idempotencyRecords) Complete(ctx context.Context, key string, r *record.Record) error {
+	s.ds.mu.Lock()
+	defer s.ds.mu.Unlock()
+
+	s.ds.idempotencyRecords[key] = copyRecord(r)
+
+	return nil
+}
+
+// Release forgets the key.
+func (s *idempotencyRecords) Release(ctx context.Context, key string) error {
+	s.ds.mu.Lock()
+	defer s.ds.mu.Unlock()
+
+	delete(s.ds.idempotencyRecords, key)
+
+	return nil
+}
+
+// copyRecord returns a deep copy of r.
+func copyRecord(r *record.Record) *record.Record {
+	c := *r
+	c.Header = r.Header.Clone()
+	c.Body = bytes.Clone(r.Body)
+
+	return &c
+}
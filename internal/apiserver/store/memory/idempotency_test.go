@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyRecords(t *testing.T) {
+	ctx := context.Background()
+	factory := NewFactory()
+	now := time.Now()
+	reserved := &record.Record{Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}
+	done := &record.Record{
+		Fingerprint: "f", Done: true, Status: http.StatusCreated,
+		Header: http.Header{"Location": {"/users/a"}}, Body: []byte("{}"), ExpiresAt: now.Add(time.Hour),
+	}
+
+	tests := []struct {
+		name string
+		op   func(s record.Store) (*record.Record, error)
+		want *record.Record
+	}{
+		{"reserve a new key", func(s record.Store) (*record.Record, error) { return s.Reserve(ctx, "k", reserved) }, nil},
+		{"reserve a reserved key", func(s record.Store) (*record.Record, error) {
+			return s.Reserve(ctx, "k", &record.Record{})
+		}, reserved},
+		{"complete", func(s record.Store) (*record.Record, error) { return nil, s.Complete(ctx, "k", done) }, nil},
+		{"reserve a completed key", func(s record.Store) (*record.Record, error) {
+			return s.Reserve(ctx, "k", &record.Record{})
+		}, done},
+		{"release", func(s record.Store) (*record.Record, error) { return nil, s.Release(ctx, "k") }, nil},
+		{"reserve a released key", func(s record.Store) (*record.Record, error) {
+			return s.Reserve(ctx, "k", &record.Record{ExpiresAt: now})
+		}, nil},
+		{"reserve an expired key", func(s record.Store) (*record.Record, error) { return s.Reserve(ctx, "k", reserved) }, nil},
+	}
+	for _, tt := range tests {
+		// every call gets a new view of the records kept by the datastore
+		got, err := tt.op(factory.IdempotencyRecords())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+		if got != nil && got == tt.want {
+			t.Errorf("%s: returned the stored record instead of a copy", tt.name)
+		}
+	}
+}
+
+func TestIdempotencyRecordsCopied(t *testing.T) {
+	ctx := context.Background()
+	s := NewFactory().IdempotencyRecords()
+	r := &record.Record{Header: http.Header{"A": {"1"}}, Body: []byte("x"), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.Complete(ctx, "k", r); err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("A", "2")
+	r.Body[0] = 'y'
+
+	got, err := s.Reserve(ctx, "k", &record.Record{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Get("A") != "1" || string(got.Body) != "x" {
+		t.Errorf("stored record changed with the completed one: %+v", got)
+	}
+}
+
+func TestIdempotencyRecordsExpireLazily(t *testing.T) {
+	ctx := context.Background()
+	ds := NewFactory().(*datastore)
+	expired := time.Now().Add(-time.Minute)
+	for i := 0; i < 4*expireBatch; i++ {
+		ds.idempotencyRecords[string(rune('a'+i))] = &record.Record{ExpiresAt: expired}
+	}
+
+	s := ds.IdempotencyRecords()
+	for i := 0; i < 4; i++ {
+		if _, err := s.Reserve(ctx, "new", &record.Record{ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// each reservation removes the expired ones among expireBatch records
+	if n := len(ds.idempotencyRecords); n >= 4*expireBatch || n < 1 {
+		t.Errorf("%d records are left, want fewer than %d", n, 4*expireBatch)
+	}
+}
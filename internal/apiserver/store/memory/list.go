@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"sort"
+)
+
+// timeLayout formats times with a fixed width, so they sort as strings.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// query is a list request translated for the in-memory objects of a kind,
+// whose labels and fields are matched and sorted as strings.
+type query struct {
+	labels metav1.Selector
+	fields metav1.Selector
+	sortBy []metav1.SortField
+	cursor *metav1.Cursor
+	offset int
+	limit  int
+	raw    metav1.ListOptions
+}
+
+// newQuery parses the list options; known are the field and sort keys allowed.
+func newQuery(opts metav1.ListOptions, known map[string]bool) (*query, error) {
+	q := &query{offset: int(opts.Offset), limit: int(opts.PageSize()), raw: opts}
+
+	var err error
+	if q.labels, err = metav1.ParseLabelSelector(opts.LabelSelector); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "labelSelector: %s", err.Error())
+	}
+	if q.fields, err = metav1.ParseFieldSelector(opts.FieldSelector); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "fieldSelector: %s", err.Error())
+	}
+	for _, r := range q.fields {
+		if !known[r.Key] {
+			return nil, errors.WithCode(code.ErrValidation, "fieldSelector: unknown field %q", r.Key)
+		}
+	}
+	if q.sortBy, err = metav1.ParseSort(opts.SortBy); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "sortBy: %s", err.Error())
+	}
+	for _, f := range q.sortBy {
+		if !known[f.Key] {
+			return nil, errors.WithCode(code.ErrValidation, "sortBy: unknown field %q", f.Key)
+		}
+	}
+	if q.cursor, err = metav1.DecodeCursor(opts.Cursor); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, "cursor: %s", err.Error())
+	}
+	if q.cursor != nil && (q.cursor.SortBy != opts.SortBy || len(q.cursor.Values) != len(q.sortBy)+1) {
+		return nil, errors.WithCode(code.ErrValidation, "cursor: does not continue sortBy %q", opts.SortBy)
+	}
+
+	return q, nil
+}
+
+// matches reports whether an object with the labels and fields is selected.
+func (q *query) matches(labels, fields metav1.Set) bool {
+	return q.labels.Matches(labels) && q.fields.Matches(fields)
+}
+
+// keys returns the sort key of an object with the name and fields: the
+// values of the sort fields followed by the name.
+func (q *query) keys(name string, fields metav1.Set) []string {
+	keys := make([]string, 0, len(q.sortBy)+1)
+	for _, f := range q.sortBy {
+		keys = append(keys, fields[f.Key])
+	}
+
+	return append(keys, name)
+}
+
+// compare compares sort keys returned by keys, honouring descending fields.
+func (q *query) compare(a, b []string) int {
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		less := a[i] < b[i]
+		if i < len(q.sortBy) && q.sortBy[i].Desc {
+			less = !less
+		}
+		if less {
+			return -1
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// page sorts the sort keys of the matching objects and returns the range of
+// the page and the list metadata.
+func (q *query) page(keys [][]string, swap func(i, j int)) (start, end int, meta metav1.ListMeta) {
+	sort.Sort(&sorter{q: q, keys: keys, swap: swap})
+
+	start = q.offset
+	if q.cursor != nil {
+		start = sort.Search(len(keys), func(i int) bool {
+			return q.compare(keys[i], q.cursor.Values) > 0
+		})
+	}
+	if start > len(keys) {
+		start = len(keys)
+	}
+	end = start + q.limit
+	if end > len(keys) {
+		end = len(keys)
+	}
+
+	meta.TotalCount = int64(len(keys))
+	if end < len(keys) && end > start {
+		meta.NextCursor = (&metav1.Cursor{SortBy: q.raw.SortBy, Values: keys[end-1]}).Encode()
+	}
+
+	return start, end, meta
+}
+
+// sorter sorts the sort keys and the objects they belong to.
+type sorter struct {
+	q    *query
+	keys [][]string
+	swap func(i, j int)
+}
+
+func (s *sorter) Len() int           { return len(s.keys) }
+func (s *sorter) Less(i, j int) bool { return s.q.compare(s.keys[i], s.keys[j]) < 0 }
+func (s *sorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
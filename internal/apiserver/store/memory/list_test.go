@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"reflect"
+	"testing"
+)
+
+// newTestUsers returns a memory user store with the users created in order.
+func newTestUsers(t *testing.T, created ...*model.User) *users {
+	t.Helper()
+	store := NewFactory().Users().(*users)
+	for _, user := range created {
+		if err := store.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return store
+}
+
+func testUser(name, nickname string, labels map[string]string) *model.User {
+	return &model.User{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Nickname:   nickname,
+		Email:      name + "@email.com",
+	}
+}
+
+func names(list *model.UserList) []string {
+	var names []string
+	for _, user := range list.Items {
+		names = append(names, user.Name)
+	}
+
+	return names
+}
+
+func TestUsersList(t *testing.T) {
+	store := newTestUsers(t,
+		testUser("c", "x", map[string]string{"env": "prod"}),
+		testUser("a", "y", map[string]string{"env": "prod", "canary": ""}),
+		testUser("d", "x", map[string]string{"env": "dev"}),
+		testUser("b", "z", nil),
+	)
+
+	tests := []struct {
+		name  string
+		opts  metav1.ListOptions
+		want  []string
+		total int64
+		code  int
+	}{
+		{name: "all by name", want: []string{"a", "b", "c", "d"}, total: 4},
+		{name: "label equals", opts: metav1.ListOptions{LabelSelector: "env=prod"}, want: []string{"a", "c"}, total: 2},
+		{name: "label not equals", opts: metav1.ListOptions{LabelSelector: "env!=prod"}, want: []string{"b", "d"}, total: 2},
+		{name: "label exists", opts: metav1.ListOptions{LabelSelector: "canary"}, want: []string{"a"}, total: 1},
+		{name: "label does not exist", opts: metav1.ListOptions{LabelSelector: "!env"}, want: []string{"b"}, total: 1},
+		{name: "field", opts: metav1.ListOptions{FieldSelector: "nickname=x"}, want: []string{"c", "d"}, total: 2},
+		{name: "field and label", opts: metav1.ListOptions{FieldSelector: "nickname=x", LabelSelector: "env=dev"},
+			want: []string{"d"}, total: 1},
+		{name: "sort desc, ties by name", opts: metav1.ListOptions{SortBy: "-nickname"},
+			want: []string{"b", "a", "c", "d"}, total: 4},
+		{name: "offset and limit", opts: metav1.ListOptions{Offset: 1, Limit: 2}, want: []string{"b", "c"}, total: 4},
+		{name: "offset past the end", opts: metav1.ListOptions{Offset: 10}, want: nil, total: 4},
+		{name: "unknown field", opts: metav1.ListOptions{FieldSelector: "password=x"}, code: code.ErrValidation},
+		{name: "unknown sort field", opts: metav1.ListOptions{SortBy: "password"}, code: code.ErrValidation},
+		{name: "cursor of another order", opts: metav1.ListOptions{
+			SortBy: "email", Cursor: (&metav1.Cursor{Values: []string{"a"}}).Encode(),
+		}, code: code.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := store.List(context.Background(), tt.opts)
+			if tt.code != 0 {
+				if !errors.IsCode(err, tt.code) {
+					t.Fatalf("List() error = %v, want code %d", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+			if list.TotalCount != tt.total {
+				t.Errorf("TotalCount = %d, want %d", list.TotalCount, tt.total)
+			}
+		})
+	}
+}
+
+func TestUsersListCursor(t *testing.T) {
+	store := newTestUsers(t,
+		testUser("c", "x", nil),
+		testUser("a", "y", nil),
+		testUser("d", "x", nil),
+		testUser("b", "z", nil),
+		testUser("e", "y", nil),
+	)
+
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{"", []string{"a", "b", "c", "d", "e"}},
+		{"nickname", []string{"c", "d", "a", "e", "b"}},
+		{"-nickname", []string{"b", "a", "e", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			var got []string
+			opts := metav1.ListOptions{SortBy: tt.sortBy, Limit: 2}
+			for page := 0; ; page++ {
+				if page > len(tt.want) {
+					t.Fatal("the pages do not end")
+				}
+				list, err := store.List(context.Background(), opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, names(list)...)
+				if list.NextCursor == "" {
+					break
+				}
+				opts.Cursor = list.NextCursor
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
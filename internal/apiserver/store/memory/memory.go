@@ -0,0 +1,72 @@
+// Package memory implements the apiserver store in memory, e.g. for
+// development and tests. The data is lost when the process exits.
+package memory
+
+import (
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/apiserver/store"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"strconv"
+	"sync"
+)
+
+type datastore struct {
+	mu sync.RWMutex
+	// users are the users by name, including the deleted ones until they are purged.
+	users map[string]*model.User
+	// userHistory are the versions of the users by name, oldest first.
+	userHistory map[string][]*model.User
+	// idempotencyRecords are the records of idempotency keys by key, they
+	// expire lazily.
+	idempotencyRecords map[string]*record.Record
+	// lastID is the ID of the last created object.
+	lastID uint64
+	// resourceVersion is the version of the last modification of any object.
+	resourceVersion uint64
+	// feed is the change feed of the users.
+	feed feed
+}
+
+var _ store.Factory = &datastore{}
+
+// NewFactory returns an empty in-memory store.
+func NewFactory() store.Factory {
+	return &datastore{
+		users:              map[string]*model.User{},
+		userHistory:        map[string][]*model.User{},
+		idempotencyRecords: map[string]*record.Record{},
+		feed:               feed{watchers: map[*userWatcher]struct{}{}},
+	}
+}
+
+// nextResourceVersion returns the resource version of a new modification.
+// The caller must hold the write lock.
+func (ds *datastore) nextResourceVersion() string {
+	ds.resourceVersion++
+
+	return strconv.FormatUint(ds.resourceVersion, 10)
+}
+
+// nextID returns the ID of a new object. The caller must hold the write lock.
+func (ds *datastore) nextID() uint64 {
+	ds.lastID++
+
+	return ds.lastID
+}
+
+func (ds *datastore) Users() store.UserStore {
+	return newUsers(ds)
+}
+
+func (ds *datastore) IdempotencyRecords() record.Store {
+	return newIdempotencyRecords(ds)
+}
+
+// Close stops the watchers.
+func (ds *datastore) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.feed.closeAll()
+
+	return nil
+}
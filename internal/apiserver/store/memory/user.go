@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/request"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"sort"
+	"time"
+)
+
+// historyLimit is the number of versions kept of each user.
+const historyLimit = 100
+
+// userFields are the fields users can be selected and sorted by.
+var userFields = map[string]bool{
+	"metadata.name":      true,
+	"metadata.createdAt": true,
+	"metadata.updatedAt": true,
+	"nickname":           true,
+	"email":              true,
+	"phone":              true,
+}
+
+type users struct {
+	ds *datastore
+}
+
+func newUsers(ds *datastore) *users {
+	return &users{ds}
+}
+
+// Create creates a new user.
+func (u *users) Create(ctx context.Context, user *model.User) error {
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	if old, ok := u.ds.users[user.Name]; ok {
+		if old.DeletedAt != nil {
+			return errors.WithCode(code.ErrUserAlreadyExist,
+				"user %q is deleted, restore it or wait until it is purged", user.Name)
+		}
+		return errors.WithCode(code.ErrUserAlreadyExist, "user %q already exists", user.Name)
+	}
+	now := time.Now().UTC()
+	user.ID = u.ds.nextID()
+	user.CreatedAt, user.UpdatedAt = now, now
+	user.DeletedAt = nil
+	u.save(ctx, user, watch.Added, nil)
+
+	return nil
+}
+
+// Update replaces the user with the same name, keeping its ID and creation time.
+func (u *users) Update(ctx context.Context, user *model.User, pre *metav1.Preconditions) error {
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	old, err := u.get(user.Name)
+	if err != nil {
+		return err
+	}
+	if err := pre.Check(old.ResourceVersion); err != nil {
+		return errors.WrapC(err, code.ErrPreconditionFailed, "update user %q", user.Name)
+	}
+	user.ID, user.CreatedAt = old.ID, old.CreatedAt
+	user.UpdatedAt = time.Now().UTC()
+	user.DeletedAt = nil
+	u.save(ctx, user, watch.Modified, old)
+
+	return nil
+}
+
+// Delete marks the user deleted, hiding it until it is restored or purged.
+func (u *users) Delete(ctx context.Context, name string, pre *metav1.Preconditions) error {
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	old, err := u.get(name)
+	if err != nil {
+		return err
+	}
+	if err := pre.Check(old.ResourceVersion); err != nil {
+		return errors.WrapC(err, code.ErrPreconditionFailed, "delete user %q", name)
+	}
+	deleted := cloneUser(old)
+	now := time.Now().UTC()
+	deleted.UpdatedAt, deleted.DeletedAt = now, &now
+	u.save(ctx, deleted, watch.Deleted, old)
+
+	return nil
+}
+
+// Restore restores the deleted user.
+func (u *users) Restore(ctx context.Context, name string, pre *metav1.Preconditions) (*model.User, error) {
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	old, ok := u.ds.users[name]
+	if !ok || old.DeletedAt == nil {
+		return nil, errors.WithCode(code.ErrUserNotFound, "deleted user %q not found", name)
+	}
+	if err := pre.Check(old.ResourceVersion); err != nil {
+		return nil, errors.WrapC(err, code.ErrPreconditionFailed, "restore user %q", name)
+	}
+	restored := cloneUser(old)
+	restored.UpdatedAt, restored.DeletedAt = time.Now().UTC(), nil
+	// watchers do not see deleted users, so it is added for them
+	u.save(ctx, restored, watch.Added, nil)
+
+	return cloneUser(restored), nil
+}
+
+// Purge removes the users deleted before the time, and their history. The
+// watchers are sent a deleted event of each, so caches drop them as well.
+func (u *users) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	var purged []string
+	for name, user := range u.ds.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(deletedBefore) {
+			purged = append(purged, name)
+		}
+	}
+	sort.Strings(purged)
+	for _, name := range purged {
+		old := u.ds.users[name]
+		delete(u.ds.users, name)
+		delete(u.ds.userHistory, name)
+
+		user := cloneUser(old)
+		user.UpdatedBy = request.UserFrom(ctx)
+		user.ResourceVersion = u.ds.nextResourceVersion()
+		u.ds.feed.emit(userEvent{resourceVersion: u.ds.resourceVersion, typ: watch.Deleted, user: user, old: old})
+	}
+
+	return len(purged), nil
+}
+
+// History returns the versions of the user, newest first, including the
+// deleted ones, until it is purged.
+func (u *users) History(ctx context.Context, name string) ([]*model.User, error) {
+	u.ds.mu.RLock()
+	defer u.ds.mu.RUnlock()
+
+	versions := u.ds.userHistory[name]
+	if len(versions) == 0 {
+		return nil, errors.WithCode(code.ErrUserNotFound, "user %q not found", name)
+	}
+	history := make([]*model.User, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		history = append(history, cloneUser(versions[i]))
+	}
+
+	return history, nil
+}
+
+// get returns the user with the name unless it is deleted. The caller must
+// hold the lock.
+func (u *users) get(name string) (*model.User, error) {
+	user, ok := u.ds.users[name]
+	if !ok || user.DeletedAt != nil {
+		return nil, errors.WithCode(code.ErrUserNotFound, "user %q not found", name)
+	}
+
+	return user, nil
+}
+
+// save stores the new version of the user, records it in its history and
+// notifies the watchers. The caller must hold the write lock.
+func (u *users) save(ctx context.Context, user *model.User, typ watch.EventType, old *model.User) {
+	user.UpdatedBy = request.UserFrom(ctx)
+	user.ResourceVersion = u.ds.nextResourceVersion()
+	stored := cloneUser(user)
+	u.ds.users[user.Name] = stored
+
+	versions := append(u.ds.userHistory[user.Name], stored)
+	if len(versions) > historyLimit {
+		versions = versions[len(versions)-historyLimit:]
+	}
+	u.ds.userHistory[user.Name] = versions
+	u.ds.feed.emit(userEvent{resourceVersion: u.ds.resourceVersion, typ: typ, user: stored, old: old})
+}
+
+// Get returns the user with the name.
+func (u *users) Get(ctx context.Context, name string) (*model.User, error) {
+	u.ds.mu.RLock()
+	defer u.ds.mu.RUnlock()
+
+	user, err := u.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneUser(user), nil
+}
+
+// List returns a page of the users matching the options.
+func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*model.UserList, error) {
+	q, err := newQuery(opts, userFields)
+	if err != nil {
+		return nil, err
+	}
+
+	u.ds.mu.RLock()
+	defer u.ds.mu.RUnlock()
+
+	var (
+		matched []*model.User
+		keys    [][]string
+	)
+	for _, user := range u.ds.users {
+		if user.DeletedAt != nil {
+			continue
+		}
+		fields := userFieldSet(user)
+		if q.matches(user.Labels, fields) {
+			matched = append(matched, user)
+			keys = append(keys, q.keys(user.Name, fields))
+		}
+	}
+
+	start, end, meta := q.page(keys, func(i, j int) { matched[i], matched[j] = matched[j], matched[i] })
+	// copy the page only, lists are read page by page
+	items := make([]*model.User, 0, end-start)
+	for _, user := range matched[start:end] {
+		items = append(items, cloneUser(user))
+	}
+
+	return &model.UserList{ListMeta: meta, Items: items}, nil
+}
+
+// userFieldSet returns the fields of the user selectors and sorting use.
+func userFieldSet(user *model.User) metav1.Set {
+	return metav1.Set{
+		"metadata.name":      user.Name,
+		"metadata.createdAt": user.CreatedAt.UTC().Format(timeLayout),
+		"metadata.updatedAt": user.UpdatedAt.UTC().Format(timeLayout),
+		"nickname":           user.Nickname,
+		"email":              user.Email,
+		"phone":              user.Phone,
+	}
+}
+
+// cloneUser returns a copy of the user which shares no memory with it.
+func cloneUser(user *model.User) *model.User {
+	c := *user
+	if user.DeletedAt != nil {
+		deletedAt := *user.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	if user.Labels != nil {
+		c.Labels = make(map[string]string, len(user.Labels))
+		for k, v := range user.Labels {
+			c.Labels[k] = v
+		}
+	}
+
+	return &c
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/request"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsersUpdatedBy(t *testing.T) {
+	store := newTestUsers(t)
+	as := func(user string) context.Context { return request.WithUser(context.Background(), user) }
+
+	if err := store.Create(as("alice"), testUser("a", "x", nil)); err != nil {
+		t.Fatal(err)
+	}
+	user, err := store.Get(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Nickname = "y"
+	if err := store.Update(as("bob"), user, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(context.Background(), "a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Restore(as("carol"), "a", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := store.History(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		version  int
+		nickname string
+		want     string
+	}{
+		{"restored", 0, "y", "carol"},
+		{"deleted anonymously", 1, "y", ""},
+		{"updated", 2, "y", "bob"},
+		{"created", 3, "x", "alice"},
+	}
+	if len(history) != len(tests) {
+		t.Fatalf("got %d versions, want %d", len(history), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := history[tt.version]
+			if v.Nickname != tt.nickname || v.UpdatedBy != tt.want {
+				t.Errorf("version %d = %q by %q, want %q by %q", tt.version, v.Nickname, v.UpdatedBy, tt.nickname, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsersPurge(t *testing.T) {
+	store := newTestUsers(t,
+		testUser("a", "x", nil), // 1
+		testUser("b", "x", nil), // 2
+		testUser("c", "x", nil), // 3
+	)
+	for _, name := range []string{"c", "a"} { // 4, 5
+		if err := store.Delete(context.Background(), name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if got, want := receive(t, w, 1), []event{{watch.Added, "b", "2"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("initial events = %v, want %v", got, want)
+	}
+
+	purged, err := store.Purge(request.WithUser(context.Background(), "janitor"), time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("purged %d users, want 2", purged)
+	}
+	want := []event{{watch.Deleted, "a", "6"}, {watch.Deleted, "c", "7"}}
+	if got := receive(t, w, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("purge events = %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"a", "c"} {
+		if _, err := store.History(context.Background(), name); !errors.IsCode(err, code.ErrUserNotFound) {
+			t.Errorf("History(%q) error = %v, want ErrUserNotFound", name, err)
+		}
+	}
+	if _, err := store.Get(context.Background(), "b"); err != nil {
+		t.Errorf("Get(b) error = %v, want the user kept", err)
+	}
+
+	// purged users are gone for watchers resuming before the purge as well
+	resumed, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, ResourceVersion: "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Stop()
+	if got := receive(t, resumed, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed events = %v, want %v", got, want)
+	}
+}
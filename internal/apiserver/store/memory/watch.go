@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	// eventHistory is the number of the latest user events kept to resume watches.
+	eventHistory = 1000
+	// watchBuffer is the number of events a watcher may fall behind before it is stopped.
+	watchBuffer = 100
+)
+
+// userEvent is a change of a user in the change feed.
+type userEvent struct {
+	resourceVersion uint64
+	typ             watch.EventType
+	// user is the user after the change, its last state if it was deleted.
+	user *model.User
+	// old is the user before the change, nil if it was added.
+	old *model.User
+}
+
+// feed is the change feed of the users. Its methods must be called with the
+// write lock of the datastore held.
+type feed struct {
+	// history are the latest events, oldest first.
+	history []userEvent
+	// compacted is the resource version of the last event dropped from history.
+	compacted uint64
+	watchers  map[*userWatcher]struct{}
+}
+
+// emit records the event and sends it to the watchers. Watchers which
+// cannot keep up are stopped.
+func (f *feed) emit(ev userEvent) {
+	if len(f.history) == eventHistory {
+		f.compacted = f.history[0].resourceVersion
+		f.history = append(f.history[:0], f.history[1:]...)
+	}
+	f.history = append(f.history, ev)
+
+	for w := range f.watchers {
+		e, ok := w.filter(ev)
+		if !ok {
+			continue
+		}
+		select {
+		case w.in <- e:
+		default:
+			f.remove(w)
+		}
+	}
+}
+
+// remove stops sending events to the watcher.
+func (f *feed) remove(w *userWatcher) {
+	if _, ok := f.watchers[w]; ok {
+		delete(f.watchers, w)
+		close(w.in)
+	}
+}
+
+// closeAll stops sending events to all watchers.
+func (f *feed) closeAll() {
+	for w := range f.watchers {
+		f.remove(w)
+	}
+}
+
+// userWatcher streams the user events matching a query.
+type userWatcher struct {
+	ds     *datastore
+	q      *query
+	in     chan watch.Event
+	result chan watch.Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Watch streams the changes of the users matching the selectors of opts
+// after opts.ResourceVersion. It fails with code.ErrResourceExpired if the
+// changes since then are no longer kept.
+func (u *users) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	q, err := newQuery(opts, userFields)
+	if err != nil {
+		return nil, err
+	}
+	w := &userWatcher{
+		ds:     u.ds,
+		q:      q,
+		in:     make(chan watch.Event, watchBuffer),
+		result: make(chan watch.Event),
+		done:   make(chan struct{}),
+	}
+
+	u.ds.mu.Lock()
+	defer u.ds.mu.Unlock()
+
+	var initial []watch.Event
+	if opts.ResourceVersion == "" {
+		for _, user := range u.ds.users {
+			if user.DeletedAt == nil && w.matches(user) {
+				initial = append(initial, watch.Event{Type: watch.Added, Object: cloneUser(user)})
+			}
+		}
+		// oldest change first, so a watch resumed after any of the events,
+		// whose ids are resource versions, receives the users after it
+		sort.Slice(initial, func(i, j int) bool {
+			return resourceVersion(initial[i]) < resourceVersion(initial[j])
+		})
+	} else {
+		rv, _ := strconv.ParseUint(opts.ResourceVersion, 10, 64)
+		if rv < u.ds.feed.compacted {
+			return nil, errors.WithCode(code.ErrResourceExpired,
+				"resource version %d is older than the oldest kept change %d", rv, u.ds.feed.compacted+1)
+		}
+		if rv > u.ds.resourceVersion {
+			return nil, errors.WithCode(code.ErrValidation,
+				"resource version %d is newer than the store %d", rv, u.ds.resourceVersion)
+		}
+		for _, ev := range u.ds.feed.history {
+			if ev.resourceVersion <= rv {
+				continue
+			}
+			if e, ok := w.filter(ev); ok {
+				initial = append(initial, e)
+			}
+		}
+	}
+	u.ds.feed.watchers[w] = struct{}{}
+	go w.run(ctx, initial)
+
+	return w, nil
+}
+
+// resourceVersion returns the resource version of the user of the event.
+func resourceVersion(e watch.Event) uint64 {
+	rv, _ := strconv.ParseUint(e.Object.(*model.User).ResourceVersion, 10, 64)
+
+	return rv
+}
+
+// matches reports whether the watcher selects the user.
+func (w *userWatcher) matches(user *model.User) bool {
+	return w.q.matches(user.Labels, userFieldSet(user))
+}
+
+// filter returns the event the watcher sees of the change: users which
+// start or stop to match are added or deleted.
+func (w *userWatcher) filter(ev userEvent) (watch.Event, bool) {
+	now := w.matches(ev.user)
+	before := ev.old != nil && w.matches(ev.old)
+	switch {
+	case ev.typ == watch.Modified && now && !before:
+		return watch.Event{Type: watch.Added, Object: cloneUser(ev.user)}, true
+	case ev.typ == watch.Modified && !now && before:
+		return watch.Event{Type: watch.Deleted, Object: cloneUser(ev.user)}, true
+	case now:
+		return watch.Event{Type: ev.typ, Object: cloneUser(ev.user)}, true
+	}
+
+	return watch.Event{}, false
+}
+
+// run sends the initial events, then the events of the feed, until the
+// watcher is stopped or ctx is done.
+func (w *userWatcher) run(ctx context.Context, initial []watch.Event) {
+	defer close(w.result)
+	defer w.Stop()
+
+	for _, e := range initial {
+		select {
+		case w.result <- e:
+		case <-w.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+	for {
+		select {
+		case e, ok := <-w.in:
+			if !ok {
+				return
+			}
+			select {
+			case w.result <- e:
+			case <-w.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		case <-w.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *userWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *userWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.done)
+		w.ds.mu.Lock()
+		w.ds.feed.remove(w)
+		w.ds.mu.Unlock()
+	})
+}
@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/model"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// event is an event of a user by name and resource version.
+type event struct {
+	typ  watch.EventType
+	name string
+	rv   string
+}
+
+// receive returns the next n events of w.
+func receive(t *testing.T, w watch.Interface, n int) []event {
+	t.Helper()
+	var events []event
+	for len(events) < n {
+		select {
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				t.Fatalf("watch ended after %v", events)
+			}
+			user := e.Object.(*model.User)
+			events = append(events, event{e.Type, user.Name, user.ResourceVersion})
+		case <-time.After(time.Second):
+			t.Fatalf("got %v, want %d events", events, n)
+		}
+	}
+
+	return events
+}
+
+// update changes the user with the name.
+func update(t *testing.T, store *users, name string, change func(*model.User)) {
+	t.Helper()
+	user, err := store.Get(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(user)
+	if err := store.Update(context.Background(), user, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchInitialOrder(t *testing.T) {
+	// a is created first but modified last
+	store := newTestUsers(t, testUser("a", "x", nil), testUser("b", "x", nil))
+	update(t, store, "a", func(u *model.User) { u.Nickname = "y" })
+
+	w, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	want := []event{{watch.Added, "b", "2"}, {watch.Added, "a", "3"}}
+	if got := receive(t, w, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("initial events = %v, want %v", got, want)
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	store := newTestUsers(t,
+		testUser("a", "x", map[string]string{"env": "prod"}), // 1
+		testUser("b", "x", map[string]string{"env": "dev"}),  // 2
+	)
+	update(t, store, "a", func(u *model.User) { u.Nickname = "y" })         // 3
+	update(t, store, "b", func(u *model.User) { u.Labels["env"] = "prod" }) // 4
+	update(t, store, "a", func(u *model.User) { u.Labels["env"] = "dev" })  // 5
+	if err := store.Delete(context.Background(), "b", nil); err != nil {    // 6
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts metav1.ListOptions
+		want []event
+	}{
+		{name: "from the start", opts: metav1.ListOptions{ResourceVersion: "0"}, want: []event{
+			{watch.Added, "a", "1"}, {watch.Added, "b", "2"}, {watch.Modified, "a", "3"},
+			{watch.Modified, "b", "4"}, {watch.Modified, "a", "5"}, {watch.Deleted, "b", "6"},
+		}},
+		{name: "after a change", opts: metav1.ListOptions{ResourceVersion: "4"}, want: []event{
+			{watch.Modified, "a", "5"}, {watch.Deleted, "b", "6"},
+		}},
+		{name: "selected", opts: metav1.ListOptions{ResourceVersion: "2", LabelSelector: "env=prod"}, want: []event{
+			{watch.Modified, "a", "3"}, {watch.Added, "b", "4"}, {watch.Deleted, "a", "5"}, {watch.Deleted, "b", "6"},
+		}},
+		{name: "up to date", opts: metav1.ListOptions{ResourceVersion: "6"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Watch = true
+			w, err := store.Watch(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			got := receive(t, w, len(tt.want))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// live events follow the replayed ones
+	w, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, ResourceVersion: "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := store.Create(context.Background(), testUser("c", "x", nil)); err != nil {
+		t.Fatal(err)
+	}
+	want := []event{{watch.Deleted, "b", "6"}, {watch.Added, "c", "7"}}
+	if got := receive(t, w, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestWatchResourceVersion(t *testing.T) {
+	store := newTestUsers(t, testUser("a", "x", nil))
+	for i := 0; i < eventHistory; i++ {
+		update(t, store, "a", func(u *model.User) { u.Phone = "1" })
+	}
+
+	tests := []struct {
+		rv   string
+		code int
+	}{
+		{"0", code.ErrResourceExpired},
+		{"1", 0},
+		{"1001", 0},
+		{"1002", code.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rv, func(t *testing.T) {
+			w, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, ResourceVersion: tt.rv})
+			if tt.code == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Stop()
+				return
+			}
+			if !errors.IsCode(err, tt.code) {
+				t.Errorf("Watch() error = %v, want code %d", err, tt.code)
+			}
+		})
+	}
+}
+
+// closed reports whether the watch ends after delivering at most max events.
+func closed(t *testing.T, w watch.Interface, max int) bool {
+	t.Helper()
+	for n := 0; ; n++ {
+		select {
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				return true
+			}
+			if n == max {
+				return false
+			}
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+}
+
+func TestWatchFanOut(t *testing.T) {
+	store := newTestUsers(t)
+	newWatch := func(selector string) watch.Interface {
+		w, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, LabelSelector: selector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(w.Stop)
+		return w
+	}
+	all, another, prod := newWatch(""), newWatch(""), newWatch("env=prod")
+
+	if err := store.Create(context.Background(), testUser("a", "x", map[string]string{"env": "prod"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(context.Background(), testUser("b", "x", map[string]string{"env": "dev"})); err != nil {
+		t.Fatal(err)
+	}
+	update(t, store, "a", func(u *model.User) { u.Nickname = "y" })
+
+	everything := []event{{watch.Added, "a", "1"}, {watch.Added, "b", "2"}, {watch.Modified, "a", "3"}}
+	tests := []struct {
+		name string
+		w    watch.Interface
+		want []event
+	}{
+		{"all", all, everything},
+		{"another", another, everything},
+		{"selected", prod, []event{{watch.Added, "a", "1"}, {watch.Modified, "a", "3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := receive(t, tt.w, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchSlowWatcher(t *testing.T) {
+	store := newTestUsers(t, testUser("a", "x", nil))
+	slow, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, ResourceVersion: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Stop()
+	fast, err := store.Watch(context.Background(), metav1.ListOptions{Watch: true, ResourceVersion: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Stop()
+
+	// the fast watcher keeps up with every batch, the slow one reads nothing
+	batch := watchBuffer / 2
+	for sent := 0; sent <= watchBuffer+1; sent += batch {
+		for i := 0; i < batch; i++ {
+			update(t, store, "a", func(u *model.User) { u.Phone = "1" })
+		}
+		receive(t, fast, batch)
+	}
+
+	// the slow watcher gets the events it buffered, then its watch ends
+	if !closed(t, slow, watchBuffer+1) {
+		t.Error("slow watcher was not stopped")
+	}
+	update(t, store, "a", func(u *model.User) { u.Phone = "2" })
+	if got := receive(t, fast, 1); got[0].typ != watch.Modified {
+		t.Errorf("fast watcher got %v after the slow one was stopped", got)
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		name string
+		stop func(ds *datastore, w watch.Interface)
+	}{
+		{"store closed", func(ds *datastore, w watch.Interface) { _ = ds.Close() }},
+		{"stopped", func(ds *datastore, w watch.Interface) { w.Stop() }},
+		{"stopped twice", func(ds *datastore, w watch.Interface) { w.Stop(); w.Stop() }},
+		{"context done", func(ds *datastore, w watch.Interface) { cancel() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := NewFactory().(*datastore)
+			store := newUsers(ds)
+			if err := store.Create(context.Background(), testUser("a", "x", nil)); err != nil {
+				t.Fatal(err)
+			}
+			w, err := store.Watch(ctx, metav1.ListOptions{Watch: true, ResourceVersion: "1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			tt.stop(ds, w)
+			if !closed(t, w, 0) {
+				t.Fatal("watch did not end")
+			}
+			ds.mu.RLock()
+			defer ds.mu.RUnlock()
+			if len(ds.feed.watchers) != 0 {
+				t.Errorf("%d watchers are left in the feed", len(ds.feed.watchers))
+			}
+		})
+	}
+}
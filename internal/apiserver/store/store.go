@@ -0,0 +1,24 @@
+package store
+
+import "golang-standards-project-example/internal/pkg/idempotency/record"
+
+var client Factory
+
+// Factory defines the apiserver storage interface.
+type Factory interface {
+	Users() UserStore
+	// IdempotencyRecords stores the records of idempotency keys next to the
+	// resources, so all instances sharing the store share them.
+	IdempotencyRecords() record.Store
+	Close() error
+}
+
+// Client return the store client instance.
+func Client() Factory {
+	return client
+}
+
+// SetClient set the apiserver store client.
+func SetClient(factory Factory) {
+	client = factory
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"golang-standards-project-example/internal/apiserver/model"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"golang-standards-project-example/pkg/watch"
+	"time"
+)
+
+// UserStore defines the user storage interface.
+type UserStore interface {
+	Create(ctx context.Context, user *model.User) error
+	// Update replaces the user with the same name, if it fulfills the preconditions.
+	Update(ctx context.Context, user *model.User, pre *metav1.Preconditions) error
+	// Delete marks the user deleted, if it fulfills the preconditions. Deleted
+	// users are hidden from the other methods but History until they are
+	// restored, and removed when they are purged.
+	Delete(ctx context.Context, name string, pre *metav1.Preconditions) error
+	// Restore restores the deleted user, if it fulfills the preconditions.
+	Restore(ctx context.Context, name string, pre *metav1.Preconditions) (*model.User, error)
+	// Purge removes the users deleted before the time and returns their number.
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	// History returns the versions of the user, newest first, including the
+	// version deleting it. Each records who made it, if known.
+	History(ctx context.Context, name string) ([]*model.User, error)
+	Get(ctx context.Context, name string) (*model.User, error)
+	// List returns a page of the users matching the selectors of opts, which
+	// the caller validated. Field selectors and sorting accept the fields
+	// metadata.name, metadata.createdAt, metadata.updatedAt, nickname, email
+	// and phone.
+	List(ctx context.Context, opts metav1.ListOptions) (*model.UserList, error)
+	// Watch streams the changes of the users matching the selectors of opts
+	// after opts.ResourceVersion, starting with the matching users as added
+	// events if it is empty. It fails with code.ErrResourceExpired if the
+	// changes since the resource version are no longer kept. Events are
+	// *model.User.
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
@@ -0,0 +1,18 @@
+package code
+
+import "net/http"
+
+// apiserver: user errors.
+// Code must start with 11xxxx.
+const (
+	// ErrUserNotFound - 404: User not found.
+	ErrUserNotFound int = iota + 110001
+
+	// ErrUserAlreadyExist - 409: User already exist.
+	ErrUserAlreadyExist
+)
+
+func init() {
+	register(ErrUserNotFound, http.StatusNotFound, "User not found")
+	register(ErrUserAlreadyExist, http.StatusConflict, "User already exist")
+}
@@ -0,0 +1,70 @@
+package code
+
+import "net/http"
+
+// Common: basic errors.
+// Code must start with 1xxxxx.
+const (
+	// ErrSuccess - 200: OK.
+	ErrSuccess int = iota + 100001
+
+	// ErrUnknown - 500: Internal server error.
+	ErrUnknown
+
+	// ErrBind - 400: Error occurred while binding the request body to the struct.
+	ErrBind
+
+	// ErrValidation - 422: Validation failed.
+	ErrValidation
+
+	// ErrPageNotFound - 404: Page not found.
+	ErrPageNotFound
+
+	// ErrUnsupportedVersion - 406: The requested API version is not supported.
+	ErrUnsupportedVersion
+
+	// ErrRequestEntityTooLarge - 413: The request body is too large.
+	ErrRequestEntityTooLarge
+
+	// ErrUnsupportedMediaType - 415: The content type of the request body is not supported.
+	ErrUnsupportedMediaType
+
+	// ErrPreconditionFailed - 412: The resource was modified, the precondition of the request failed.
+	ErrPreconditionFailed
+
+	// ErrPatchConflict - 409: The patch cannot be applied to the resource.
+	ErrPatchConflict
+
+	// ErrIdempotencyKeyReused - 409: The idempotency key was used with a different request.
+	ErrIdempotencyKeyReused
+
+	// ErrIdempotencyKeyInProgress - 409: A request with the idempotency key is still in progress.
+	ErrIdempotencyKeyInProgress
+
+	// ErrResourceExpired - 410: The resource version is too old to watch from.
+	ErrResourceExpired
+
+	// ErrClientCertRequired - 401: A verified client certificate is required.
+	ErrClientCertRequired
+
+	// ErrPreconditionRequired - 428: The request must be conditional, If-Match is required.
+	ErrPreconditionRequired
+)
+
+func init() {
+	register(ErrSuccess, http.StatusOK, "OK")
+	register(ErrUnknown, http.StatusInternalServerError, "Internal server error")
+	register(ErrBind, http.StatusBadRequest, "Error occurred while binding the request body to the struct")
+	register(ErrValidation, http.StatusUnprocessableEntity, "Validation failed")
+	register(ErrPageNotFound, http.StatusNotFound, "Page not found")
+	register(ErrUnsupportedVersion, http.StatusNotAcceptable, "The requested API version is not supported")
+	register(ErrRequestEntityTooLarge, http.StatusRequestEntityTooLarge, "The request body is too large")
+	register(ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "The content type of the request body is not supported")
+	register(ErrPreconditionFailed, http.StatusPreconditionFailed, "The resource was modified, the precondition of the request failed")
+	register(ErrPatchConflict, http.StatusConflict, "The patch cannot be applied to the resource")
+	register(ErrIdempotencyKeyReused, http.StatusConflict, "The idempotency key was used with a different request")
+	register(ErrIdempotencyKeyInProgress, http.StatusConflict, "A request with the idempotency key is still in progress")
+	register(ErrResourceExpired, http.StatusGone, "The resource version is too old to watch from")
+	register(ErrClientCertRequired, http.StatusUnauthorized, "A verified client certificate is required")
+	register(ErrPreconditionRequired, http.StatusPreconditionRequired, "The request must be conditional, If-Match is required")
+}
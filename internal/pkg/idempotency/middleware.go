@@ -0,0 +1,165 @@
+// Package idempotency replays the responses of retried requests which carry
+// the same Idempotency-Key, so retries do not repeat their side effects.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"golang-standards-project-example/internal/pkg/stream"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// HeaderKey is the request header carrying the idempotency key.
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on replayed responses.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// maxKeyLength is the maximal length of an idempotency key.
+	maxKeyLength = 255
+)
+
+// Middleware makes POST requests with an Idempotency-Key header idempotent:
+// the response is stored for the ttl under the key, the user, the method and
+// the path, and replayed to retries. Reusing a key with a different body, or
+// while the first request is in flight, is rejected with 409. Server errors
+// are not stored, so the request can be retried. The request and response
+// bodies are held in memory, so streamed requests, e.g. NDJSON or CSV
+// imports, are passed through.
+func Middleware(store record.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(HeaderKey)
+		if c.Request.Method != http.MethodPost || idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if _, streamed := stream.FormatOf(c.GetHeader("Content-Type")); streamed {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > maxKeyLength {
+			abort(c, errors.WithCode(code.ErrValidation, "%s exceeds %d characters", HeaderKey, maxKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			if limit, ok := core.IsBodyTooLarge(err); ok {
+				err = errors.WrapC(err, code.ErrRequestEntityTooLarge, "request body exceeds the limit of %d bytes", limit)
+			} else {
+				err = errors.WrapC(err, code.ErrBind, "%s", err.Error())
+			}
+			abort(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		key := storeKey(c.GetString(middleware.UsernameKey), c.Request.Method, c.Request.URL.Path, idempotencyKey)
+		fingerprint := sha256.Sum256(body)
+		rec := &record.Record{
+			Fingerprint: hex.EncodeToString(fingerprint[:]),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		existing, err := store.Reserve(ctx, key, rec)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		switch {
+		case existing == nil:
+		case existing.Fingerprint != rec.Fingerprint:
+			abort(c, errors.WithCode(code.ErrIdempotencyKeyReused, "%s %q was used with a different body", HeaderKey, idempotencyKey))
+			return
+		case !existing.Done:
+			abort(c, errors.WithCode(code.ErrIdempotencyKeyInProgress, "a request with %s %q is in progress", HeaderKey, idempotencyKey))
+			return
+		default:
+			replay(c, existing)
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			// release the key if the handler panicked
+			if !completed {
+				if err := store.Release(ctx, key); err != nil {
+					log.Printf("release idempotency key: %v\n", err)
+				}
+			}
+		}()
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		rec.Done = true
+		rec.Status = w.Status()
+		rec.Header = w.Header().Clone()
+		rec.Header.Del(middleware.XRequestIDKey)
+		rec.Body = w.body.Bytes()
+		if err := store.Complete(ctx, key, rec); err != nil {
+			log.Printf("complete idempotency key: %v\n", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// storeKey returns the key the record of an idempotency key is stored under.
+func storeKey(username, method, path, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(username + "\n" + method + " " + path + "\n" + idempotencyKey))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// replay writes the stored response.
+func replay(c *gin.Context, rec *record.Record) {
+	for k, v := range rec.Header {
+		c.Writer.Header()[k] = v
+	}
+	c.Header(HeaderReplayed, "true")
+	c.Data(rec.Status, rec.Header.Get("Content-Type"), rec.Body)
+	c.Abort()
+}
+
+// abort writes the error and stops the handler chain.
+func abort(c *gin.Context, err error) {
+	core.WriteResponse(c, err, nil)
+	c.Abort()
+}
+
+// recorder records the response body.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the recorded writer, e.g. for http.ResponseController.
+func (w *recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
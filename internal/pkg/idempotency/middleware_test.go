@@ -0,0 +1,201 @@
+package idempotency
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/idempotency/record"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testHandler counts the requests it serves and answers with the count, or
+// with status if the request body is "status".
+type testHandler struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (h *testHandler) serve(c *gin.Context) {
+	n := h.calls.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	body, _ := io.ReadAll(c.Request.Body)
+	if status, err := strconv.Atoi(string(body)); err == nil {
+		c.String(status, "failed")
+		return
+	}
+	c.Header("Location", c.Request.URL.Path+"/"+strconv.Itoa(int(n)))
+	c.String(http.StatusCreated, "created %d", n)
+}
+
+// newTestEngine serves the handler on POST /users/*path behind the
+// middleware; the X-User header names the user of the request.
+func newTestEngine(store record.Store, ttl time.Duration, h *testHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+	}, Middleware(store, ttl))
+	engine.POST("/users/*path", h.serve)
+	engine.GET("/users/*path", h.serve)
+
+	return engine
+}
+
+type testRequest struct {
+	method      string
+	path        string
+	key         string
+	user        string
+	body        string
+	contentType string
+}
+
+func (r testRequest) do(engine *gin.Engine) *httptest.ResponseRecorder {
+	method := r.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req := httptest.NewRequest(method, r.path, strings.NewReader(r.body))
+	if r.key != "" {
+		req.Header.Set(HeaderKey, r.key)
+	}
+	req.Header.Set("X-User", r.user)
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	first := testRequest{path: "/users/a", key: "k1", user: "alice", body: "{}"}
+	tests := []struct {
+		name     string
+		retry    testRequest
+		status   int
+		replayed bool
+		calls    int32
+	}{
+		{name: "replay", retry: first, status: http.StatusCreated, replayed: true, calls: 1},
+		{name: "other body", retry: testRequest{path: "/users/a", key: "k1", user: "alice", body: `{"a":1}`},
+			status: http.StatusConflict, calls: 1},
+		{name: "other key", retry: testRequest{path: "/users/a", key: "k2", user: "alice", body: "{}"},
+			status: http.StatusCreated, calls: 2},
+		{name: "other path", retry: testRequest{path: "/users/b", key: "k1", user: "alice", body: "{}"},
+			status: http.StatusCreated, calls: 2},
+		{name: "other user", retry: testRequest{path: "/users/a", key: "k1", user: "bob", body: "{}"},
+			status: http.StatusCreated, calls: 2},
+		{name: "no key", retry: testRequest{path: "/users/a", user: "alice", body: "{}"},
+			status: http.StatusCreated, calls: 2},
+		{name: "not a post", retry: testRequest{method: http.MethodGet, path: "/users/a", key: "k1", user: "alice"},
+			status: http.StatusCreated, calls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &testHandler{}
+			engine := newTestEngine(record.NewMemoryStore(), time.Hour, h)
+			original := first.do(engine)
+			if original.Code != http.StatusCreated {
+				t.Fatalf("first request: status = %d", original.Code)
+			}
+
+			w := tt.retry.do(engine)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got := w.Header().Get(HeaderReplayed) == "true"; got != tt.replayed {
+				t.Errorf("replayed = %v, want %v", got, tt.replayed)
+			}
+			if tt.replayed {
+				if w.Body.String() != original.Body.String() || w.Header().Get("Location") != original.Header().Get("Location") {
+					t.Errorf("replayed %q %v, want %q %v", w.Body, w.Header(), original.Body, original.Header())
+				}
+			}
+			if got := h.calls.Load(); got != tt.calls {
+				t.Errorf("handler calls = %d, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestMiddlewareNotStored(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   testRequest
+		calls int32
+	}{
+		{name: "server error", req: testRequest{path: "/users/a", key: "k1", body: "500"}, calls: 2},
+		{name: "client error", req: testRequest{path: "/users/a", key: "k1", body: "400"}, calls: 1},
+		{name: "streamed", req: testRequest{path: "/users/a", key: "k1", body: "{}\n", contentType: "application/x-ndjson"}, calls: 2},
+		{name: "csv", req: testRequest{path: "/users/a", key: "k1", body: "a\n", contentType: "text/csv; charset=utf-8"}, calls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &testHandler{}
+			engine := newTestEngine(record.NewMemoryStore(), time.Hour, h)
+			tt.req.do(engine)
+			tt.req.do(engine)
+			if got := h.calls.Load(); got != tt.calls {
+				t.Errorf("handler calls = %d, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	h := &testHandler{release: make(chan struct{})}
+	engine := newTestEngine(record.NewMemoryStore(), time.Hour, h)
+	req := testRequest{path: "/users/a", key: "k1", body: "{}"}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- req.do(engine) }()
+	for h.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	w := req.do(engine)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), strconv.Itoa(code.ErrIdempotencyKeyInProgress)) {
+		t.Errorf("concurrent retry: %d %s, want 409 in progress", w.Code, w.Body)
+	}
+	close(h.release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("first request: status = %d", w.Code)
+	}
+	if w := req.do(engine); w.Header().Get(HeaderReplayed) != "true" {
+		t.Error("the retry after the first request completed is not replayed")
+	}
+}
+
+func TestMiddlewareExpired(t *testing.T) {
+	h := &testHandler{}
+	engine := newTestEngine(record.NewMemoryStore(), time.Nanosecond, h)
+	req := testRequest{path: "/users/a", key: "k1", body: "{}"}
+	req.do(engine)
+	time.Sleep(time.Millisecond)
+	if w := req.do(engine); w.Header().Get(HeaderReplayed) != "" {
+		t.Error("an expired key is replayed")
+	}
+	if got := h.calls.Load(); got != 2 {
+		t.Errorf("handler calls = %d, want 2", got)
+	}
+}
+
+func TestMiddlewareKeyTooLong(t *testing.T) {
+	h := &testHandler{}
+	engine := newTestEngine(record.NewMemoryStore(), time.Hour, h)
+	w := testRequest{path: "/users/a", key: strings.Repeat("k", maxKeyLength+1), body: "{}"}.do(engine)
+	if w.Code != http.StatusUnprocessableEntity || h.calls.Load() != 0 {
+		t.Errorf("status = %d, calls = %d, want 422 and no call", w.Code, h.calls.Load())
+	}
+}
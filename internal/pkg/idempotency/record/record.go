@@ -0,0 +1,98 @@
+// Package record defines the records of idempotency keys and the stores
+// keeping them, independent of how the requests are served.
+package record
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the outcome of the first request with a key.
+type Record struct {
+	// Fingerprint identifies the request body the key was first used with.
+	Fingerprint string
+	// Done is false while the first request is in flight.
+	Done bool
+	// Status, Header and Body are the response of the first request.
+	Status int
+	Header http.Header
+	Body   []byte
+	// ExpiresAt is the time the record is forgotten.
+	ExpiresAt time.Time
+}
+
+// Store stores the records of idempotency keys.
+type Store interface {
+	// Reserve stores the record of a new request under the key, unless the
+	// key has a record which has not expired yet, which it returns instead.
+	Reserve(ctx context.Context, key string, record *Record) (*Record, error)
+	// Complete replaces the record of the key with the completed record.
+	Complete(ctx context.Context, key string, record *Record) error
+	// Release forgets the key, so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// expireBatch is the number of records Reserve checks for expiry besides
+// the one of its key, so expired records are removed a few at a time
+// instead of sweeping all of them.
+const expireBatch = 8
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore returns a Store keeping the records in memory, so they are
+// neither shared between processes nor kept across restarts.
+func NewMemoryStore() Store {
+	return &memoryStore{records: map[string]*Record{}}
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, key string, record *Record) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	if existing, ok := s.records[key]; ok && now.Before(existing.ExpiresAt) {
+		return existing, nil
+	}
+	s.records[key] = record
+
+	return nil, nil
+}
+
+// expire removes the expired records among up to expireBatch records, map
+// iteration starts at a random one. The caller must hold the lock.
+func (s *memoryStore) expire(now time.Time) {
+	checked := 0
+	for k, r := range s.records {
+		if checked == expireBatch {
+			return
+		}
+		checked++
+		if now.After(r.ExpiresAt) {
+			delete(s.records, k)
+		}
+	}
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package record
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	now := time.Now()
+	record := &Record{Fingerprint: "f", ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name string
+		op   func() (*Record, error)
+		want *Record
+	}{
+		{"reserve a new key", func() (*Record, error) { return s.Reserve(ctx, "k", record) }, nil},
+		{"reserve a reserved key", func() (*Record, error) { return s.Reserve(ctx, "k", &Record{}) }, record},
+		{"release", func() (*Record, error) { return nil, s.Release(ctx, "k") }, nil},
+		{"reserve a released key", func() (*Record, error) { return s.Reserve(ctx, "k", record) }, nil},
+		{"complete", func() (*Record, error) { return nil, s.Complete(ctx, "k", &Record{Done: true, ExpiresAt: now}) }, nil},
+		{"reserve an expired key", func() (*Record, error) { return s.Reserve(ctx, "k", record) }, nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.op()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStoreExpiresLazily(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore().(*memoryStore)
+	expired := time.Now().Add(-time.Minute)
+	for i := 0; i < 10*expireBatch; i++ {
+		s.records[strconv.Itoa(i)] = &Record{ExpiresAt: expired}
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := s.Reserve(ctx, "new"+strconv.Itoa(i), &Record{ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+		// each reservation checks at most expireBatch records
+		if want := 10*expireBatch - (i+1)*expireBatch + i + 1; len(s.records) < want {
+			t.Fatalf("after %d reservations %d records are left, want at least %d", i+1, len(s.records), want)
+		}
+	}
+	if len(s.records) >= 10*expireBatch {
+		t.Errorf("%d records are left, expired ones were not removed", len(s.records))
+	}
+}
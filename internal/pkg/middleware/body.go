@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// rawBodyKey defines the key in gin context which holds the request body
+// before BodyLimit limited it.
+const rawBodyKey = "rawBody"
+
+// BodyLimit is a middleware that limits the request body to n bytes, no limit
+// if n is not positive. Reading a body exceeding the limit fails with
+// *http.MaxBytesError, which binding reports with 413. The limit is enforced
+// while reading, so a BodyLimit installed later, e.g. on a route group,
+// replaces it.
+func BodyLimit(n int64) gin.HandlerFunc {
+	return BodyLimitFunc(func(*gin.Context) int64 { return n })
+}
+
+// BodyLimitFunc is like BodyLimit with the limit of each request returned by limit.
+func BodyLimitFunc(limit func(c *gin.Context) int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n := limit(c)
+		body, ok := c.Get(rawBodyKey)
+		if !ok {
+			body = c.Request.Body
+			c.Set(rawBodyKey, body)
+		}
+		if n <= 0 {
+			c.Request.Body = body.(io.ReadCloser)
+			c.Next()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, body.(io.ReadCloser), n)
+		c.Next()
+	}
+}
+
+// ContentType is a middleware that rejects POST, PUT and PATCH requests with a
+// body whose Content-Type is none of the media types with 415. A media type
+// with a structured syntax suffix, e.g. "application/vnd.user.v2+json", is
+// accepted if its base type, e.g. "application/json", is.
+// A ContentType installed later, e.g. on a route group, checks again.
+func ContentType(mediaTypes ...string) gin.HandlerFunc {
+	return ContentTypeFunc(func(*gin.Context) []string { return mediaTypes })
+}
+
+// ContentTypeFunc is like ContentType with the media types accepted for each
+// request returned by mediaTypes.
+func ContentTypeFunc(mediaTypes func(c *gin.Context) []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		allowed := mediaTypes(c)
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || !accepts(allowed, mediaType) && !accepts(allowed, suffixType(mediaType)) {
+			core.WriteResponse(c, errors.WithCode(code.ErrUnsupportedMediaType,
+				"content type %q is not one of %s", c.GetHeader("Content-Type"), strings.Join(allowed, ", ")), nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// accepts reports whether mediaType is one of the media types.
+func accepts(mediaTypes []string, mediaType string) bool {
+	for _, mt := range mediaTypes {
+		if mediaType != "" && strings.EqualFold(mt, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suffixType returns the media type of the structured syntax suffix of
+// mediaType, e.g. "application/json" for "application/problem+json".
+func suffixType(mediaType string) string {
+	i := strings.LastIndexByte(mediaType, '+')
+	if i < 0 {
+		return ""
+	}
+
+	return "application/" + mediaType[i+1:]
+}
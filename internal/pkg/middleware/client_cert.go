@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"time"
+)
+
+// ClientCertKey defines the key in gin context which holds the *ClientCertInfo
+// of a request authenticated with a client certificate.
+const ClientCertKey = "clientCert"
+
+// Sources of the username of a client certificate.
+const (
+	// UsernameFromCommonName uses the common name of the subject.
+	UsernameFromCommonName = "cn"
+	// UsernameFromDNS uses the first DNS name subject alternative name.
+	UsernameFromDNS = "dns"
+	// UsernameFromEmail uses the first email address subject alternative name.
+	UsernameFromEmail = "email"
+	// UsernameFromURI uses the first URI subject alternative name.
+	UsernameFromURI = "uri"
+)
+
+// ClientCertInfo describes the verified client certificate of a request.
+type ClientCertInfo struct {
+	Subject        string    `json:"subject"`
+	CommonName     string    `json:"commonName"`
+	Issuer         string    `json:"issuer"`
+	SerialNumber   string    `json:"serialNumber"`
+	DNSNames       []string  `json:"dnsNames,omitempty"`
+	EmailAddresses []string  `json:"emailAddresses,omitempty"`
+	URIs           []string  `json:"uris,omitempty"`
+	NotBefore      time.Time `json:"notBefore"`
+	NotAfter       time.Time `json:"notAfter"`
+}
+
+// NewClientCertInfo returns the information of the certificate.
+func NewClientCertInfo(cert *x509.Certificate) *ClientCertInfo {
+	info := &ClientCertInfo{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		Issuer:         cert.Issuer.String(),
+		SerialNumber:   cert.SerialNumber.String(),
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		NotBefore:      cert.NotBefore,
+		NotAfter:       cert.NotAfter,
+	}
+	for _, uri := range cert.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+
+	return info
+}
+
+// Username returns the username of the certificate taken from the given
+// source, one of the UsernameFrom constants, empty if it has none.
+func (i *ClientCertInfo) Username(from string) string {
+	var names []string
+	switch from {
+	case UsernameFromCommonName:
+		return i.CommonName
+	case UsernameFromDNS:
+		names = i.DNSNames
+	case UsernameFromEmail:
+		names = i.EmailAddresses
+	case UsernameFromURI:
+		names = i.URIs
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
+
+// ClientCert is a middleware that injects the verified client certificate of
+// the request into the context and sets UsernameKey from it. Requests without
+// a verified client certificate pass unchanged. It must be installed before
+// Context.
+func ClientCert(usernameFrom string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			info := NewClientCertInfo(c.Request.TLS.VerifiedChains[0][0])
+			c.Set(ClientCertKey, info)
+			if username := info.Username(usernameFrom); username != "" {
+				c.Set(UsernameKey, username)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireClientCert is a middleware that rejects requests without a verified
+// client certificate, e.g. the ones on plain HTTP listeners, except the
+// requests to the exempt paths.
+func RequireClientCert(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			c.Next()
+			return
+		}
+		for _, path := range exempt {
+			if c.Request.URL.Path == path {
+				c.Next()
+				return
+			}
+		}
+
+		core.WriteResponse(c, errors.WithCode(code.ErrClientCertRequired,
+			"%s %s requires a verified client certificate", c.Request.Method, c.Request.URL.Path), nil)
+		c.Abort()
+	}
+}
+
+// GetClientCert returns the verified client certificate of the request if present.
+func GetClientCert(c *gin.Context) (*ClientCertInfo, bool) {
+	if v, ok := c.Get(ClientCertKey); ok {
+		if info, ok := v.(*ClientCertInfo); ok {
+			return info, true
+		}
+	}
+
+	return nil, false
+}
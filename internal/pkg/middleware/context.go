@@ -1,15 +1,20 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/request"
+)
 
 // UsernameKey defines the key in gin context which represents the owner of the secret.
 const UsernameKey = "username"
 
 // Context is a middleware that injects common prefix fields to gin.Context.
+// It also puts the username into the request context, see request.UserFrom.
 func Context() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Set("requestID", c.GetString(XRequestIDKey))
 		c.Set("username", c.GetString(UsernameKey))
+		c.Request = c.Request.WithContext(request.WithUser(c.Request.Context(), c.GetString(UsernameKey)))
 		c.Next()
 	}
 }
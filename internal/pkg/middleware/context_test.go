@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/request"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		header string
+		tls    bool
+		cert   string
+		want   string
+	}{
+		{"no header", "", false, "", ""},
+		{"header", "alice", false, "", "alice"},
+		{"header over tls ignored", "alice", true, "", ""},
+		{"client certificate wins", "alice", false, "bob", "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tt.cert != "" {
+					c.Set(UsernameKey, tt.cert)
+				}
+			})
+			r.Use(ProxyUser("X-Remote-User"), Context())
+			r.GET("/", func(c *gin.Context) {
+				got = request.UserFrom(c.Request.Context())
+				if username := c.GetString(UsernameKey); username != got {
+					t.Errorf("gin username = %q, request user = %q", username, got)
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Remote-User", tt.header)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("user = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
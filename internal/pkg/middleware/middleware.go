@@ -56,6 +56,7 @@ func defaultMiddlewares() map[string]gin.HandlerFunc {
 		"nocache":   NoCache,
 		"cors":      Cors(),
 		"requestid": RequestID(),
+		"logger":    gin.LoggerWithConfig(GetLoggerConfig(nil, nil, nil)),
 		"dump":      gindump.Dump(),
 	}
 }
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/request"
+)
+
+// OriginalPath is a middleware that restores the path of requests which
+// were rewritten to be routed, see request.WithOriginalPath, so the later
+// middlewares and the handlers see the path the request was sent to.
+func OriginalPath() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if path, ok := request.OriginalPathFrom(c.Request.Context()); ok {
+			c.Request.URL.Path = path
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ProxyUser is a middleware that sets UsernameKey from the header set by the
+// authenticating proxy in front of the plain listeners, e.g. X-Remote-User.
+// The header is ignored on TLS requests, clients reach those directly and
+// could set it themselves, and on requests already authenticated with a
+// client certificate. It must be installed before Context.
+func ProxyUser(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil && c.GetString(UsernameKey) == "" {
+			if username := c.GetHeader(header); username != "" {
+				c.Set(UsernameKey, username)
+			}
+		}
+		c.Next()
+	}
+}
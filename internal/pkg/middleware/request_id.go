@@ -46,7 +46,7 @@ func GetLoggerConfig(formatter gin.LogFormatter, output io.Writer, skipPaths []s
 	}
 }
 
-// GetDefaultLogFormatterWithRequestID returns gin.LogFormatter with 'RequestID'.
+// GetDefaultLogFormatterWithRequestID returns gin.LogFormatter with 'RequestID' and 'TraceID'.
 func GetDefaultLogFormatterWithRequestID() gin.LogFormatter {
 	return func(param gin.LogFormatterParams) string {
 		var statusColor, methodColor, resetColor string
@@ -60,14 +60,17 @@ func GetDefaultLogFormatterWithRequestID() gin.LogFormatter {
 			// Truncate in a golang < 1.8 safe way
 			param.Latency -= param.Latency % time.Second
 		}
+		traceID, _ := param.Keys[TraceIDKey].(string)
 
-		return fmt.Sprintf("%s%3d%s - [%s] \"%v %s%s%s %s\" %s",
+		return fmt.Sprintf("%s%3d%s - [%s] \"%v %s%s%s %s\" request_id=%s trace_id=%s %s\n",
 			// param.TimeStamp.Format("2006/01/02 - 15:04:05"),
 			statusColor, param.StatusCode, resetColor,
 			param.ClientIP,
 			param.Latency,
 			methodColor, param.Method, resetColor,
 			param.Path,
+			param.Request.Header.Get(XRequestIDKey),
+			traceID,
 			param.ErrorMessage,
 		)
 	}
@@ -0,0 +1,29 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// drainingKey defines the key in gin context which holds the channel closed
+// when the server stops accepting connections.
+const drainingKey = "draining"
+
+// Draining is a middleware that makes the channel closed when the server
+// stops accepting connections available to handlers, see DrainingFrom.
+func Draining(ch <-chan struct{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(drainingKey, ch)
+		c.Next()
+	}
+}
+
+// DrainingFrom returns the channel closed when the server stops accepting
+// connections. Long-running handlers, e.g. streams, return when it is closed
+// so shutdown need not wait for them. It is nil without the Draining middleware.
+func DrainingFrom(c *gin.Context) <-chan struct{} {
+	if v, ok := c.Get(drainingKey); ok {
+		if ch, ok := v.(<-chan struct{}); ok {
+			return ch
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/tracing"
+	"net/http"
+)
+
+// TraceIDKey defines the key in gin context which holds the trace ID of the request.
+const TraceIDKey = "traceID"
+
+// requestIDAttribute is the span attribute holding the X-Request-ID.
+const requestIDAttribute = attribute.Key("http.request_id")
+
+// Tracing is a middleware that continues the trace passed in the W3C
+// traceparent header, or starts a new one, with a server span per route.
+// The span and the trace ID, see core.TraceIDFrom, are stored in the
+// request context. It must be installed after RequestID.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		name := c.Request.Method
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+			requestIDAttribute.String(GetRequestIDFromHeaders(c)),
+		}
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			ctx = core.WithTraceID(ctx, traceID)
+			c.Set(TraceIDKey, traceID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// GetTraceIDFromContext returns the trace ID of the request if present.
+func GetTraceIDFromContext(c *gin.Context) string {
+	return c.GetString(TraceIDKey)
+}
@@ -0,0 +1,49 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// DeletionOptions contains the options of purging deleted resources.
+type DeletionOptions struct {
+	Retention     time.Duration `json:"retention"      mapstructure:"retention"`
+	PurgeInterval time.Duration `json:"purge-interval" mapstructure:"purge-interval"`
+}
+
+// NewDeletionOptions creates a DeletionOptions object with default parameters.
+func NewDeletionOptions() *DeletionOptions {
+	return &DeletionOptions{
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+}
+
+// PurgeEnabled reports whether deleted resources are purged.
+func (d *DeletionOptions) PurgeEnabled() bool {
+	return d.Retention > 0
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (d *DeletionOptions) Validate() []error {
+	var errors []error
+
+	if d.Retention < 0 {
+		errors = append(errors, fmt.Errorf("--deletion.retention %v must not be negative", d.Retention))
+	}
+	if d.PurgeEnabled() && d.PurgeInterval <= 0 {
+		errors = append(errors, fmt.Errorf("--deletion.purge-interval %v must be positive", d.PurgeInterval))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to deleting resources to the specified FlagSet.
+func (d *DeletionOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&d.Retention, "deletion.retention", d.Retention, ""+
+		"How long deleted resources can be restored before they are purged. They are never purged if 0.")
+	fs.DurationVar(&d.PurgeInterval, "deletion.purge-interval", d.PurgeInterval, ""+
+		"How often resources deleted longer than the retention ago are purged.")
+}
@@ -5,23 +5,51 @@ import (
 	"github.com/spf13/pflag"
 	"golang-standards-project-example/internal/pkg/server"
 	"net"
+	"os"
 	"strconv"
+	"time"
 )
 
 type HttpServingOptions struct {
-	BindAddress string `json:"bind-address" mapstructure:"bind-address"`
-	BindPort    int    `json:"bind-port"    mapstructure:"bind-port"`
+	BindAddress      string        `json:"bind-address"       mapstructure:"bind-address"`
+	BindPort         int           `json:"bind-port"          mapstructure:"bind-port"`
+	UnixSocket       string        `json:"unix-socket"        mapstructure:"unix-socket"`
+	UnixSocketMode   string        `json:"unix-socket-mode"   mapstructure:"unix-socket-mode"`
+	UnixSocketOwner  string        `json:"unix-socket-owner"  mapstructure:"unix-socket-owner"`
+	SocketActivation bool          `json:"socket-activation"  mapstructure:"socket-activation"`
+	H2C              bool          `json:"h2c"                mapstructure:"h2c"`
+	UsernameHeader   string        `json:"username-header"    mapstructure:"username-header"`
+	SelfCheckTimeout time.Duration `json:"self-check-timeout" mapstructure:"self-check-timeout"`
 }
 
 func NewHttpServingOptions() *HttpServingOptions {
 	return &HttpServingOptions{
-		BindAddress: "127.0.0.1",
-		BindPort:    8080,
+		BindAddress:      "127.0.0.1",
+		BindPort:         8080,
+		SelfCheckTimeout: 10 * time.Second,
 	}
 }
 
+// ApplyTo applies the run options to the method receiver and returns self.
+// The sockets passed by socket activation are taken over at startup.
 func (h *HttpServingOptions) ApplyTo(c *server.Config) error {
-	c.HttpServing = &server.HttpServingInfo{Address: net.JoinHostPort(h.BindAddress, strconv.Itoa(h.BindPort))}
+	c.HttpServing = &server.HttpServingInfo{
+		UnixSocket:      h.UnixSocket,
+		UnixSocketOwner: h.UnixSocketOwner,
+		H2C:             h.H2C,
+		UsernameHeader:  h.UsernameHeader,
+	}
+	if h.BindPort != 0 {
+		c.HttpServing.Address = net.JoinHostPort(h.BindAddress, strconv.Itoa(h.BindPort))
+	}
+	if h.UnixSocketMode != "" {
+		mode, err := strconv.ParseUint(h.UnixSocketMode, 8, 32)
+		if err != nil {
+			return err
+		}
+		c.HttpServing.UnixSocketMode = os.FileMode(mode)
+	}
+	c.SelfCheckTimeout = h.SelfCheckTimeout
 	return nil
 }
 
@@ -40,6 +68,22 @@ func (h *HttpServingOptions) Validate() []error {
 		)
 	}
 
+	if h.BindPort == 0 && h.UnixSocket == "" && !h.SocketActivation {
+		errors = append(errors, fmt.Errorf(
+			"one of --http.bind-port, --http.unix-socket or --http.socket-activation must be set",
+		))
+	}
+
+	if h.UnixSocketMode != "" {
+		if _, err := strconv.ParseUint(h.UnixSocketMode, 8, 32); err != nil {
+			errors = append(errors, fmt.Errorf("--http.unix-socket-mode %q must be an octal file mode, e.g. 0660", h.UnixSocketMode))
+		}
+	}
+
+	if h.SelfCheckTimeout < 0 {
+		errors = append(errors, fmt.Errorf("--http.self-check-timeout %v must not be negative", h.SelfCheckTimeout))
+	}
+
 	return errors
 }
 
@@ -54,4 +98,19 @@ func (h *HttpServingOptions) AddFlags(fs *pflag.FlagSet) {
 		"that firewall rules are set up such that this port is not reachable from outside of "+
 		"the deployed machine and that port 443 on the iam public address is proxied to this "+
 		"port. This is performed by nginx in the default setup. Set to zero to disable.")
+	fs.StringVar(&h.UnixSocket, "http.unix-socket", h.UnixSocket, ""+
+		"The path of a unix socket on which to serve, e.g. behind a local proxy. Empty to disable.")
+	fs.StringVar(&h.UnixSocketMode, "http.unix-socket-mode", h.UnixSocketMode, ""+
+		"The octal file mode of the unix socket, e.g. 0660.")
+	fs.StringVar(&h.UnixSocketOwner, "http.unix-socket-owner", h.UnixSocketOwner, ""+
+		"The owner of the unix socket as user[:group], names or numeric ids.")
+	fs.BoolVar(&h.SocketActivation, "http.socket-activation", h.SocketActivation, ""+
+		"Serve on the sockets passed by systemd socket activation (LISTEN_FDS).")
+	fs.BoolVar(&h.H2C, "http.h2c", h.H2C, ""+
+		"Serve HTTP/2 without TLS (h2c) next to HTTP/1.1, e.g. for internal service-to-service traffic.")
+	fs.StringVar(&h.UsernameHeader, "http.username-header", h.UsernameHeader, ""+
+		"The header carrying the username set by the authenticating proxy in front of the plain "+
+		"listeners, e.g. X-Remote-User. The username is recorded as the author of changes. Empty to disable.")
+	fs.DurationVar(&h.SelfCheckTimeout, "http.self-check-timeout", h.SelfCheckTimeout, ""+
+		"The time to wait for the server to answer its own /healthz after startup. Set to zero to disable the self check.")
 }
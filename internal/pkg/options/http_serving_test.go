@@ -0,0 +1,53 @@
+package options
+
+import (
+	"golang-standards-project-example/internal/pkg/server"
+	"golang-standards-project-example/pkg/shutdown/upgrade"
+	"os"
+	"testing"
+)
+
+func TestHttpServingOptionsApplyTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(h *HttpServingOptions)
+		wantAddr string
+		wantMode os.FileMode
+		wantErr  bool
+	}{
+		{name: "defaults", modify: func(h *HttpServingOptions) {}, wantAddr: "127.0.0.1:8080"},
+		{name: "unix socket only", modify: func(h *HttpServingOptions) {
+			h.BindPort, h.UnixSocket, h.UnixSocketMode = 0, "/tmp/api.sock", "660"
+		}, wantMode: 0o660},
+		{name: "invalid mode", modify: func(h *HttpServingOptions) {
+			h.UnixSocketMode = "9"
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// sockets handed over by an upgrade are left for the server startup
+			t.Setenv(upgrade.EnvListenFds, "1")
+			h := NewHttpServingOptions()
+			tt.modify(h)
+			c := server.NewConfig()
+
+			err := h.ApplyTo(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyTo() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if c.HttpServing.Address != tt.wantAddr || c.HttpServing.UnixSocketMode != tt.wantMode {
+				t.Errorf("ApplyTo() address %q mode %v, want %q %v",
+					c.HttpServing.Address, c.HttpServing.UnixSocketMode, tt.wantAddr, tt.wantMode)
+			}
+			if c.Inherited != nil {
+				t.Error("ApplyTo() took over inherited sockets")
+			}
+			if os.Getenv(upgrade.EnvListenFds) != "1" {
+				t.Error("ApplyTo() consumed the upgrade environment")
+			}
+		})
+	}
+}
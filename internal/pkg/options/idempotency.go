@@ -0,0 +1,61 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"time"
+)
+
+// Idempotency record backends.
+const (
+	// IdempotencyBackendMemory keeps the records in the memory of the process.
+	IdempotencyBackendMemory = "memory"
+	// IdempotencyBackendStore keeps the records in the store of the resources.
+	IdempotencyBackendStore = "store"
+)
+
+// IdempotencyOptions contains the options of Idempotency-Key handling.
+type IdempotencyOptions struct {
+	TTL     time.Duration `json:"ttl"     mapstructure:"ttl"`
+	Backend string        `json:"backend" mapstructure:"backend"`
+}
+
+// NewIdempotencyOptions creates an IdempotencyOptions object with default parameters.
+func NewIdempotencyOptions() *IdempotencyOptions {
+	return &IdempotencyOptions{
+		TTL:     24 * time.Hour,
+		Backend: IdempotencyBackendMemory,
+	}
+}
+
+// Enabled reports whether Idempotency-Key headers are honoured.
+func (i *IdempotencyOptions) Enabled() bool {
+	return i.TTL > 0
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (i *IdempotencyOptions) Validate() []error {
+	var errors []error
+
+	if i.TTL < 0 {
+		errors = append(errors, fmt.Errorf("--idempotency.ttl %v must not be negative", i.TTL))
+	}
+	switch i.Backend {
+	case IdempotencyBackendMemory, IdempotencyBackendStore:
+	default:
+		errors = append(errors, fmt.Errorf("--idempotency.backend %q must be one of 'memory' or 'store'", i.Backend))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to idempotency keys to the specified FlagSet.
+func (i *IdempotencyOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&i.TTL, "idempotency.ttl", i.TTL, ""+
+		"How long the response of a POST request is replayed to retries with the same Idempotency-Key. "+
+		"Idempotency-Key headers are ignored if 0.")
+	fs.StringVar(&i.Backend, "idempotency.backend", i.Backend, ""+
+		"Where the responses are kept, 'memory' (per process) or 'store' (with the resources in the apiserver store, "+
+		"so instances sharing a store share them; the in-memory store is per process as well).")
+}
@@ -0,0 +1,28 @@
+package options
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// LogOptions contains the options of the application log.
+type LogOptions struct {
+	OutputPath string `json:"output-path" mapstructure:"output-path"`
+}
+
+// NewLogOptions creates a LogOptions object with default parameters.
+func NewLogOptions() *LogOptions {
+	return &LogOptions{}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (l *LogOptions) Validate() []error {
+	return nil
+}
+
+// AddFlags adds flags related to the log to the specified FlagSet.
+func (l *LogOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&l.OutputPath, "log.output-path", l.OutputPath, ""+
+		"The file the log is appended to, stderr if empty. The file is reopened on SIGHUP, "+
+		"which also switches the log to a changed path.")
+}
@@ -0,0 +1,145 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"golang-standards-project-example/internal/pkg/server"
+	"net"
+	"strconv"
+)
+
+// SecureServingOptions contains configuration items related to HTTPS server startup.
+type SecureServingOptions struct {
+	BindAddress string     `json:"bind-address" mapstructure:"bind-address"`
+	BindPort    int        `json:"bind-port"    mapstructure:"bind-port"`
+	TLS         CertKey    `json:"tls"          mapstructure:"tls"`
+	HTTP3       bool       `json:"http3"        mapstructure:"http3"`
+	ClientAuth  ClientAuth `json:"client-auth"  mapstructure:"client-auth"`
+}
+
+// CertKey contains configuration items related to certificate.
+type CertKey struct {
+	CertFile string `json:"cert-file"        mapstructure:"cert-file"`
+	KeyFile  string `json:"private-key-file" mapstructure:"private-key-file"`
+}
+
+// ClientAuth contains configuration items related to client certificate authentication.
+type ClientAuth struct {
+	CAFile       string `json:"ca-file"       mapstructure:"ca-file"`
+	Required     bool   `json:"required"      mapstructure:"required"`
+	CRLFile      string `json:"crl-file"      mapstructure:"crl-file"`
+	UsernameFrom string `json:"username-from" mapstructure:"username-from"`
+}
+
+// NewSecureServingOptions creates a SecureServingOptions object with default parameters.
+func NewSecureServingOptions() *SecureServingOptions {
+	return &SecureServingOptions{
+		BindAddress: "0.0.0.0",
+		BindPort:    8443,
+		ClientAuth: ClientAuth{
+			UsernameFrom: middleware.UsernameFromCommonName,
+		},
+	}
+}
+
+// Enabled reports whether the secure server is configured, i.e. a
+// certificate is given.
+func (s *SecureServingOptions) Enabled() bool {
+	return s.TLS.CertFile != ""
+}
+
+// ApplyTo applies the run options to the method receiver and returns self.
+func (s *SecureServingOptions) ApplyTo(c *server.Config) error {
+	if !s.Enabled() {
+		c.SecureServing = nil
+		return nil
+	}
+
+	c.SecureServing = &server.SecureServingInfo{
+		Address: net.JoinHostPort(s.BindAddress, strconv.Itoa(s.BindPort)),
+		CertKey: server.CertKey{
+			CertFile: s.TLS.CertFile,
+			KeyFile:  s.TLS.KeyFile,
+		},
+		HTTP3: s.HTTP3,
+	}
+	if s.ClientAuth.CAFile != "" {
+		c.SecureServing.ClientAuth = &server.ClientAuth{
+			CAFile:       s.ClientAuth.CAFile,
+			Required:     s.ClientAuth.Required,
+			CRLFile:      s.ClientAuth.CRLFile,
+			UsernameFrom: s.ClientAuth.UsernameFrom,
+		}
+	}
+	return nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (s *SecureServingOptions) Validate() []error {
+	var errors []error
+
+	if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		errors = append(errors, fmt.Errorf(
+			"--secure.tls.cert-file and --secure.tls.private-key-file must be set together",
+		))
+	}
+
+	if s.Enabled() && (s.BindPort < 1 || s.BindPort > 65535) {
+		errors = append(errors, fmt.Errorf(
+			"--secure.bind-port %v must be between 1 and 65535, inclusive", s.BindPort,
+		))
+	}
+
+	if s.HTTP3 && !s.Enabled() {
+		errors = append(errors, fmt.Errorf("--secure.http3 requires --secure.tls.cert-file"))
+	}
+
+	if s.ClientAuth.CAFile == "" && (s.ClientAuth.Required || s.ClientAuth.CRLFile != "") {
+		errors = append(errors, fmt.Errorf(
+			"--secure.client-auth.required and --secure.client-auth.crl-file require --secure.client-auth.ca-file",
+		))
+	}
+
+	switch s.ClientAuth.UsernameFrom {
+	case middleware.UsernameFromCommonName, middleware.UsernameFromDNS,
+		middleware.UsernameFromEmail, middleware.UsernameFromURI:
+	default:
+		errors = append(errors, fmt.Errorf(
+			"--secure.client-auth.username-from %q must be one of 'cn', 'dns', 'email' or 'uri'", s.ClientAuth.UsernameFrom,
+		))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to HTTPS server for a specific APIServer to the
+// specified FlagSet.
+func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.BindAddress, "secure.bind-address", s.BindAddress, ""+
+		"The IP address on which to listen for the --secure.bind-port port "+
+		"(set to 0.0.0.0 for all IPv4 interfaces and :: for all IPv6 interfaces).")
+	fs.IntVar(&s.BindPort, "secure.bind-port", s.BindPort, ""+
+		"The port on which to serve HTTPS with authentication and authorization. "+
+		"HTTP/3 is served on the same UDP port if enabled.")
+	fs.StringVar(&s.TLS.CertFile, "secure.tls.cert-file", s.TLS.CertFile, ""+
+		"File containing the default x509 Certificate for HTTPS. (CA cert, if any, concatenated "+
+		"after server cert). The secure server is disabled if not set.")
+	fs.StringVar(&s.TLS.KeyFile, "secure.tls.private-key-file", s.TLS.KeyFile, ""+
+		"File containing the default x509 private key matching --secure.tls.cert-file.")
+	fs.BoolVar(&s.HTTP3, "secure.http3", s.HTTP3, ""+
+		"Serve HTTP/3 over QUIC on the UDP port of --secure.bind-port and announce it with Alt-Svc.")
+	fs.StringVar(&s.ClientAuth.CAFile, "secure.client-auth.ca-file", s.ClientAuth.CAFile, ""+
+		"File containing the PEM-encoded CA bundle client certificates are verified against. "+
+		"Client certificate authentication is disabled if not set.")
+	fs.BoolVar(&s.ClientAuth.Required, "secure.client-auth.required", s.ClientAuth.Required, ""+
+		"Reject connections without a valid client certificate, otherwise a client certificate is verified only if given. "+
+		"Requests on the plain HTTP listeners are rejected as well, except /healthz and /readyz.")
+	fs.StringVar(&s.ClientAuth.CRLFile, "secure.client-auth.crl-file", s.ClientAuth.CRLFile, ""+
+		"File containing PEM or DER encoded certificate revocation lists of the client CAs. "+
+		"The file is reloaded when it changes. Certificates of a CA whose list is past its next update are rejected.")
+	fs.StringVar(&s.ClientAuth.UsernameFrom, "secure.client-auth.username-from", s.ClientAuth.UsernameFrom, ""+
+		"The client certificate field used as username, one of 'cn' (subject common name), "+
+		"or the first 'dns', 'email' or 'uri' subject alternative name.")
+}
@@ -0,0 +1,103 @@
+package options
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/pflag"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"golang-standards-project-example/internal/pkg/server"
+	"mime"
+	"strings"
+)
+
+// ServerRunOptions contains the options while running a generic api server.
+type ServerRunOptions struct {
+	Mode               string           `json:"mode"        mapstructure:"mode"`
+	Healthz            bool             `json:"healthz"     mapstructure:"healthz"`
+	Middlewares        []string         `json:"middlewares" mapstructure:"middlewares"`
+	SwaggerUI          bool             `json:"swagger-ui"  mapstructure:"swagger-ui"`
+	MaxBodyBytes       int64            `json:"max-body-bytes" mapstructure:"max-body-bytes"`
+	PrefixMaxBodyBytes map[string]int64 `json:"prefix-max-body-bytes" mapstructure:"prefix-max-body-bytes"`
+	ContentTypes       []string         `json:"content-types"  mapstructure:"content-types"`
+}
+
+// NewServerRunOptions creates a new ServerRunOptions object with default parameters.
+func NewServerRunOptions() *ServerRunOptions {
+	defaults := server.NewConfig()
+
+	return &ServerRunOptions{
+		Mode:         defaults.Mode,
+		Healthz:      defaults.Healthz,
+		Middlewares:  defaults.Middlewares,
+		SwaggerUI:    defaults.SwaggerUI,
+		MaxBodyBytes: defaults.MaxBodyBytes,
+		ContentTypes: defaults.ContentTypes,
+	}
+}
+
+// ApplyTo applies the run options to the method receiver and returns self.
+func (s *ServerRunOptions) ApplyTo(c *server.Config) error {
+	c.Mode = s.Mode
+	c.Healthz = s.Healthz
+	c.Middlewares = s.Middlewares
+	c.SwaggerUI = s.SwaggerUI
+	c.MaxBodyBytes = s.MaxBodyBytes
+	c.PrefixMaxBodyBytes = s.PrefixMaxBodyBytes
+	c.ContentTypes = s.ContentTypes
+
+	return nil
+}
+
+// Validate checks validation of ServerRunOptions.
+func (s *ServerRunOptions) Validate() []error {
+	var errors []error
+
+	switch s.Mode {
+	case gin.DebugMode, gin.TestMode, gin.ReleaseMode:
+	default:
+		errors = append(errors, fmt.Errorf("--server.mode %q must be one of 'debug', 'test' or 'release'", s.Mode))
+	}
+
+	for _, m := range s.Middlewares {
+		if _, ok := middleware.Middlewares[m]; !ok {
+			errors = append(errors, fmt.Errorf("--server.middlewares: unknown middleware %q", m))
+		}
+	}
+
+	for prefix := range s.PrefixMaxBodyBytes {
+		if !strings.HasPrefix(prefix, "/") {
+			errors = append(errors, fmt.Errorf("--server.prefix-max-body-bytes: path prefix %q must start with '/'", prefix))
+		}
+	}
+	if len(s.ContentTypes) == 0 {
+		errors = append(errors, fmt.Errorf("--server.content-types must not be empty"))
+	}
+	for _, ct := range s.ContentTypes {
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			errors = append(errors, fmt.Errorf("--server.content-types: invalid media type %q: %w", ct, err))
+		}
+	}
+
+	return errors
+}
+
+// AddFlags adds flags for a specific APIServer to the specified FlagSet.
+func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.Mode, "server.mode", s.Mode, ""+
+		"Start the server in a specified server mode. Supported server mode: debug, test, release.")
+	fs.BoolVar(&s.Healthz, "server.healthz", s.Healthz, ""+
+		"Add self readiness check and install /healthz and /readyz router.")
+	fs.StringSliceVar(&s.Middlewares, "server.middlewares", s.Middlewares, ""+
+		"List of additional middlewares for server, comma separated, e.g. logger,recovery. "+
+		"The request id, tracing and context middlewares are always installed.")
+	fs.BoolVar(&s.SwaggerUI, "server.swagger-ui", s.SwaggerUI, ""+
+		"Serve the Swagger UI for the /openapi.json document at /swagger/index.html.")
+	fs.Int64Var(&s.MaxBodyBytes, "server.max-body-bytes", s.MaxBodyBytes, ""+
+		"The maximum size of request bodies in bytes, larger bodies are rejected with 413. No limit if 0.")
+	fs.StringToInt64Var(&s.PrefixMaxBodyBytes, "server.prefix-max-body-bytes", s.PrefixMaxBodyBytes, ""+
+		"The maximum size of request bodies under a path prefix, overriding --server.max-body-bytes, "+
+		"e.g. /v2/users/import=104857600. The longest matching prefix wins.")
+	fs.StringSliceVar(&s.ContentTypes, "server.content-types", s.ContentTypes, ""+
+		"The media types accepted for POST, PUT and PATCH bodies, others are rejected with 415. "+
+		"Media types with a structured syntax suffix, e.g. application/vnd.user+json, are accepted with their base type.")
+}
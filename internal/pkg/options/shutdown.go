@@ -0,0 +1,95 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"golang-standards-project-example/internal/pkg/server"
+	"golang-standards-project-example/pkg/shutdown"
+	"golang-standards-project-example/pkg/shutdown/upgrade"
+	"time"
+)
+
+// ShutdownOptions contains the options of graceful shutdown and the
+// additional ways to request it besides SIGINT and SIGTERM.
+type ShutdownOptions struct {
+	Timeout        time.Duration `json:"timeout"         mapstructure:"timeout"`
+	Delay          time.Duration `json:"delay"           mapstructure:"delay"`
+	DrainTimeout   time.Duration `json:"drain-timeout"   mapstructure:"drain-timeout"`
+	AdminAddress   string        `json:"admin-address"   mapstructure:"admin-address"`
+	AdminToken     string        `json:"admin-token"     mapstructure:"admin-token"     secret:"true"`
+	WatchFile      string        `json:"watch-file"      mapstructure:"watch-file"`
+	WatchStdin     bool          `json:"watch-stdin"     mapstructure:"watch-stdin"`
+	Upgrade        bool          `json:"upgrade"         mapstructure:"upgrade"`
+	UpgradeTimeout time.Duration `json:"upgrade-timeout" mapstructure:"upgrade-timeout"`
+}
+
+// NewShutdownOptions creates a ShutdownOptions object with default parameters.
+func NewShutdownOptions() *ShutdownOptions {
+	return &ShutdownOptions{
+		Timeout:        shutdown.DefaultTimeout,
+		DrainTimeout:   10 * time.Second,
+		UpgradeTimeout: upgrade.DefaultTimeout,
+	}
+}
+
+// ApplyTo applies the run options to the method receiver and returns self.
+func (s *ShutdownOptions) ApplyTo(c *server.Config) error {
+	c.ShutdownDelay = s.Delay
+	c.ShutdownTimeout = s.DrainTimeout
+
+	return nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (s *ShutdownOptions) Validate() []error {
+	var errors []error
+
+	if s.Timeout < 0 {
+		errors = append(errors, fmt.Errorf("--shutdown.timeout %v must not be negative", s.Timeout))
+	}
+	if s.Delay < 0 || s.DrainTimeout < 0 {
+		errors = append(errors, fmt.Errorf("--shutdown.delay and --shutdown.drain-timeout must not be negative"))
+	}
+	if s.Timeout > 0 && s.Delay+s.DrainTimeout > s.Timeout {
+		errors = append(errors, fmt.Errorf(
+			"--shutdown.delay %v plus --shutdown.drain-timeout %v must not exceed --shutdown.timeout %v",
+			s.Delay, s.DrainTimeout, s.Timeout,
+		))
+	}
+	if s.UpgradeTimeout <= 0 {
+		errors = append(errors, fmt.Errorf("--shutdown.upgrade-timeout %v must be positive", s.UpgradeTimeout))
+	}
+	if s.AdminAddress != "" && s.AdminToken == "" {
+		errors = append(errors, fmt.Errorf("--shutdown.admin-token is required when --shutdown.admin-address is set"))
+	}
+	if s.AdminAddress != "" && s.Upgrade {
+		// the admin listener is not handed over, the upgraded process could not listen on it
+		errors = append(errors, fmt.Errorf("--shutdown.upgrade cannot be combined with --shutdown.admin-address"))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to graceful shutdown to the specified FlagSet.
+func (s *ShutdownOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&s.Timeout, "shutdown.timeout", s.Timeout, ""+
+		"The maximal duration of a graceful shutdown. Zero means no limit.")
+	fs.DurationVar(&s.Delay, "shutdown.delay", s.Delay, ""+
+		"The time /readyz fails while traffic is still served before the http server stops accepting connections.")
+	fs.DurationVar(&s.DrainTimeout, "shutdown.drain-timeout", s.DrainTimeout, ""+
+		"The maximal time to wait for in-flight requests before their connections are closed.")
+	fs.StringVar(&s.AdminAddress, "shutdown.admin-address", s.AdminAddress, ""+
+		"The address on which to serve the admin endpoint POST /shutdown. Empty to disable.")
+	fs.StringVar(&s.AdminToken, "shutdown.admin-token", s.AdminToken, ""+
+		"The bearer token required by the admin shutdown endpoint.")
+	fs.StringVar(&s.WatchFile, "shutdown.watch-file", s.WatchFile, ""+
+		"Shut down once this file is created. Empty to disable.")
+	fs.BoolVar(&s.WatchStdin, "shutdown.watch-stdin", s.WatchStdin, ""+
+		"Shut down once stdin is closed, e.g. by a supervisor.")
+	fs.BoolVar(&s.Upgrade, "shutdown.upgrade", s.Upgrade, ""+
+		"On SIGUSR2, start the executable again with the listening sockets and shut down once it is ready. "+
+		"The admin shutdown endpoint is not handed over, so it cannot be combined with --shutdown.admin-address.")
+	fs.DurationVar(&s.UpgradeTimeout, "shutdown.upgrade-timeout", s.UpgradeTimeout, ""+
+		"The time to wait for the upgraded process to become ready before it is killed.")
+}
@@ -0,0 +1,50 @@
+package options
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *ShutdownOptions)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(s *ShutdownOptions) {}},
+		{name: "admin", modify: func(s *ShutdownOptions) {
+			s.AdminAddress, s.AdminToken = "127.0.0.1:9090", "secret"
+		}},
+		{name: "upgrade", modify: func(s *ShutdownOptions) { s.Upgrade = true }},
+		{name: "admin without token", modify: func(s *ShutdownOptions) {
+			s.AdminAddress = "127.0.0.1:9090"
+		}, wantErr: "--shutdown.admin-token"},
+		{name: "upgrade with admin", modify: func(s *ShutdownOptions) {
+			s.AdminAddress, s.AdminToken, s.Upgrade = "127.0.0.1:9090", "secret", true
+		}, wantErr: "--shutdown.upgrade cannot be combined"},
+		{name: "delay exceeds timeout", modify: func(s *ShutdownOptions) {
+			s.Timeout, s.Delay = time.Second, time.Second
+		}, wantErr: "must not exceed"},
+		{name: "no upgrade timeout", modify: func(s *ShutdownOptions) {
+			s.UpgradeTimeout = 0
+		}, wantErr: "--shutdown.upgrade-timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewShutdownOptions()
+			tt.modify(s)
+
+			errs := s.Validate()
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("Validate() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package options
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	"golang-standards-project-example/pkg/tracing"
+)
+
+// TraceOptions contains the options of distributed tracing.
+type TraceOptions struct {
+	ServiceName string  `json:"service-name" mapstructure:"service-name"`
+	Exporter    string  `json:"exporter"     mapstructure:"exporter"`
+	FilePath    string  `json:"file-path"    mapstructure:"file-path"`
+	Endpoint    string  `json:"endpoint"     mapstructure:"endpoint"`
+	Insecure    bool    `json:"insecure"     mapstructure:"insecure"`
+	SampleRatio float64 `json:"sample-ratio" mapstructure:"sample-ratio"`
+}
+
+// NewTraceOptions creates a TraceOptions object with default parameters.
+func NewTraceOptions() *TraceOptions {
+	return &TraceOptions{
+		Endpoint:    "localhost:4318",
+		SampleRatio: 1,
+	}
+}
+
+// Config returns the tracer provider configuration.
+func (t *TraceOptions) Config() *tracing.Config {
+	return &tracing.Config{
+		ServiceName: t.ServiceName,
+		Exporter:    t.Exporter,
+		FilePath:    t.FilePath,
+		Endpoint:    t.Endpoint,
+		Insecure:    t.Insecure,
+		SampleRatio: t.SampleRatio,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (t *TraceOptions) Validate() []error {
+	var errors []error
+
+	switch t.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	case tracing.ExporterFile:
+		if t.FilePath == "" {
+			errors = append(errors, fmt.Errorf("--trace.file-path must be set for the file exporter"))
+		}
+	default:
+		errors = append(errors, fmt.Errorf(
+			"--trace.exporter %q must be one of '', 'stdout', 'file' or 'otlp'", t.Exporter,
+		))
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errors = append(errors, fmt.Errorf("--trace.sample-ratio %v must be between 0 and 1, inclusive", t.SampleRatio))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to tracing to the specified FlagSet.
+func (t *TraceOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&t.ServiceName, "trace.service-name", t.ServiceName, ""+
+		"The service name recorded in the spans, the application name if empty.")
+	fs.StringVar(&t.Exporter, "trace.exporter", t.Exporter, ""+
+		"The span exporter, one of 'stdout' (OTLP/JSON lines), 'file' (OTLP/JSON lines in --trace.file-path) or "+
+		"'otlp' (OTLP/HTTP to --trace.endpoint). Tracing is disabled if empty.")
+	fs.StringVar(&t.FilePath, "trace.file-path", t.FilePath, ""+
+		"The file spans are appended to by the file exporter.")
+	fs.StringVar(&t.Endpoint, "trace.endpoint", t.Endpoint, ""+
+		"The host:port of the OTLP/HTTP collector.")
+	fs.BoolVar(&t.Insecure, "trace.insecure", t.Insecure, ""+
+		"Send spans to the OTLP collector over plain HTTP instead of HTTPS.")
+	fs.Float64Var(&t.SampleRatio, "trace.sample-ratio", t.SampleRatio, ""+
+		"The ratio of new traces to sample. Traces continued from a traceparent header follow the caller's decision.")
+}
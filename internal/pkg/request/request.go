@@ -0,0 +1,42 @@
+// Package request carries request scoped values, e.g. the authenticated
+// user, through contexts so that the layers below the HTTP handlers need
+// not know about gin or the middlewares.
+package request
+
+import "context"
+
+// userKey is the key of the user in contexts.
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the user, empty users are not stored.
+func WithUser(ctx context.Context, user string) context.Context {
+	if user == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user of ctx, empty if it is unknown.
+func UserFrom(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+
+	return user
+}
+
+// originalPathKey is the key of the path of a rewritten request in contexts.
+type originalPathKey struct{}
+
+// WithOriginalPath returns a copy of ctx carrying the path a request was
+// sent to before it was rewritten to be routed.
+func WithOriginalPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, originalPathKey{}, path)
+}
+
+// OriginalPathFrom returns the path the request of ctx was sent to, if it
+// was rewritten to be routed.
+func OriginalPathFrom(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(originalPathKey{}).(string)
+
+	return path, ok
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUser(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"unset", context.Background(), ""},
+		{"set", WithUser(context.Background(), "alice"), "alice"},
+		{"empty keeps parent", WithUser(WithUser(context.Background(), "alice"), ""), "alice"},
+		{"overridden", WithUser(WithUser(context.Background(), "alice"), "bob"), "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserFrom(tt.ctx); got != tt.want {
+				t.Errorf("UserFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginalPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOK bool
+	}{
+		{"unset", context.Background(), "", false},
+		{"set", WithOriginalPath(context.Background(), "/users:import"), "/users:import", true},
+		{"empty", WithOriginalPath(context.Background(), ""), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := OriginalPathFrom(tt.ctx)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("OriginalPathFrom() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuth holds configuration of client certificate authentication.
+type ClientAuth struct {
+	// CAFile is the PEM-encoded CA bundle client certificates are verified against.
+	CAFile string
+	// Required rejects connections without a valid client certificate,
+	// otherwise a client certificate is verified only if given. Requests on
+	// the plain listeners are rejected as well, except /healthz and /readyz.
+	Required bool
+	// CRLFile is a PEM or DER encoded certificate revocation list, empty to disable.
+	CRLFile string
+	// UsernameFrom selects the certificate field used as username, see middleware.UsernameFromCommonName.
+	UsernameFrom string
+}
+
+// apply configures tlsConfig to verify client certificates.
+func (a *ClientAuth) apply(tlsConfig *tls.Config) error {
+	cas, err := readCertificates(a.CAFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	for _, ca := range cas {
+		pool.AddCert(ca)
+	}
+
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if a.Required {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if a.CRLFile != "" {
+		crls := &revocationLists{file: a.CRLFile, cas: cas}
+		if err := crls.load(); err != nil {
+			return err
+		}
+		tlsConfig.VerifyConnection = crls.verifyConnection
+	}
+
+	return nil
+}
+
+// revocationLists checks client certificates against the certificate
+// revocation lists in a file, reloading them when the file changes.
+// Certificates of an issuer whose list is past its next update are rejected,
+// as revocations since then are unknown.
+type revocationLists struct {
+	file string
+	cas  []*x509.Certificate
+
+	mu      sync.Mutex
+	modTime time.Time
+	lists   []*x509.RevocationList
+}
+
+// load reads the revocation lists if the file changed since the last load.
+// Every list must be signed by one of the CAs.
+func (r *revocationLists) load() error {
+	fi, err := os.Stat(r.file)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fi.ModTime().Equal(r.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.file)
+	if err != nil {
+		return err
+	}
+	var lists []*x509.RevocationList
+	for _, der := range decodePEM(data, "X509 CRL") {
+		crl, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", r.file, err)
+		}
+		if !r.signedByCA(crl) {
+			return fmt.Errorf("%s: revocation list of %s is not signed by a client CA", r.file, crl.Issuer)
+		}
+		if expired(crl, time.Now()) {
+			log.Printf("Revocation list of %s in %s expired at %s, its client certificates are rejected until it is updated\n",
+				crl.Issuer, r.file, crl.NextUpdate.Format(time.RFC3339))
+		}
+		lists = append(lists, crl)
+	}
+	r.lists, r.modTime = lists, fi.ModTime()
+
+	return nil
+}
+
+func (r *revocationLists) signedByCA(crl *x509.RevocationList) bool {
+	for _, ca := range r.cas {
+		if bytes.Equal(crl.RawIssuer, ca.RawSubject) && crl.CheckSignatureFrom(ca) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyConnection rejects connections whose client certificate chain
+// contains a revoked certificate. A file which cannot be reloaded is logged
+// and the previous lists are kept.
+func (r *revocationLists) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.VerifiedChains) == 0 {
+		return nil
+	}
+	if err := r.load(); err != nil {
+		log.Printf("Reload certificate revocation lists failed, keep the previous ones: %s\n", err.Error())
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			for _, crl := range r.lists {
+				if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+					continue
+				}
+				if expired(crl, now) {
+					return fmt.Errorf("revocation list of %s expired at %s", crl.Issuer, crl.NextUpdate.Format(time.RFC3339))
+				}
+				for _, revoked := range crl.RevokedCertificateEntries {
+					if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+						return fmt.Errorf("certificate %s (serial %s) is revoked", cert.Subject, cert.SerialNumber)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// expired reports whether the list is past its next update, if it has one.
+func expired(crl *x509.RevocationList, now time.Time) bool {
+	return !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate)
+}
+
+// readCertificates reads the PEM-encoded certificates in file.
+func readCertificates(file string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for _, der := range decodePEM(data, "CERTIFICATE") {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New(file + ": no certificates found")
+	}
+
+	return certs, nil
+}
+
+// decodePEM returns the blocks of the given type in data, or data itself if
+// it is not PEM-encoded.
+func decodePEM(data []byte, blockType string) [][]byte {
+	var blocks [][]byte
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == blockType {
+			blocks = append(blocks, block.Bytes)
+		}
+	}
+	if len(blocks) == 0 && len(bytes.TrimSpace(data)) > 0 && !bytes.Contains(data, []byte("-----BEGIN")) {
+		blocks = append(blocks, data)
+	}
+
+	return blocks
+}
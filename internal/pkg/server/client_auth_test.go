@@ -0,0 +1,191 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCRL writes a revocation list of the CA revoking the serials.
+func (ca *testCA) writeCRL(t *testing.T, name string, nextUpdate time.Time, serials ...int64) string {
+	t.Helper()
+	tmpl := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-2 * time.Hour),
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range serials {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber: big.NewInt(serial), RevocationTime: time.Now().Add(-time.Hour),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ca.writePEM(t, name, "X509 CRL", der)
+}
+
+func TestRevocationLists(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+	client, _, _ := ca.issue(t, "client", 10, false)
+	leaf, err := x509.ParseCertificate(client.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf, ca.cert}}}
+
+	tests := []struct {
+		name        string
+		crl         func() string
+		wantLoadErr bool
+		wantErr     string
+	}{
+		{name: "not revoked", crl: func() string { return ca.writeCRL(t, "a.crl", time.Now().Add(time.Hour), 11) }},
+		{name: "revoked", crl: func() string { return ca.writeCRL(t, "b.crl", time.Now().Add(time.Hour), 10) }, wantErr: "is revoked"},
+		{name: "expired", crl: func() string { return ca.writeCRL(t, "c.crl", time.Now().Add(-time.Minute)) }, wantErr: "expired"},
+		{name: "other CA", crl: func() string { return other.writeCRL(t, "d.crl", time.Now().Add(time.Hour), 10) }, wantLoadErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &revocationLists{file: tt.crl(), cas: []*x509.Certificate{ca.cert}}
+			if err := r.load(); (err != nil) != tt.wantLoadErr {
+				t.Fatalf("load() error = %v, wantErr %v", err, tt.wantLoadErr)
+			}
+			if tt.wantLoadErr {
+				return
+			}
+
+			err := r.verifyConnection(cs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyConnection() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyConnection() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevocationListsReload(t *testing.T) {
+	ca := newTestCA(t)
+	client, _, _ := ca.issue(t, "client", 10, false)
+	leaf, _ := x509.ParseCertificate(client.Certificate[0])
+	cs := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf, ca.cert}}}
+
+	file := ca.writeCRL(t, "ca.crl", time.Now().Add(time.Hour))
+	r := &revocationLists{file: file, cas: []*x509.Certificate{ca.cert}}
+	if err := r.load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.verifyConnection(cs); err != nil {
+		t.Fatalf("verifyConnection() error = %v", err)
+	}
+
+	// revoke the certificate, the file is reloaded as its modification time changed
+	data, err := os.ReadFile(ca.writeCRL(t, "new.crl", time.Now().Add(time.Hour), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.verifyConnection(cs); err == nil {
+		t.Errorf("verifyConnection() accepted a certificate revoked by the reloaded list")
+	}
+
+	// a broken file keeps the previous lists
+	if err := os.WriteFile(file, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, future.Add(time.Minute), future.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.verifyConnection(cs); err == nil {
+		t.Errorf("verifyConnection() dropped the previous lists")
+	}
+}
+
+func TestClientAuthRequired(t *testing.T) {
+	ca := newTestCA(t)
+	_, certFile, keyFile := ca.issue(t, "localhost", 2, true)
+	client, _, _ := ca.issue(t, "alice", 3, false)
+
+	s := newTestServer(t, func(c *Config) {
+		c.SecureServing = &SecureServingInfo{
+			Address:    "127.0.0.1:0",
+			CertKey:    CertKey{CertFile: certFile, KeyFile: keyFile},
+			ClientAuth: &ClientAuth{CAFile: ca.file, Required: true, UsernameFrom: "cn"},
+		}
+	})
+	s.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("username"))
+	})
+	startServer(t, s)
+	listeners := s.Listeners()
+	plain := "http://" + listeners[0].Addr().String()
+	secure := "https://" + listeners[len(listeners)-1].Addr().String()
+	mtls := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs: ca.pool(), Certificates: []tls.Certificate{client},
+	}}}
+
+	tests := []struct {
+		name     string
+		client   *http.Client
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{name: "plain api", client: http.DefaultClient, url: plain + "/whoami", wantCode: http.StatusUnauthorized},
+		{name: "plain healthz", client: http.DefaultClient, url: plain + "/healthz", wantCode: http.StatusOK},
+		{name: "plain readyz", client: http.DefaultClient, url: plain + "/readyz", wantCode: http.StatusOK},
+		{name: "mtls api", client: mtls, url: secure + "/whoami", wantCode: http.StatusOK, wantBody: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.Get(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("GET %s = %d %s, want %d", tt.url, resp.StatusCode, body, tt.wantCode)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("GET %s = %q, want %q", tt.url, body, tt.wantBody)
+			}
+			if tt.wantCode == http.StatusUnauthorized {
+				var errResp struct{ Code int }
+				if err := json.Unmarshal(body, &errResp); err != nil || errResp.Code == 0 {
+					t.Errorf("GET %s body = %s, want an error response", tt.url, body)
+				}
+			}
+		})
+	}
+
+	// without a client certificate the TLS handshake fails
+	noCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool()}}}
+	if resp, err := noCert.Get(secure + "/healthz"); err == nil {
+		resp.Body.Close()
+		t.Errorf("GET without client certificate succeeded")
+	}
+}
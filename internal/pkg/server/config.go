@@ -3,37 +3,112 @@ package server
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/openapi"
 	"golang-standards-project-example/pkg/util/homedir"
+	"golang-standards-project-example/pkg/version"
 	"log"
+	"net"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type HttpServingInfo struct {
+	// Address is the TCP address to listen on, empty to disable TCP.
 	Address string
+	// UnixSocket is the path of the unix socket to listen on, empty to disable.
+	UnixSocket string
+	// UnixSocketMode is the file mode of the unix socket, zero to keep the default.
+	UnixSocketMode os.FileMode
+	// UnixSocketOwner is the "user[:group]" owning the unix socket, empty to keep the default.
+	UnixSocketOwner string
+	// Listeners are pre-opened listeners to serve on, e.g. passed by systemd socket activation.
+	Listeners []net.Listener
+	// H2C enables HTTP/2 without TLS (h2c) next to HTTP/1.1.
+	H2C bool
+	// UsernameHeader is the header carrying the username set by the
+	// authenticating proxy in front of the listeners, empty to disable.
+	UsernameHeader string
+}
+
+// InheritedSockets are sockets opened by a previous process, e.g. handed
+// over during an upgrade, see upgrade.Inherited. The server adopts the ones
+// on its configured addresses instead of listening itself.
+type InheritedSockets interface {
+	// Listener returns the listener on the address, nil if there is none.
+	Listener(network, address string) net.Listener
+	// PacketConn returns the packet connection on the address, nil if there is none.
+	PacketConn(network, address string) net.PacketConn
+	// Listeners returns the listeners not adopted by address, they are served
+	// like HttpServingInfo.Listeners.
+	Listeners() []net.Listener
+	// Close closes the sockets not adopted.
+	Close() error
+}
+
+// SecureServingInfo holds configuration of the TLS server.
+type SecureServingInfo struct {
+	// Address is the TCP address to serve HTTPS on, HTTP/3 uses the same UDP address.
+	Address string
+	// CertKey is the certificate and private key the server presents.
+	CertKey CertKey
+	// HTTP3 enables HTTP/3 over QUIC next to HTTP/1.1 and HTTP/2.
+	HTTP3 bool
+	// ClientAuth enables client certificate authentication, nil to disable.
+	ClientAuth *ClientAuth
+}
+
+// CertKey contains configuration items related to certificate.
+type CertKey struct {
+	// CertFile is a file containing a PEM-encoded certificate, and possibly the complete certificate chain
+	CertFile string
+	// KeyFile is a file containing a PEM-encoded private key for the certificate specified by CertFile
+	KeyFile string
 }
 
 const (
 	// RecommendedHomeDir defines the default directory used to place all user service configurations.
 	RecommendedHomeDir = ".user"
-
-	// RecommendedEnvPrefix defines the ENV prefix used by all user service.
-	RecommendedEnvPrefix = "EXAMPLE"
 )
 
 type Config struct {
-	HttpServing *HttpServingInfo
-	Mode        string
-	Middlewares []string
-	Healthz     bool
+	HttpServing   *HttpServingInfo
+	SecureServing *SecureServingInfo
+	Mode          string
+	Middlewares   []string
+	Healthz       bool
+	SwaggerUI     bool
+	// Inherited are the sockets handed over by a previous process, nil if none.
+	Inherited InheritedSockets
+	// MaxBodyBytes limits the request bodies, no limit if not positive.
+	// Route groups may set their own limit with middleware.BodyLimit.
+	MaxBodyBytes int64
+	// PrefixMaxBodyBytes overrides MaxBodyBytes for the paths under a prefix,
+	// e.g. "/v2/users/import", the longest matching prefix wins.
+	PrefixMaxBodyBytes map[string]int64
+	// ContentTypes are the media types accepted for POST, PUT and PATCH bodies.
+	ContentTypes []string
+	// PrefixContentTypes are the media types accepted in addition to
+	// ContentTypes for the paths under a prefix, e.g. the stream formats of
+	// "/v2/users:import", the longest matching prefix wins.
+	PrefixContentTypes map[string][]string
+	SelfCheckTimeout   time.Duration
+	ShutdownDelay      time.Duration
+	ShutdownTimeout    time.Duration
 }
 
 // NewConfig returns a Config struct with the default values.
 func NewConfig() *Config {
 	return &Config{
-		Healthz:     true,
-		Mode:        gin.DebugMode,
-		Middlewares: []string{},
+		Healthz:          true,
+		Mode:             gin.DebugMode,
+		Middlewares:      []string{},
+		MaxBodyBytes:     1 << 20,
+		ContentTypes:     []string{core.MIMEJSON, core.MIMEYAML, core.MIMEProtobuf},
+		SelfCheckTimeout: 10 * time.Second,
+		ShutdownTimeout:  10 * time.Second,
 	}
 }
 
@@ -52,10 +127,23 @@ func (c CompletedConfig) New() (*GenericHttpServer, error) {
 	gin.SetMode(c.Mode)
 
 	s := &GenericHttpServer{
-		HttpServingInfo: c.HttpServing,
-		healthz:         c.Healthz,
-		middlewares:     c.Middlewares,
-		Engine:          gin.New(),
+		HttpServingInfo:   c.HttpServing,
+		SecureServingInfo: c.SecureServing,
+		inherited:         c.Inherited,
+		SelfCheckTimeout:  c.SelfCheckTimeout,
+		ShutdownDelay:     c.ShutdownDelay,
+		ShutdownTimeout:   c.ShutdownTimeout,
+		healthz:           c.Healthz,
+		swaggerUI:         c.SwaggerUI,
+		spec:              openapi.NewSpec(openapi.Info{Title: "API", Version: version.Get().GitVersion}),
+		middlewares:       c.Middlewares,
+		maxBodyBytes:      c.MaxBodyBytes,
+		prefixBodyBytes:   c.PrefixMaxBodyBytes,
+		contentTypes:      c.ContentTypes,
+		prefixTypes:       prefixContentTypes(c.ContentTypes, c.PrefixContentTypes),
+		inflight:          newInflightRequests(),
+		draining:          make(chan struct{}),
+		Engine:            gin.New(),
 	}
 
 	initGenericHttpServer(s)
@@ -63,8 +151,21 @@ func (c CompletedConfig) New() (*GenericHttpServer, error) {
 	return s, nil
 }
 
-// LoadConfig reads in config file and ENV variables if set.
-func LoadConfig(cfg string, defaultName string) {
+// prefixContentTypes returns the media types accepted under each prefix,
+// the extra ones following the default ones.
+func prefixContentTypes(defaults []string, extra map[string][]string) map[string][]string {
+	accepted := make(map[string][]string, len(extra))
+	for prefix, mediaTypes := range extra {
+		accepted[prefix] = append(append([]string(nil), defaults...), mediaTypes...)
+	}
+
+	return accepted
+}
+
+// LoadConfig reads in config file and ENV variables if set. ENV variables
+// start with envPrefix and follow the same naming scheme as the application
+// flags, e.g. app.EnvPrefix(defaultName), see app.EnvName.
+func LoadConfig(cfg string, defaultName string, envPrefix string) {
 	if cfg != "" {
 		viper.SetConfigFile(cfg)
 	} else {
@@ -74,9 +175,9 @@ func LoadConfig(cfg string, defaultName string) {
 	}
 
 	// Use config file from the flag.
-	viper.SetConfigType("yaml")              // set the type of the configuration to yaml.
-	viper.AutomaticEnv()                     // read in environment variables that match.
-	viper.SetEnvPrefix(RecommendedEnvPrefix) // set ENVIRONMENT variables prefix.
+	viper.SetConfigType("yaml")   // set the type of the configuration to yaml.
+	viper.AutomaticEnv()          // read in environment variables that match.
+	viper.SetEnvPrefix(envPrefix) // set ENVIRONMENT variables prefix.
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 
 	// If a config file is found, read it in.
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// Exit codes of the errors returned by GenericHttpServer.Run.
+const (
+	ExitCodeListenFailed     = 10
+	ExitCodeAddressInUse     = 11
+	ExitCodeSelfCheckTimeout = 12
+)
+
+// ListenError is returned by Run if the server cannot listen on its address.
+type ListenError struct {
+	Address string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *ListenError) Error() string {
+	return fmt.Sprintf("start http server failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ListenError) Unwrap() error {
+	return e.Err
+}
+
+// AddressInUse reports whether another process already listens on the address.
+func (e *ListenError) AddressInUse() bool {
+	return errors.Is(e.Err, syscall.EADDRINUSE)
+}
+
+// ExitCode returns the exit code reported by app.App.
+func (e *ListenError) ExitCode() int {
+	if e.AddressInUse() {
+		return ExitCodeAddressInUse
+	}
+
+	return ExitCodeListenFailed
+}
+
+// SelfCheckError is returned by Run if the server does not answer its own
+// health check in time after startup.
+type SelfCheckError struct {
+	URL string
+	Err error
+}
+
+// Error implements the error interface.
+func (e *SelfCheckError) Error() string {
+	return fmt.Sprintf("can not ping http server at %s within the specified time interval: %v", e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SelfCheckError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code reported by app.App.
+func (e *SelfCheckError) ExitCode() int {
+	return ExitCodeSelfCheckTimeout
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/middleware"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InflightRequest describes a request which is currently being served.
+type InflightRequest struct {
+	RequestID string    `json:"requestID"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	StartTime time.Time `json:"startTime"`
+}
+
+// inflightRequests tracks the requests which are currently being served.
+type inflightRequests struct {
+	mu       sync.Mutex
+	next     uint64
+	requests map[uint64]InflightRequest
+}
+
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{
+		requests: map[uint64]InflightRequest{},
+	}
+}
+
+// Middleware returns a middleware which tracks every request until its
+// handlers returned. It must be installed after middleware.RequestID.
+func (r *inflightRequests) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "<unmatched>"
+		}
+
+		r.mu.Lock()
+		id := r.next
+		r.next++
+		r.requests[id] = InflightRequest{
+			RequestID: middleware.GetRequestIDFromHeaders(c),
+			Method:    c.Request.Method,
+			Route:     route,
+			Path:      c.Request.URL.Path,
+			StartTime: time.Now(),
+		}
+		r.mu.Unlock()
+
+		defer func() {
+			r.mu.Lock()
+			delete(r.requests, id)
+			r.mu.Unlock()
+		}()
+
+		c.Next()
+	}
+}
+
+// Count returns the number of in-flight requests.
+func (r *inflightRequests) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.requests)
+}
+
+// Wait waits until no request is in flight or ctx is done.
+func (r *inflightRequests) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for r.Count() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// List returns the in-flight requests, oldest first.
+func (r *inflightRequests) List() []InflightRequest {
+	r.mu.Lock()
+	list := make([]InflightRequest, 0, len(r.requests))
+	for _, req := range r.requests {
+		list = append(list, req)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartTime.Before(list[j].StartTime)
+	})
+
+	return list
+}
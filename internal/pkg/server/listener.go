@@ -0,0 +1,159 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// listen opens the listeners configured in HttpServingInfo: the TCP address,
+// the unix socket and the pre-opened listeners, in this order. Inherited
+// listeners are adopted by address, the remaining ones are served like the
+// pre-opened listeners.
+func (s *GenericHttpServer) listen() ([]net.Listener, error) {
+	info := s.HttpServingInfo
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+	}
+
+	if info.Address != "" {
+		ln := s.inheritedListener("tcp", info.Address)
+		if ln == nil {
+			var err error
+			if ln, err = net.Listen("tcp", info.Address); err != nil {
+				return nil, &ListenError{Address: info.Address, Err: err}
+			}
+		}
+		listeners = append(listeners, ln)
+	}
+	if info.UnixSocket != "" {
+		ln := s.inheritedListener("unix", info.UnixSocket)
+		if ln == nil {
+			var err error
+			if ln, err = listenUnix(info.UnixSocket, info.UnixSocketMode, info.UnixSocketOwner); err != nil {
+				closeAll()
+				return nil, &ListenError{Address: info.UnixSocket, Err: err}
+			}
+		}
+		listeners = append(listeners, ln)
+	}
+	listeners = append(listeners, info.Listeners...)
+	if s.inherited != nil {
+		listeners = append(listeners, s.inherited.Listeners()...)
+	}
+
+	if len(listeners) == 0 {
+		return nil, &ListenError{Err: fmt.Errorf("no address, unix socket or listener configured")}
+	}
+
+	return listeners, nil
+}
+
+// inheritedListener returns the inherited listener on the address, nil if there is none.
+func (s *GenericHttpServer) inheritedListener(network, address string) net.Listener {
+	if s.inherited == nil {
+		return nil
+	}
+
+	return s.inherited.Listener(network, address)
+}
+
+// listenUnix listens on the unix socket at path, replacing a stale socket
+// file, and applies the file mode and owner if given.
+func listenUnix(path string, mode os.FileMode, owner string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+	if owner != "" {
+		uid, gid, err := lookupOwner(owner)
+		if err == nil {
+			err = os.Chown(path, uid, gid)
+		}
+		if err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket file at path if no process accepts
+// connections on it anymore. A socket in use is left alone, listening on it
+// then fails with EADDRINUSE.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return nil
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("check socket %s: %w", path, err)
+	}
+
+	return os.Remove(path)
+}
+
+// lookupOwner resolves "user[:group]", each either a name or a numeric id.
+// A missing group is returned as -1, which leaves the group unchanged.
+func lookupOwner(owner string) (int, int, error) {
+	userName, groupName, _ := strings.Cut(owner, ":")
+
+	uid, err := strconv.Atoi(userName)
+	if err != nil {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return 0, 0, err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, fmt.Errorf("user %s has non-numeric uid %s", userName, u.Uid)
+		}
+	}
+	if groupName == "" {
+		return uid, -1, nil
+	}
+
+	gid, err := strconv.Atoi(groupName)
+	if err != nil {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, fmt.Errorf("group %s has non-numeric gid %s", groupName, g.Gid)
+		}
+	}
+
+	return uid, gid, nil
+}
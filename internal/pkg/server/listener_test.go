@@ -0,0 +1,119 @@
+//go:build !windows
+
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, path string)
+		wantErr   bool
+		wantInUse bool
+	}{
+		{name: "no file"},
+		{
+			name: "stale socket",
+			setup: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// keep the socket file after closing the listener
+				ln.(*net.UnixListener).SetUnlinkOnClose(false)
+				_ = ln.Close()
+			},
+		},
+		{
+			name: "socket in use",
+			setup: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = ln.Close() })
+			},
+			wantErr:   true,
+			wantInUse: true,
+		},
+		{
+			name: "regular file",
+			setup: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "server.sock")
+			if tt.setup != nil {
+				tt.setup(t, path)
+			}
+
+			ln, err := listenUnix(path, 0o660, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listenUnix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if inUse := errors.Is(err, syscall.EADDRINUSE); inUse != tt.wantInUse {
+					t.Errorf("listenUnix() error = %v, want address in use %v", err, tt.wantInUse)
+				}
+				if _, err := os.Lstat(path); err != nil {
+					t.Errorf("listenUnix() removed %s: %v", path, err)
+				}
+				return
+			}
+			defer ln.Close()
+
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.Mode().Perm() != 0o660 {
+				t.Errorf("socket mode = %v, want 0660", fi.Mode().Perm())
+			}
+			conn, err := net.Dial("unix", path)
+			if err != nil {
+				t.Fatalf("dial %s: %v", path, err)
+			}
+			_ = conn.Close()
+		})
+	}
+}
+
+func TestLookupOwner(t *testing.T) {
+	tests := []struct {
+		owner   string
+		wantUID int
+		wantGID int
+		wantErr bool
+	}{
+		{owner: "1000", wantUID: 1000, wantGID: -1},
+		{owner: "1000:2000", wantUID: 1000, wantGID: 2000},
+		{owner: "root", wantUID: 0, wantGID: -1},
+		{owner: "0:root", wantUID: 0, wantGID: 0},
+		{owner: "no-such-user-for-test", wantErr: true},
+		{owner: "0:no-such-group-for-test", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			uid, gid, err := lookupOwner(tt.owner)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookupOwner(%q) error = %v, wantErr %v", tt.owner, err, tt.wantErr)
+			}
+			if !tt.wantErr && (uid != tt.wantUID || gid != tt.wantGID) {
+				t.Errorf("lookupOwner(%q) = %d, %d, want %d, %d", tt.owner, uid, gid, tt.wantUID, tt.wantGID)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/sync/errgroup"
+	"log"
+	"net"
+	"net/http"
+)
+
+// TLSConfig returns the TLS configuration of the secure server.
+func (s *SecureServingInfo) TLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(s.CertKey.CertFile, s.CertKey.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if s.ClientAuth != nil {
+		if err := s.ClientAuth.apply(tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// listenSecure listens on the secure TCP address and, if HTTP/3 is enabled,
+// the same UDP address, adopting inherited sockets.
+func (s *GenericHttpServer) listenSecure() (net.Listener, net.PacketConn, error) {
+	info := s.SecureServingInfo
+	ln := s.inheritedListener("tcp", info.Address)
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", info.Address); err != nil {
+			return nil, nil, &ListenError{Address: info.Address, Err: err}
+		}
+	}
+	if !info.HTTP3 {
+		return ln, nil, nil
+	}
+
+	var conn net.PacketConn
+	if s.inherited != nil {
+		conn = s.inherited.PacketConn("udp", info.Address)
+	}
+	if conn == nil {
+		var err error
+		if conn, err = net.ListenPacket("udp", info.Address); err != nil {
+			_ = ln.Close()
+			return nil, nil, &ListenError{Address: info.Address, Err: err}
+		}
+	}
+
+	return ln, conn, nil
+}
+
+// serveSecure serves HTTPS on ln, and HTTP/3 on conn if not nil, in the
+// error group.
+func (s *GenericHttpServer) serveSecure(eg *errgroup.Group, tlsConfig *tls.Config, ln net.Listener, conn net.PacketConn) {
+	var handler http.Handler = s
+	if conn != nil {
+		s.http3Server = &http3.Server{
+			Handler:   s,
+			TLSConfig: tlsConfig.Clone(),
+		}
+		handler = s.altSvcHandler()
+
+		eg.Go(func() error {
+			defer conn.Close()
+			log.Printf("Start to listening the incoming requests on http3 address: udp:%s\n", conn.LocalAddr())
+
+			if err := s.http3Server.Serve(conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			log.Printf("Server on udp:%s stopped\n", conn.LocalAddr())
+
+			return nil
+		})
+	}
+
+	s.secureServer = &http.Server{
+		Addr:      s.SecureServingInfo.Address,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	eg.Go(func() error {
+		log.Printf("Start to listening the incoming requests on https address: %s:%s\n", ln.Addr().Network(), ln.Addr())
+
+		if err := s.secureServer.ServeTLS(ln, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		log.Printf("Server on %s:%s stopped\n", ln.Addr().Network(), ln.Addr())
+
+		return nil
+	})
+}
+
+// altSvcHandler announces HTTP/3 to clients of the TLS server.
+func (s *GenericHttpServer) altSvcHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = s.http3Server.SetQUICHeaders(w.Header())
+		s.ServeHTTP(w, r)
+	})
+}
@@ -2,38 +2,79 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/quic-go/quic-go/http3"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 	"golang-standards-project-example/internal/pkg/middleware"
 	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/openapi"
 	"golang-standards-project-example/pkg/version"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/sync/errgroup"
 	"log"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type GenericHttpServer struct {
 	middlewares []string
-	// SecureServingInfo holds configuration of the TLS server.
+	// HttpServingInfo holds configuration of the plain HTTP server.
 	HttpServingInfo *HttpServingInfo
+	// SecureServingInfo holds configuration of the TLS server, nil to disable it.
+	SecureServingInfo *SecureServingInfo
 
 	// ShutdownTimeout is the timeout used for server shutdown. This specifies the timeout before server
 	// gracefully shutdown returns.
 	ShutdownTimeout time.Duration
 
+	// SelfCheckTimeout is the time Run waits for the server to answer its own
+	// /healthz after startup. Zero disables the self check.
+	SelfCheckTimeout time.Duration
+
+	// ShutdownDelay is the time between failing /readyz and closing the listeners,
+	// so load balancers stop sending traffic while it is still served.
+	ShutdownDelay time.Duration
+
 	*gin.Engine
-	healthz bool
+	healthz         bool
+	swaggerUI       bool
+	spec            *openapi.Spec
+	maxBodyBytes    int64
+	prefixBodyBytes map[string]int64
+	contentTypes    []string
+	// prefixTypes are the media types accepted under a path prefix,
+	// including contentTypes.
+	prefixTypes map[string][]string
+
+	inflight     *inflightRequests
+	shuttingDown atomic.Bool
+	// draining is closed when the server stops accepting connections.
+	draining     chan struct{}
+	drainingOnce sync.Once
+	readyHooks   []func()
+	rewrites     []func(r *http.Request) *http.Request
 
-	httpServer *http.Server
+	inherited      InheritedSockets
+	listenersMux   sync.Mutex
+	listeners      []net.Listener
+	secureListener net.Listener
+	packetConn     net.PacketConn
+	httpServer     *http.Server
+	secureServer   *http.Server
+	http3Server    *http3.Server
 }
 
 func initGenericHttpServer(s *GenericHttpServer) {
 	// do some setup
-	// s.GET(path, ginSwagger.WrapHandler(swaggerFiles.Handler))
-
 	s.Setup()
 	s.InstallMiddlewares()
 	s.InstallAPIs()
@@ -49,8 +90,24 @@ func (s *GenericHttpServer) Setup() {
 // InstallMiddlewares install generic middlewares.
 func (s *GenericHttpServer) InstallMiddlewares() {
 	// necessary middlewares
+	s.Use(middleware.OriginalPath())
 	s.Use(middleware.RequestID())
+	s.Use(middleware.Tracing())
+	if s.SecureServingInfo != nil && s.SecureServingInfo.ClientAuth != nil {
+		s.Use(middleware.ClientCert(s.SecureServingInfo.ClientAuth.UsernameFrom))
+		if s.SecureServingInfo.ClientAuth.Required {
+			// the plain listeners have no client certificates, only serve the probes there
+			s.Use(middleware.RequireClientCert("/healthz", "/readyz"))
+		}
+	}
+	if s.HttpServingInfo != nil && s.HttpServingInfo.UsernameHeader != "" {
+		s.Use(middleware.ProxyUser(s.HttpServingInfo.UsernameHeader))
+	}
 	s.Use(middleware.Context())
+	s.Use(s.inflight.Middleware())
+	s.Use(middleware.Draining(s.draining))
+	s.Use(middleware.BodyLimitFunc(s.bodyLimit))
+	s.Use(middleware.ContentTypeFunc(s.acceptedContentTypes))
 
 	// install custom middlewares
 	for _, m := range s.middlewares {
@@ -65,6 +122,33 @@ func (s *GenericHttpServer) InstallMiddlewares() {
 	}
 }
 
+// bodyLimit returns the body size limit of the request: the limit of the
+// longest prefix of its path, the default limit if none matches.
+func (s *GenericHttpServer) bodyLimit(c *gin.Context) int64 {
+	limit, longest := s.maxBodyBytes, -1
+	for prefix, n := range s.prefixBodyBytes {
+		if len(prefix) > longest && strings.HasPrefix(c.Request.URL.Path, prefix) {
+			limit, longest = n, len(prefix)
+		}
+	}
+
+	return limit
+}
+
+// acceptedContentTypes returns the media types accepted for the request
+// body: those of the longest prefix of its path, the default ones if none
+// matches.
+func (s *GenericHttpServer) acceptedContentTypes(c *gin.Context) []string {
+	accepted, longest := s.contentTypes, -1
+	for prefix, mediaTypes := range s.prefixTypes {
+		if len(prefix) > longest && strings.HasPrefix(c.Request.URL.Path, prefix) {
+			accepted, longest = mediaTypes, len(prefix)
+		}
+	}
+
+	return accepted
+}
+
 // InstallAPIs install generic apis.
 func (s *GenericHttpServer) InstallAPIs() {
 	// install healthz handler
@@ -72,68 +156,352 @@ func (s *GenericHttpServer) InstallAPIs() {
 		s.GET("/healthz", func(c *gin.Context) {
 			core.WriteResponse(c, nil, map[string]string{"status": "ok"})
 		})
+		s.spec.Describe(http.MethodGet, "/healthz", openapi.Operation{
+			Summary: "Check whether the server is alive", Tags: []string{"meta"}, Response: map[string]string{},
+		})
+		s.GET("/readyz", func(c *gin.Context) {
+			if s.shuttingDown.Load() {
+				c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+				return
+			}
+			core.WriteResponse(c, nil, map[string]string{"status": "ok"})
+		})
+		s.spec.Describe(http.MethodGet, "/readyz", openapi.Operation{
+			Summary:     "Check whether the server accepts traffic",
+			Description: "Responds with 503 Service Unavailable once the server is shutting down.",
+			Tags:        []string{"meta"}, Response: map[string]string{},
+		})
 	}
 
 	s.GET("/version", func(c *gin.Context) {
-		core.WriteResponse(c, nil, version.Get())
+		core.WriteResponse(c, nil, version.Get().Server())
 	})
+	s.spec.Describe(http.MethodGet, "/version", openapi.Operation{
+		Summary: "Get the version information of the server", Tags: []string{"meta"}, Response: version.ServerInfo{},
+	})
+
+	s.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.spec.Document(s.Routes()))
+	})
+	s.spec.Describe(http.MethodGet, "/openapi.json", openapi.Operation{Hidden: true})
+	if s.swaggerUI {
+		s.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/openapi.json")))
+		s.spec.Describe(http.MethodGet, "/swagger/*any", openapi.Operation{Hidden: true})
+	}
+}
+
+// Spec returns the OpenAPI description of the routes served at /openapi.json.
+// Describe routes when registering them.
+func (s *GenericHttpServer) Spec() *openapi.Spec {
+	return s.spec
 }
 
+// Run listens on the configured address and serves until the server is shut
+// down. It returns a *ListenError if the address cannot be listened on and a
+// *SelfCheckError if the self check is enabled and the server does not answer
+// its own /healthz in time.
 func (s *GenericHttpServer) Run() error {
 	s.httpServer = &http.Server{
 		Addr:    s.HttpServingInfo.Address,
 		Handler: s,
 	}
+	if s.HttpServingInfo.H2C {
+		h2s := &http2.Server{}
+		// let Shutdown send GOAWAY to h2c connections as well
+		if err := http2.ConfigureServer(s.httpServer, h2s); err != nil {
+			return err
+		}
+		s.httpServer.Handler = h2c.NewHandler(s, h2s)
+	}
+
+	var tlsConfig *tls.Config
+	var secureListener net.Listener
+	var packetConn net.PacketConn
+	if s.SecureServingInfo != nil {
+		var err error
+		if tlsConfig, err = s.SecureServingInfo.TLSConfig(); err != nil {
+			s.closeInherited()
+			return err
+		}
+		if secureListener, packetConn, err = s.listenSecure(); err != nil {
+			s.closeInherited()
+			return err
+		}
+	}
+	listeners, err := s.listen()
+	s.closeInherited()
+	if err != nil {
+		if secureListener != nil {
+			_ = secureListener.Close()
+		}
+		if packetConn != nil {
+			_ = packetConn.Close()
+		}
+
+		return err
+	}
+	s.listenersMux.Lock()
+	s.listeners = listeners
+	s.secureListener = secureListener
+	s.packetConn = packetConn
+	s.listenersMux.Unlock()
 	var eg errgroup.Group
 
+	if s.SecureServingInfo != nil {
+		s.serveSecure(&eg, tlsConfig, secureListener, packetConn)
+	}
+
 	// Initializing the server in a goroutine so that
 	// it won't block the graceful shutdown handling below
-	eg.Go(func() error {
-		log.Printf("Start to listening the incoming requests on http address: %s\n", s.HttpServingInfo.Address)
+	for _, ln := range listeners {
+		ln := ln
+		eg.Go(func() error {
+			log.Printf("Start to listening the incoming requests on http address: %s:%s\n", ln.Addr().Network(), ln.Addr())
+
+			if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			log.Printf("Server on %s:%s stopped\n", ln.Addr().Network(), ln.Addr())
 
-		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err.Error())
+			return nil
+		})
+	}
+
+	// Ping the server to make sure the router is working.
+	if s.healthz && s.SelfCheckTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), s.SelfCheckTimeout)
+		defer cancel()
+		if err := s.ping(ctx, listeners[0].Addr()); err != nil {
+			s.closeServers()
+			_ = eg.Wait()
 
 			return err
 		}
+	}
+	// A ready hook may let a previous process sharing the listeners shut
+	// down, which could have answered the ping, so check this process itself.
+	if s.healthz && len(s.readyHooks) > 0 {
+		if err := s.checkHealthz(); err != nil {
+			s.closeServers()
+			_ = eg.Wait()
 
-		log.Printf("Server on %s stopped\n", s.HttpServingInfo.Address)
+			return err
+		}
+	}
+	for _, hook := range s.readyHooks {
+		hook()
+	}
+
+	return eg.Wait()
+}
+
+// AddReadyHook adds a function which Run calls once the server is serving
+// and, if enabled, answered its own /healthz.
+func (s *GenericHttpServer) AddReadyHook(hook func()) {
+	s.readyHooks = append(s.readyHooks, hook)
+}
 
+// AddRewrite adds a function which rewrites the requests before they are
+// routed, e.g. to serve paths gin cannot route. A rewrite which changes the
+// path keeps the original one with request.WithOriginalPath, which the
+// middlewares and handlers see.
+func (s *GenericHttpServer) AddRewrite(rewrite func(r *http.Request) *http.Request) {
+	s.rewrites = append(s.rewrites, rewrite)
+}
+
+// ServeHTTP serves the request, rewritten by the rewrites, with the gin engine.
+func (s *GenericHttpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, rewrite := range s.rewrites {
+		r = rewrite(r)
+	}
+	s.Engine.ServeHTTP(w, r)
+}
+
+// Listeners returns the listeners the plain and TLS servers are serving
+// on, nil before Run.
+func (s *GenericHttpServer) Listeners() []net.Listener {
+	s.listenersMux.Lock()
+	defer s.listenersMux.Unlock()
+
+	listeners := append([]net.Listener(nil), s.listeners...)
+	if s.secureListener != nil {
+		listeners = append(listeners, s.secureListener)
+	}
+
+	return listeners
+}
+
+// PacketConns returns the packet connections the HTTP/3 server is serving
+// on, nil before Run or if HTTP/3 is disabled.
+func (s *GenericHttpServer) PacketConns() []net.PacketConn {
+	s.listenersMux.Lock()
+	defer s.listenersMux.Unlock()
+
+	if s.packetConn == nil {
 		return nil
-	})
+	}
 
-	// Ping the server to make sure the router is working.
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	return []net.PacketConn{s.packetConn}
+}
+
+// Close gracefully shuts down the server, see Shutdown.
+func (s *GenericHttpServer) Close() {
+	if err := s.Shutdown(context.Background()); err != nil {
+		log.Printf("Shutdown http server failed: %s\n", err.Error())
+	}
+}
+
+// Shutdown fails /readyz, keeps serving for ShutdownDelay, then stops
+// accepting connections and waits up to ShutdownTimeout for in-flight
+// requests. Requests still running at the deadline are logged and their
+// connections closed. ctx bounds the whole shutdown.
+func (s *GenericHttpServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	if s.httpServer == nil {
+		return nil
+	}
+
+	if s.ShutdownDelay > 0 {
+		log.Printf("Failing readiness, stop accepting connections in %s\n", s.ShutdownDelay)
+		select {
+		case <-time.After(s.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	drainCtx, cancel := ctx, context.CancelFunc(func() {})
+	if s.ShutdownTimeout > 0 {
+		drainCtx, cancel = context.WithTimeout(ctx, s.ShutdownTimeout)
+	}
 	defer cancel()
-	if s.healthz {
-		if err := s.ping(ctx); err != nil {
-			return err
+
+	// let streams end before waiting for in-flight requests
+	s.drainingOnce.Do(func() { close(s.draining) })
+	if n := s.inflight.Count(); n > 0 {
+		log.Printf("Draining %d in-flight request(s)\n", n)
+	}
+	err := s.shutdownServers(drainCtx)
+	if err == nil {
+		// h2c connections are hijacked, so the servers do not wait for their requests
+		err = s.inflight.Wait(drainCtx)
+	}
+	if err == nil {
+		return nil
+	}
+
+	for _, req := range s.inflight.List() {
+		log.Printf("Cut off request %s %s (route %s, request id %s) after %s\n",
+			req.Method, req.Path, req.Route, req.RequestID, time.Since(req.StartTime).Round(time.Millisecond))
+	}
+	s.closeServers()
+
+	return err
+}
+
+// shutdownServers gracefully shuts down the plain, TLS and HTTP/3 servers concurrently.
+func (s *GenericHttpServer) shutdownServers(ctx context.Context) error {
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return s.httpServer.Shutdown(ctx)
+	})
+	if s.secureServer != nil {
+		eg.Go(func() error {
+			return s.secureServer.Shutdown(ctx)
+		})
+	}
+	if s.http3Server != nil {
+		eg.Go(func() error {
+			return s.http3Server.Shutdown(ctx)
+		})
+	}
+
+	return eg.Wait()
+}
+
+// closeServers closes the plain, TLS and HTTP/3 servers and all their connections.
+func (s *GenericHttpServer) closeServers() {
+	if err := s.httpServer.Close(); err != nil {
+		log.Printf("Close http server failed: %s\n", err.Error())
+	}
+	if s.secureServer != nil {
+		if err := s.secureServer.Close(); err != nil {
+			log.Printf("Close https server failed: %s\n", err.Error())
+		}
+	}
+	if s.http3Server != nil {
+		if err := s.http3Server.Close(); err != nil {
+			log.Printf("Close http3 server failed: %s\n", err.Error())
 		}
 	}
+}
+
+// InflightRequests returns the requests which are currently being served, oldest first.
+func (s *GenericHttpServer) InflightRequests() []InflightRequest {
+	return s.inflight.List()
+}
+
+// closeInherited closes the inherited sockets which are not configured anymore.
+func (s *GenericHttpServer) closeInherited() {
+	if s.inherited == nil {
+		return
+	}
+	if err := s.inherited.Close(); err != nil {
+		log.Printf("Close inherited sockets failed: %s\n", err.Error())
+	}
+}
 
-	if err := eg.Wait(); err != nil {
-		log.Fatal(err.Error())
+// checkHealthz passes a /healthz request to the router of this process.
+func (s *GenericHttpServer) checkHealthz() error {
+	req, err := http.NewRequest(http.MethodGet, "/healthz", http.NoBody)
+	if err != nil {
+		return &SelfCheckError{URL: "/healthz", Err: err}
+	}
+	w := &statusWriter{header: http.Header{}}
+	s.ServeHTTP(w, req)
+	if w.status != http.StatusOK {
+		return &SelfCheckError{URL: "/healthz", Err: fmt.Errorf("status %d", w.status)}
 	}
 
 	return nil
 }
 
-func (s *GenericHttpServer) Close() {
-	// The context is used to inform the server it has 10 seconds to finish
-	// the request it is currently handling
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// statusWriter is an http.ResponseWriter which keeps the status code only.
+type statusWriter struct {
+	header http.Header
+	status int
+}
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		log.Printf("Shutdown http server failed: %s\n", err.Error())
+func (w *statusWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return len(p), nil
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
 	}
 }
 
 // ping pings the http server to make sure the router is working.
-func (s *GenericHttpServer) ping(ctx context.Context) error {
-	url := fmt.Sprintf("http://%s/healthz", s.HttpServingInfo.Address)
-	if strings.Contains(s.HttpServingInfo.Address, "0.0.0.0") {
-		url = fmt.Sprintf("http://127.0.0.1:%s/healthz", strings.Split(s.HttpServingInfo.Address, ":")[1])
+func (s *GenericHttpServer) ping(ctx context.Context, addr net.Addr) error {
+	url := fmt.Sprintf("http://%s/healthz", pingAddress(addr))
+	client := http.DefaultClient
+	if addr.Network() == "unix" {
+		url = "http://unix/healthz"
+		client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", addr.String())
+			},
+		}}
 	}
 
 	for {
@@ -144,24 +512,39 @@ func (s *GenericHttpServer) ping(ctx context.Context) error {
 		}
 		// Ping the server by sending a GET request to `/healthz`.
 
-		resp, err := http.DefaultClient.Do(req)
-		if err == nil && resp.StatusCode == http.StatusOK {
-			log.Printf("The router has been deployed successfully.\n")
-
+		resp, err := client.Do(req)
+		if err == nil {
 			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				log.Printf("The router has been deployed successfully.\n")
 
-			return nil
+				return nil
+			}
 		}
 
 		// Sleep for a second to continue the next ping.
 		log.Printf("Waiting for the router, retry in 1 second.\n")
-		time.Sleep(1 * time.Second)
-
 		select {
 		case <-ctx.Done():
-			log.Fatal("can not ping http server within the specified time interval.")
-		default:
+			return &SelfCheckError{URL: url, Err: ctx.Err()}
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
+
+// pingAddress returns the address the server can be reached on locally,
+// replacing unspecified hosts such as "", "0.0.0.0" and "::" by loopback.
+func pingAddress(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+		if ip != nil && ip.To4() == nil {
+			host = "::1"
 		}
 	}
-	// return fmt.Errorf("the router has no response, or it might took too long to start up")
+
+	return net.JoinHostPort(host, port)
 }
@@ -0,0 +1,440 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/request"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+	file string
+}
+
+// newTestCA creates a CA and writes its certificate to a temporary file.
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	ca.file = ca.writePEM(t, "ca.pem", "CERTIFICATE", der)
+
+	return ca
+}
+
+// pool returns a certificate pool containing the CA.
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	return pool
+}
+
+// issue issues a certificate for the common name, a server certificate for
+// localhost if server is set, and writes it and its key to temporary files.
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64, server bool) (tls.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{"localhost"}
+		tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := ca.writePEM(t, commonName+".pem", "CERTIFICATE", der)
+	keyFile := ca.writePEM(t, commonName+"-key.pem", "EC PRIVATE KEY", keyDER)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, certFile, keyFile
+}
+
+func (ca *testCA) writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	file := filepath.Join(ca.dir, name)
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// newTestServer returns a server listening on a random local port without
+// self check, modified by configure.
+func newTestServer(t *testing.T, configure func(c *Config)) *GenericHttpServer {
+	t.Helper()
+	c := NewConfig()
+	c.Mode = gin.TestMode
+	c.HttpServing = &HttpServingInfo{Address: "127.0.0.1:0"}
+	c.SelfCheckTimeout = 0
+	c.ShutdownTimeout = time.Second
+	if configure != nil {
+		configure(c)
+	}
+	s, err := c.Complete().New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// startServer runs the server until the test ends and returns once it is ready.
+func startServer(t *testing.T, s *GenericHttpServer) {
+	t.Helper()
+	ready := make(chan struct{})
+	s.AddReadyHook(func() { close(ready) })
+	done := make(chan error, 1)
+	go func() { done <- s.Run() }()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("Run() = %v before the server was ready", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown() = %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+	})
+}
+
+// get requests the url with the client and returns the status code and protocol.
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, resp.Proto
+}
+
+// fakeInherited are sockets handed over by a previous process.
+type fakeInherited struct {
+	listeners   map[string]net.Listener
+	packetConns map[string]net.PacketConn
+	closed      bool
+}
+
+func (f *fakeInherited) Listener(network, address string) net.Listener {
+	ln := f.listeners[network+":"+address]
+	delete(f.listeners, network+":"+address)
+
+	return ln
+}
+
+func (f *fakeInherited) PacketConn(network, address string) net.PacketConn {
+	conn := f.packetConns[network+":"+address]
+	delete(f.packetConns, network+":"+address)
+
+	return conn
+}
+
+func (f *fakeInherited) Listeners() []net.Listener {
+	var listeners []net.Listener
+	for key, ln := range f.listeners {
+		listeners = append(listeners, ln)
+		delete(f.listeners, key)
+	}
+
+	return listeners
+}
+
+func (f *fakeInherited) Close() error {
+	f.closed = true
+
+	return nil
+}
+
+func TestRunAdoptsInheritedSockets(t *testing.T) {
+	ca := newTestCA(t)
+	_, certFile, keyFile := ca.issue(t, "localhost", 2, true)
+
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secure, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	udp, err := net.ListenPacket("udp", secure.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inherited := &fakeInherited{
+		listeners: map[string]net.Listener{
+			"tcp:" + plain.Addr().String():  plain,
+			"tcp:" + secure.Addr().String(): secure,
+			"extra":                         extra,
+		},
+		packetConns: map[string]net.PacketConn{"udp:" + udp.LocalAddr().String(): udp},
+	}
+
+	s := newTestServer(t, func(c *Config) {
+		c.HttpServing.Address = plain.Addr().String()
+		c.SecureServing = &SecureServingInfo{
+			Address: secure.Addr().String(),
+			CertKey: CertKey{CertFile: certFile, KeyFile: keyFile},
+			HTTP3:   true,
+		}
+		c.Inherited = inherited
+	})
+	startServer(t, s)
+
+	if !inherited.closed {
+		t.Errorf("Run() did not close the sockets it did not adopt")
+	}
+	var addrs []string
+	for _, ln := range s.Listeners() {
+		addrs = append(addrs, ln.Addr().String())
+	}
+	want := []string{plain.Addr().String(), extra.Addr().String(), secure.Addr().String()}
+	if len(addrs) != len(want) {
+		t.Fatalf("Listeners() = %v, want %v", addrs, want)
+	}
+	for i := range want {
+		if addrs[i] != want[i] {
+			t.Fatalf("Listeners() = %v, want %v", addrs, want)
+		}
+	}
+	if conns := s.PacketConns(); len(conns) != 1 || conns[0] != udp {
+		t.Errorf("PacketConns() = %v, want the inherited connection", conns)
+	}
+
+	for _, url := range []string{plain.Addr().String(), extra.Addr().String()} {
+		if code, _ := get(t, http.DefaultClient, "http://"+url+"/healthz"); code != http.StatusOK {
+			t.Errorf("GET http://%s/healthz = %d", url, code)
+		}
+	}
+	tlsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool()}}}
+	if code, _ := get(t, tlsClient, "https://"+secure.Addr().String()+"/healthz"); code != http.StatusOK {
+		t.Errorf("GET https://%s/healthz = %d", secure.Addr(), code)
+	}
+}
+
+func TestRunReadyHooksAfterHealthCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		healthz   bool
+		selfCheck time.Duration
+	}{
+		{name: "no self check", healthz: true},
+		{name: "self check", healthz: true, selfCheck: time.Second},
+		{name: "no healthz", healthz: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, func(c *Config) {
+				c.Healthz = tt.healthz
+				c.SelfCheckTimeout = tt.selfCheck
+			})
+			var calls int
+			s.AddReadyHook(func() { calls++ })
+			startServer(t, s)
+
+			if calls != 1 {
+				t.Errorf("ready hook called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestVersionHidesBuildDetails(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	for _, path := range []string{"/version", "/openapi.json"} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", path, w.Code)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "gitVersion") {
+			t.Errorf("GET %s = %s, want the version fields", path, body)
+		}
+		for _, field := range []string{`"deps"`, `"settings"`} {
+			if strings.Contains(body, field) {
+				t.Errorf("GET %s exposes %s", path, field)
+			}
+		}
+	}
+}
+
+func TestCheckHealthz(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		wantErr bool
+	}{
+		{name: "ok", handler: func(c *gin.Context) { c.String(http.StatusOK, "ok") }},
+		{name: "status only", handler: func(c *gin.Context) { c.Status(http.StatusOK) }},
+		{name: "unhealthy", handler: func(c *gin.Context) { c.String(http.StatusInternalServerError, "down") }, wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, func(c *Config) { c.Healthz = false })
+			if tt.handler != nil {
+				s.GET("/healthz", tt.handler)
+			}
+
+			err := s.checkHealthz()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkHealthz() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddRewrite(t *testing.T) {
+	s := newTestServer(t, func(c *Config) { c.PrefixMaxBodyBytes = map[string]int64{"/original": 1} })
+	s.POST("/rewritten", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.String(http.StatusRequestEntityTooLarge, c.Request.URL.Path)
+			return
+		}
+		c.String(http.StatusOK, c.Request.URL.Path)
+	})
+	s.AddRewrite(func(r *http.Request) *http.Request {
+		if r.URL.Path != "/original" {
+			return r
+		}
+		r = r.WithContext(request.WithOriginalPath(r.Context(), r.URL.Path))
+		r.URL.Path = "/rewritten"
+		return r
+	})
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{path: "/original", status: http.StatusOK, want: "/original"},
+		{path: "/original", body: "too large", status: http.StatusRequestEntityTooLarge, want: "/original"},
+		{path: "/rewritten", body: "{}", status: http.StatusOK, want: "/rewritten"},
+		{path: "/other", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		s.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("POST %s = %d, want %d", tt.path, w.Code, tt.status)
+		}
+		if tt.want != "" && w.Body.String() != tt.want {
+			t.Errorf("POST %s served with path %q, want %q", tt.path, w.Body, tt.want)
+		}
+	}
+}
+
+func TestPrefixContentTypes(t *testing.T) {
+	s := newTestServer(t, func(c *Config) {
+		c.PrefixContentTypes = map[string][]string{
+			"/v1/imports": {"application/x-ndjson", "text/csv"},
+			"/v1/imp":     {"text/plain"},
+		}
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	s.POST("/v1/items", ok)
+	s.POST("/v1/imports", ok)
+
+	tests := []struct {
+		path        string
+		contentType string
+		status      int
+	}{
+		{path: "/v1/items", contentType: "application/json", status: http.StatusNoContent},
+		{path: "/v1/items", contentType: "application/x-ndjson", status: http.StatusUnsupportedMediaType},
+		{path: "/v1/items", contentType: "text/csv", status: http.StatusUnsupportedMediaType},
+		{path: "/v1/imports", contentType: "application/x-ndjson", status: http.StatusNoContent},
+		{path: "/v1/imports", contentType: "text/csv; charset=utf-8", status: http.StatusNoContent},
+		{path: "/v1/imports", contentType: "application/json", status: http.StatusNoContent},
+		{path: "/v1/imports", contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", tt.contentType)
+		s.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("POST %s with %s = %d, want %d", tt.path, tt.contentType, w.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// h2cClient speaks HTTP/2 without TLS.
+var h2cClient = &http.Client{Transport: &http2.Transport{
+	AllowHTTP: true,
+	DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	},
+}}
+
+func TestH2C(t *testing.T) {
+	tests := []struct {
+		name      string
+		h2c       bool
+		client    *http.Client
+		wantProto string
+		wantErr   bool
+	}{
+		{name: "http/1.1", client: http.DefaultClient, wantProto: "HTTP/1.1"},
+		{name: "h2c enabled, http/1.1 client", h2c: true, client: http.DefaultClient, wantProto: "HTTP/1.1"},
+		{name: "h2c enabled", h2c: true, client: h2cClient, wantProto: "HTTP/2.0"},
+		{name: "h2c disabled", client: h2cClient, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, func(c *Config) {
+				c.HttpServing.H2C = tt.h2c
+			})
+			startServer(t, s)
+
+			url := "http://" + s.Listeners()[0].Addr().String() + "/healthz"
+			resp, err := tt.client.Get(url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GET %s error = %v, wantErr %v", url, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.Proto != tt.wantProto {
+				t.Errorf("GET %s = %d %s, want 200 %s", url, resp.StatusCode, resp.Proto, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestHTTP3(t *testing.T) {
+	ca := newTestCA(t)
+	_, certFile, keyFile := ca.issue(t, "localhost", 2, true)
+	tests := []struct {
+		name       string
+		http3      bool
+		wantAltSvc bool
+	}{
+		{name: "enabled", http3: true, wantAltSvc: true},
+		{name: "disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, func(c *Config) {
+				c.SecureServing = &SecureServingInfo{
+					Address: "127.0.0.1:0",
+					CertKey: CertKey{CertFile: certFile, KeyFile: keyFile},
+					HTTP3:   tt.http3,
+				}
+			})
+			startServer(t, s)
+			listeners := s.Listeners()
+			addr := listeners[len(listeners)-1].Addr().String()
+
+			tlsConfig := &tls.Config{RootCAs: ca.pool()}
+			tlsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, ForceAttemptHTTP2: true}}
+			resp, err := tlsClient.Get("https://" + addr + "/healthz")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			altSvc := resp.Header.Get("Alt-Svc")
+			if resp.Proto != "HTTP/2.0" || strings.Contains(altSvc, "h3") != tt.wantAltSvc {
+				t.Errorf("GET over TLS = %s with Alt-Svc %q, want HTTP/2.0 announcing h3 %v", resp.Proto, altSvc, tt.wantAltSvc)
+			}
+			if !tt.http3 {
+				if conns := s.PacketConns(); len(conns) != 0 {
+					t.Errorf("PacketConns() = %v, want none", conns)
+				}
+				return
+			}
+
+			// HTTP/3 is served on the UDP port of the TLS address
+			rt := &http3.RoundTripper{TLSClientConfig: tlsConfig}
+			defer rt.Close()
+			code, proto := get(t, &http.Client{Transport: rt}, "https://"+s.PacketConns()[0].LocalAddr().String()+"/healthz")
+			if code != http.StatusOK || proto != "HTTP/3.0" {
+				t.Errorf("GET over QUIC = %d %s, want 200 HTTP/3.0", code, proto)
+			}
+		})
+	}
+}
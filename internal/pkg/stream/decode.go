@@ -0,0 +1,206 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// maxLineSize is the maximal size of an NDJSON line.
+const maxLineSize = 1 << 20
+
+// Decoder reads a stream of objects.
+type Decoder interface {
+	// Decode decodes the next object into obj. It returns io.EOF at the end
+	// of the stream and a *RowError if only this object is malformed.
+	Decode(obj interface{}) error
+	// Row returns the number of the last decoded line or record, starting at 1.
+	Row() int
+}
+
+// NewDecoder returns a decoder reading the format from r.
+func NewDecoder(format string, r io.Reader) Decoder {
+	if format == MIMECSV {
+		return &csvDecoder{r: csv.NewReader(r)}
+	}
+
+	er := &errReader{r: r}
+	s := bufio.NewScanner(er)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		// a line cut off by a read error is not a row
+		if atEOF && er.err != nil && len(data) > 0 && !bytes.Contains(data, []byte{'\n'}) {
+			return 0, nil, er.err
+		}
+		return bufio.ScanLines(data, atEOF)
+	})
+
+	return &ndjsonDecoder{s: s}
+}
+
+// errReader records the error reading r other than io.EOF.
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+type ndjsonDecoder struct {
+	s   *bufio.Scanner
+	row int
+}
+
+func (d *ndjsonDecoder) Decode(obj interface{}) error {
+	for d.s.Scan() {
+		d.row++
+		line := bytes.TrimSpace(d.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, obj); err != nil {
+			return &RowError{Row: d.row, Err: err}
+		}
+		return nil
+	}
+	if err := d.s.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+func (d *ndjsonDecoder) Row() int {
+	return d.row
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns [][]string
+	row     int
+}
+
+func (d *csvDecoder) Decode(obj interface{}) error {
+	if d.columns == nil {
+		header, err := d.r.Read()
+		if err != nil {
+			return err
+		}
+		for _, column := range header {
+			d.columns = append(d.columns, strings.Split(strings.TrimSpace(column), "."))
+		}
+		d.r.FieldsPerRecord = len(header)
+	}
+
+	record, err := d.r.Read()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok {
+			d.row++
+			return &RowError{Row: d.row, Err: err}
+		}
+		return err
+	}
+	d.row++
+
+	t := reflect.TypeOf(obj)
+	generic := map[string]interface{}{}
+	for i, path := range d.columns {
+		if record[i] == "" {
+			continue
+		}
+		var value interface{} = record[i]
+		if kind := kindAt(t, path); kind != reflect.String && kind != reflect.Invalid {
+			if err := json.Unmarshal([]byte(record[i]), &value); err != nil {
+				return &RowError{Row: d.row, Err: fmt.Errorf("column %s: %w", strings.Join(path, "."), err)}
+			}
+		}
+		set(generic, path, value)
+	}
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		return &RowError{Row: d.row, Err: err}
+	}
+
+	return nil
+}
+
+func (d *csvDecoder) Row() int {
+	return d.row
+}
+
+// kindAt returns the kind of the field at the dotted JSON path in the type
+// t, reflect.Invalid if there is none.
+func kindAt(t reflect.Type, path []string) reflect.Kind {
+	for _, name := range path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := fieldByJSONName(t, name)
+			if !ok {
+				return reflect.Invalid
+			}
+			t = f.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return reflect.Invalid
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind()
+}
+
+// fieldByJSONName returns the field of the struct type t encoded with the
+// name, looking into embedded structs like encoding/json does.
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(tag, ",")
+		if jsonName == "" && f.Anonymous {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if embedded, ok := fieldByJSONName(ft, name); ok {
+					return embedded, true
+				}
+				continue
+			}
+		}
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		if strings.EqualFold(jsonName, name) {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
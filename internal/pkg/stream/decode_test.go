@@ -0,0 +1,273 @@
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type testRow struct {
+	Metadata testMeta `json:"metadata"`
+	Nickname string   `json:"nickname"`
+	Age      int      `json:"age,omitempty"`
+	Admin    bool     `json:"admin,omitempty"`
+	Phone    *string  `json:"phone,omitempty"`
+}
+
+type testEmbedded struct {
+	testMeta
+	Count int `json:"count"`
+}
+
+// decodeAll decodes the stream and returns the rows and the errors by row,
+// up to the first error which is not a *RowError.
+func decodeAll(format, input string) ([]testRow, map[int]string, error) {
+	dec := NewDecoder(format, strings.NewReader(input))
+	var rows []testRow
+	rowErrs := map[int]string{}
+	for {
+		var row testRow
+		err := dec.Decode(&row)
+		if err == io.EOF {
+			return rows, rowErrs, nil
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			if rowErr.Row != dec.Row() {
+				return rows, rowErrs, errors.New("row of the error is not the row of the decoder")
+			}
+			rowErrs[rowErr.Row] = rowErr.Err.Error()
+			continue
+		}
+		if err != nil {
+			return rows, rowErrs, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     []testRow
+		wantErrs []int
+	}{
+		{
+			name:  "rows",
+			input: "{\"metadata\":{\"name\":\"a\"},\"age\":1}\n{\"metadata\":{\"name\":\"b\"}}\n",
+			want:  []testRow{{Metadata: testMeta{Name: "a"}, Age: 1}, {Metadata: testMeta{Name: "b"}}},
+		},
+		{
+			name:  "no trailing newline and CRLF",
+			input: "{\"nickname\":\"a\"}\r\n{\"nickname\":\"b\"}",
+			want:  []testRow{{Nickname: "a"}, {Nickname: "b"}},
+		},
+		{
+			name:  "blank lines are skipped but counted",
+			input: "\n  \n{\"nickname\":\"a\"}\n\n",
+			want:  []testRow{{Nickname: "a"}},
+		},
+		{
+			name:     "malformed lines are reported by row",
+			input:    "{\"nickname\":\"a\"}\n{oops\n{\"age\":\"x\"}\n{\"nickname\":\"d\"}\n",
+			want:     []testRow{{Nickname: "a"}, {Nickname: "d"}},
+			wantErrs: []int{2, 3},
+		},
+		{name: "empty", input: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrs, err := decodeAll(MIMENDJSON, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %+v, want %+v", rows, tt.want)
+			}
+			if got := errRows(rowErrs); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("rows with errors = %v (%v), want %v", got, rowErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestNDJSONDecoderRow(t *testing.T) {
+	dec := NewDecoder(MIMENDJSON, strings.NewReader("\n{}\n\n{}\n"))
+	for _, want := range []int{2, 4} {
+		if err := dec.Decode(&testRow{}); err != nil {
+			t.Fatal(err)
+		}
+		if dec.Row() != want {
+			t.Errorf("Row() = %d, want %d", dec.Row(), want)
+		}
+	}
+}
+
+func TestNDJSONDecoderStreamErrors(t *testing.T) {
+	readErr := errors.New("connection reset")
+	tests := []struct {
+		name  string
+		input io.Reader
+		want  error
+	}{
+		{
+			name:  "line cut off by a read error",
+			input: io.MultiReader(strings.NewReader("{}\n{\"nick"), &failingReader{readErr}),
+			want:  readErr,
+		},
+		{
+			name:  "line too long",
+			input: strings.NewReader("{}\n\"" + strings.Repeat("x", maxLineSize) + "\"\n"),
+			want:  bufio.ErrTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(MIMENDJSON, tt.input)
+			if err := dec.Decode(&testRow{}); err != nil {
+				t.Fatalf("first row: %v", err)
+			}
+			err := dec.Decode(&testRow{})
+			var rowErr *RowError
+			if errors.As(err, &rowErr) || !errors.Is(err, tt.want) {
+				t.Errorf("Decode() = %v, want the stream error %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// failingReader fails every read with err.
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestCSVDecoder(t *testing.T) {
+	phone := "555"
+	tests := []struct {
+		name     string
+		input    string
+		want     []testRow
+		wantErrs []int
+	}{
+		{
+			name:  "header maps columns to JSON paths",
+			input: "metadata.name, nickname ,age,admin,phone,metadata.labels.team\na,A,30,true,555,x\n",
+			want: []testRow{{
+				Metadata: testMeta{Name: "a", Labels: map[string]string{"team": "x"}},
+				Nickname: "A", Age: 30, Admin: true, Phone: &phone,
+			}},
+		},
+		{
+			name:  "columns in any order, empty cells are unset",
+			input: "age,metadata.name\n,b\n7,\n",
+			want:  []testRow{{Metadata: testMeta{Name: "b"}}, {Age: 7}},
+		},
+		{
+			name:  "header names are matched case insensitively",
+			input: "Metadata.Name,NICKNAME\nc,C\n",
+			want:  []testRow{{Metadata: testMeta{Name: "c"}, Nickname: "C"}},
+		},
+		{
+			name:  "unknown columns are ignored",
+			input: "nickname,extra.column\nd,42\n",
+			want:  []testRow{{Nickname: "d"}},
+		},
+		{
+			name:  "strings are not parsed as JSON",
+			input: "nickname\n123\n",
+			want:  []testRow{{Nickname: "123"}},
+		},
+		{
+			name:     "invalid values are reported by row",
+			input:    "nickname,age\na,1\nb,x\nc,\"1.5\"\nd,4\n",
+			want:     []testRow{{Nickname: "a", Age: 1}, {Nickname: "d", Age: 4}},
+			wantErrs: []int{2, 3},
+		},
+		{
+			name:     "records with the wrong number of fields are reported by row",
+			input:    "nickname,age\na,1\nb\nc,3,x\nd,4\n",
+			want:     []testRow{{Nickname: "a", Age: 1}, {Nickname: "d", Age: 4}},
+			wantErrs: []int{2, 3},
+		},
+		{name: "header only", input: "nickname,age\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrs, err := decodeAll(MIMECSV, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %+v, want %+v", rows, tt.want)
+			}
+			if got := errRows(rowErrs); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("rows with errors = %v (%v), want %v", got, rowErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestCSVDecoderColumnError(t *testing.T) {
+	dec := NewDecoder(MIMECSV, strings.NewReader("metadata.name,age\na,old\n"))
+	err := dec.Decode(&testRow{})
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 1 || !strings.Contains(err.Error(), "column age") {
+		t.Errorf("Decode() = %v, want a row 1 error naming the column", err)
+	}
+}
+
+func TestCSVDecoderEmpty(t *testing.T) {
+	if err := NewDecoder(MIMECSV, strings.NewReader("")).Decode(&testRow{}); err != io.EOF {
+		t.Errorf("Decode() = %v, want io.EOF", err)
+	}
+}
+
+func TestKindAt(t *testing.T) {
+	tests := []struct {
+		t    reflect.Type
+		path string
+		want reflect.Kind
+	}{
+		{reflect.TypeOf(&testRow{}), "metadata.name", reflect.String},
+		{reflect.TypeOf(&testRow{}), "age", reflect.Int},
+		{reflect.TypeOf(&testRow{}), "phone", reflect.String},
+		{reflect.TypeOf(&testRow{}), "metadata.labels.team", reflect.String},
+		{reflect.TypeOf(&testRow{}), "metadata", reflect.Struct},
+		{reflect.TypeOf(&testRow{}), "missing", reflect.Invalid},
+		{reflect.TypeOf(&testRow{}), "age.value", reflect.Invalid},
+		{reflect.TypeOf(&testEmbedded{}), "name", reflect.String},
+		{reflect.TypeOf(&testEmbedded{}), "count", reflect.Int},
+	}
+	for _, tt := range tests {
+		t.Run(tt.t.String()+" "+tt.path, func(t *testing.T) {
+			if got := kindAt(tt.t, strings.Split(tt.path, ".")); got != tt.want {
+				t.Errorf("kindAt(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// errRows returns the rows with errors in order, nil if there are none.
+func errRows(rowErrs map[int]string) []int {
+	var rows []int
+	for row := 1; len(rows) < len(rowErrs); row++ {
+		if _, ok := rowErrs[row]; ok {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,159 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Encoder writes a stream of objects.
+type Encoder interface {
+	// Encode writes the object.
+	Encode(obj interface{}) error
+	// Flush writes buffered data to the underlying writer.
+	Flush() error
+}
+
+// NewEncoder returns an encoder writing the format to w. CSV writes the
+// columns, NDJSON the fields in columns or, if empty, all fields.
+func NewEncoder(format string, w io.Writer, columns []string) Encoder {
+	if format == MIMECSV {
+		return &csvEncoder{w: csv.NewWriter(w), columns: columns}
+	}
+
+	return &ndjsonEncoder{w: bufio.NewWriter(w), fields: columns}
+}
+
+type ndjsonEncoder struct {
+	w      *bufio.Writer
+	fields []string
+}
+
+func (e *ndjsonEncoder) Encode(obj interface{}) error {
+	if len(e.fields) > 0 {
+		generic, err := toGeneric(obj)
+		if err != nil {
+			return err
+		}
+		obj = selectFields(generic, e.fields)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte('\n')
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+type csvEncoder struct {
+	w       *csv.Writer
+	columns []string
+	header  bool
+}
+
+func (e *csvEncoder) Encode(obj interface{}) error {
+	if !e.header {
+		if err := e.w.Write(e.columns); err != nil {
+			return err
+		}
+		e.header = true
+	}
+
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		if record[i], err = cell(lookup(generic, strings.Split(column, "."))); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+// cell returns the CSV cell of the JSON value: strings as they are, nothing
+// for null and JSON for the other values.
+func cell(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+
+	return string(data), err
+}
+
+// lookup returns the value at the path in the JSON value, nil if there is none.
+func lookup(v interface{}, path []string) interface{} {
+	for _, name := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+
+	return v
+}
+
+// selectFields returns the JSON object restricted to the fields, see lookup.
+func selectFields(v interface{}, fields []string) interface{} {
+	selected := map[string]interface{}{}
+	for _, f := range fields {
+		path := strings.Split(f, ".")
+		value := lookup(v, path)
+		if value == nil {
+			continue
+		}
+		set(selected, path, value)
+	}
+
+	return selected
+}
+
+// set sets the value at the path, creating the objects on the path.
+func set(m map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		next, ok := m[name].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[name] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// toGeneric returns the JSON form of obj, keeping numbers as they are.
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	err = dec.Decode(&generic)
+
+	return generic, err
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	phone := "555"
+	rows := []interface{}{
+		&testRow{
+			Metadata: testMeta{Name: "a", Labels: map[string]string{"team": "x"}},
+			Nickname: "A, \"the first\"", Age: 30, Admin: true, Phone: &phone,
+		},
+		testRow{Metadata: testMeta{Name: "b"}, Age: 12345678901234},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		columns []string
+		want    string
+	}{
+		{
+			name:   "ndjson",
+			format: MIMENDJSON,
+			want: `{"metadata":{"name":"a","labels":{"team":"x"}},"nickname":"A, \"the first\"","age":30,"admin":true,"phone":"555"}` + "\n" +
+				`{"metadata":{"name":"b"},"nickname":"","age":12345678901234}` + "\n",
+		},
+		{
+			name:    "ndjson fields",
+			format:  MIMENDJSON,
+			columns: []string{"metadata.name", "age", "phone", "missing"},
+			want: `{"age":30,"metadata":{"name":"a"},"phone":"555"}` + "\n" +
+				`{"age":12345678901234,"metadata":{"name":"b"}}` + "\n",
+		},
+		{
+			name:    "csv",
+			format:  MIMECSV,
+			columns: []string{"metadata.name", "nickname", "age", "admin", "phone", "metadata.labels"},
+			want: "metadata.name,nickname,age,admin,phone,metadata.labels\n" +
+				"a,\"A, \"\"the first\"\"\",30,true,555,\"{\"\"team\"\":\"\"x\"\"}\"\n" +
+				"b,,12345678901234,,,\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(tt.format, &buf, tt.columns)
+			for _, row := range rows {
+				if err := enc.Encode(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := enc.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("encoded\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	phone := "555"
+	want := testRow{
+		Metadata: testMeta{Name: "a", Labels: map[string]string{"team": "x"}},
+		Nickname: "A, \"quoted\"\nsecond line", Age: 30, Admin: true, Phone: &phone,
+	}
+	columns := []string{"metadata.name", "metadata.labels.team", "nickname", "age", "admin", "phone"}
+
+	for _, format := range []string{MIMENDJSON, MIMECSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(format, &buf, columns)
+			if err := enc.Encode(want); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got testRow
+			if err := NewDecoder(format, &buf).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Nickname != want.Nickname || got.Age != want.Age || !got.Admin ||
+				got.Phone == nil || *got.Phone != phone || got.Metadata.Labels["team"] != "x" {
+				t.Errorf("decoded %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEncoderFlushError(t *testing.T) {
+	for _, format := range []string{MIMENDJSON, MIMECSV} {
+		t.Run(format, func(t *testing.T) {
+			enc := NewEncoder(format, failingWriter{}, []string{"nickname"})
+			if err := enc.Encode(testRow{Nickname: strings.Repeat("x", 10)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.Flush(); !errors.Is(err, errWrite) {
+				t.Errorf("Flush() = %v, want %v", err, errWrite)
+			}
+		})
+	}
+}
+
+var errWrite = errors.New("broken pipe")
+
+// failingWriter fails every write with errWrite.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestCell(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"null", nil, ""},
+		{"string", "a,b", "a,b"},
+		{"bool", true, "true"},
+		{"number", 1.5, "1.5"},
+		{"object", map[string]interface{}{"a": 1}, `{"a":1}`},
+		{"array", []interface{}{"a"}, `["a"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cell(tt.v)
+			if err != nil || got != tt.want {
+				t.Errorf("cell(%v) = %q, %v, want %q", tt.v, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Package stream encodes and decodes streams of objects as NDJSON or CSV,
+// one object per line or record, without holding the stream in memory.
+package stream
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media types of the stream formats.
+const (
+	// MIMENDJSON is newline delimited JSON, one object per line.
+	MIMENDJSON = "application/x-ndjson"
+	// MIMECSV is comma separated values with a header record naming the
+	// columns by the dotted JSON paths of the fields, e.g. "metadata.name".
+	MIMECSV = "text/csv"
+)
+
+// formats maps the media types of the stream formats to the formats.
+var formats = map[string]string{
+	MIMENDJSON:              MIMENDJSON,
+	"application/jsonl":     MIMENDJSON,
+	"application/jsonlines": MIMENDJSON,
+	MIMECSV:                 MIMECSV,
+	"application/csv":       MIMECSV,
+}
+
+// FormatOf returns the stream format of the Content-Type header.
+func FormatOf(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	format, ok := formats[mediaType]
+
+	return format, ok
+}
+
+// Negotiate returns the stream format preferred by the Accept header,
+// honouring quality values, NDJSON if it accepts none.
+func Negotiate(accept string) string {
+	format, best := MIMENDJSON, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if f, ok := formats[mediaType]; ok && q > best {
+			format, best = f, q
+		}
+	}
+
+	return format
+}
+
+// RowError is an error of a single line or record, the stream can be read on.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return "row " + strconv.Itoa(e.Row) + ": " + e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
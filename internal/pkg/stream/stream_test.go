@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+		wantOK      bool
+	}{
+		{"application/x-ndjson", MIMENDJSON, true},
+		{"application/x-ndjson; charset=utf-8", MIMENDJSON, true},
+		{"application/jsonl", MIMENDJSON, true},
+		{"application/jsonlines", MIMENDJSON, true},
+		{"text/csv", MIMECSV, true},
+		{"Text/CSV; header=present", MIMECSV, true},
+		{"application/csv", MIMECSV, true},
+		{"application/json", "", false},
+		{"", "", false},
+		{";", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, ok := FormatOf(tt.contentType)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("FormatOf(%q) = %q, %v, want %q, %v", tt.contentType, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MIMENDJSON},
+		{"*/*", MIMENDJSON},
+		{"text/csv", MIMECSV},
+		{"application/json, text/csv", MIMECSV},
+		{"application/x-ndjson, text/csv", MIMENDJSON},
+		{"application/x-ndjson;q=0.5, text/csv", MIMECSV},
+		{"text/csv;q=0.9, application/jsonl;q=1", MIMENDJSON},
+		{"text/csv;q=x, application/csv;q=0.1", MIMECSV},
+		{"text/csv;q=0", MIMENDJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := Negotiate(tt.accept); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowError(t *testing.T) {
+	err := error(&RowError{Row: 3, Err: io.ErrUnexpectedEOF})
+	if got, want := err.Error(), "row 3: unexpected EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("RowError does not unwrap to its error")
+	}
+}
@@ -0,0 +1,128 @@
+package versioning
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"reflect"
+)
+
+// versionKey defines the key in gin context which holds the *Version of the request.
+const versionKey = "apiVersion"
+
+// FromContext returns the API version the request is served with.
+func FromContext(c *gin.Context) (*Version, bool) {
+	if v, ok := c.Get(versionKey); ok {
+		if version, ok := v.(*Version); ok {
+			return version, true
+		}
+	}
+
+	return nil, false
+}
+
+// ToDTO converts the internal object obj into the DTO of the version of the
+// request, it returns obj if the route is not versioned.
+func ToDTO(c *gin.Context, obj interface{}) (interface{}, error) {
+	if v, ok := FromContext(c); ok {
+		return v.Converter.FromInternal(obj)
+	}
+
+	return obj, nil
+}
+
+// Decode decodes the DTO of the version of the request with decode and
+// converts it into the internal object obj points to. If the route is not
+// versioned decode decodes obj directly.
+func Decode(c *gin.Context, obj interface{}, decode func(dto interface{}) error) error {
+	v, ok := FromContext(c)
+	if !ok {
+		return decode(obj)
+	}
+
+	dto := v.Converter.New()
+	if err := decode(dto); err != nil {
+		return err
+	}
+
+	return v.Converter.ToInternal(dto, obj)
+}
+
+// WriteResponse converts the internal object obj into the DTO of the version
+// of the request and writes it, see core.WriteResponse.
+func WriteResponse(c *gin.Context, err error, obj interface{}) {
+	if err == nil && obj != nil {
+		obj, err = ToDTO(c, obj)
+	}
+
+	core.WriteResponse(c, err, obj)
+}
+
+// WriteCreated converts the internal object obj into the DTO of the version
+// of the request and writes it, see core.WriteCreated.
+func WriteCreated(c *gin.Context, location string, obj interface{}) {
+	dto, err := ToDTO(c, obj)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+		return
+	}
+
+	core.WriteCreated(c, location, dto)
+}
+
+// WriteList converts the internal objects of the slice items into the DTOs
+// of the version of the request and writes them, see core.WriteList.
+func WriteList(c *gin.Context, err error, list metav1.ListMeta, items interface{}, fields []string) {
+	if err == nil {
+		if v, ok := FromContext(c); ok {
+			items, err = convertItems(v, items)
+		}
+	}
+
+	core.WriteList(c, err, list, items, fields)
+}
+
+// convertItems converts the internal objects of the slice items into DTOs.
+func convertItems(v *Version, items interface{}) ([]interface{}, error) {
+	s := reflect.ValueOf(items)
+	if s.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("items of type %T are not a slice", items)
+	}
+
+	dtos := make([]interface{}, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		dto, err := v.Converter.FromInternal(s.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+
+	return dtos, nil
+}
+
+// Bind decodes the request body into the DTO of the version of the request,
+// see core.Bind, and converts it into the internal object obj points to.
+func Bind(c *gin.Context, obj interface{}) error {
+	return Decode(c, obj, func(dto interface{}) error {
+		return bindError(core.Bind(c, dto))
+	})
+}
+
+// bindError returns err with the code of the reason the body could not be bound.
+func bindError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if limit, ok := core.IsBodyTooLarge(err); ok {
+		return errors.WrapC(err, code.ErrRequestEntityTooLarge, "request body exceeds the limit of %d bytes", limit)
+	}
+	if core.IsValidationError(err) {
+		return errors.WrapC(err, code.ErrValidation, "%s", err.Error())
+	}
+
+	return errors.WrapC(err, code.ErrBind, "%s", err.Error())
+}
@@ -0,0 +1,41 @@
+package versioning
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestBindError(t *testing.T) {
+	invalid := validator.New().Struct(&struct {
+		Name string `validate:"required"`
+	}{})
+
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		status int
+	}{
+		{"malformed", io.ErrUnexpectedEOF, code.ErrBind, http.StatusBadRequest},
+		{"invalid", invalid, code.ErrValidation, http.StatusUnprocessableEntity},
+		{"too large", &http.MaxBytesError{Limit: 1}, code.ErrRequestEntityTooLarge, http.StatusRequestEntityTooLarge},
+		{"wrapped too large", fmt.Errorf("read: %w", &http.MaxBytesError{Limit: 1}),
+			code.ErrRequestEntityTooLarge, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coder := errors.ParseCoder(bindError(tt.err))
+			if coder.Code() != tt.code || coder.HTTPStatus() != tt.status {
+				t.Errorf("bindError() = %d/%d, want %d/%d", coder.Code(), coder.HTTPStatus(), tt.code, tt.status)
+			}
+		})
+	}
+	if bindError(nil) != nil {
+		t.Error("bindError(nil) != nil")
+	}
+}
@@ -0,0 +1,31 @@
+package versioning
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// vendorMediaType matches vendor media types carrying a version, e.g.
+// "application/vnd.user.v2+json".
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.[^+]+\.(v[0-9][a-z0-9]*)\+json$`)
+
+// acceptedVersion returns the version requested by the Accept header, empty
+// if none: either the version parameter of a media range, e.g.
+// "application/json; version=v2", or a vendor media type.
+func acceptedVersion(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if v := params["version"]; v != "" {
+			return v
+		}
+		if m := vendorMediaType.FindStringSubmatch(mediaType); m != nil {
+			return m[1]
+		}
+	}
+
+	return ""
+}
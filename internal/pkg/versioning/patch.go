@@ -0,0 +1,99 @@
+package versioning
+
+import (
+	"encoding/json"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	"io"
+	"mime"
+	"reflect"
+)
+
+// Media types of the patch formats.
+const (
+	// JSONPatchType is a JSON Patch, see RFC 6902.
+	JSONPatchType = "application/json-patch+json"
+	// MergePatchType is a JSON Merge Patch, see RFC 7396.
+	MergePatchType = "application/merge-patch+json"
+)
+
+// Patch is a patch of the DTO of the version of a request, so clients patch
+// the representation they get. Initialize with ReadPatch.
+type Patch struct {
+	version   *Version
+	mediaType string
+	merge     []byte
+	ops       jsonpatch.Patch
+}
+
+// ReadPatch reads the patch in the request body; its Content-Type selects
+// the format, JSONPatchType or MergePatchType.
+func ReadPatch(c *gin.Context) (*Patch, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != JSONPatchType && mediaType != MergePatchType {
+		return nil, errors.WithCode(code.ErrUnsupportedMediaType,
+			"content type %q is neither %s nor %s", c.GetHeader("Content-Type"), JSONPatchType, MergePatchType)
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, bindError(err)
+	}
+
+	p := &Patch{mediaType: mediaType}
+	p.version, _ = FromContext(c)
+	if mediaType == JSONPatchType {
+		if p.ops, err = jsonpatch.DecodePatch(data); err != nil {
+			return nil, errors.WrapC(err, code.ErrBind, "%s", err.Error())
+		}
+	} else {
+		if !json.Valid(data) {
+			return nil, errors.WithCode(code.ErrBind, "merge patch is not valid JSON")
+		}
+		p.merge = data
+	}
+
+	return p, nil
+}
+
+// Apply applies the patch to the DTO of obj, a pointer to an internal object,
+// and converts the result back into obj. The patch may be applied again, e.g.
+// to a newer obj after a conflict.
+func (p *Patch) Apply(obj interface{}) error {
+	var (
+		dto interface{} = obj
+		err error
+	)
+	if p.version != nil {
+		if dto, err = p.version.Converter.FromInternal(obj); err != nil {
+			return err
+		}
+	}
+	doc, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	if p.mediaType == JSONPatchType {
+		doc, err = p.ops.Apply(doc)
+	} else {
+		doc, err = jsonpatch.MergePatch(doc, p.merge)
+	}
+	if err != nil {
+		return errors.WrapC(err, code.ErrPatchConflict, "%s", err.Error())
+	}
+
+	if p.version == nil {
+		// reset obj, so fields the patch removed are not kept
+		v := reflect.ValueOf(obj).Elem()
+		v.Set(reflect.Zero(v.Type()))
+		return bindError(json.Unmarshal(doc, obj))
+	}
+	patched := p.version.Converter.New()
+	if err := json.Unmarshal(doc, patched); err != nil {
+		return bindError(err)
+	}
+
+	return p.version.Converter.ToInternal(patched, obj)
+}
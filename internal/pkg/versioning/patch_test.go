@@ -0,0 +1,197 @@
+package versioning
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testObject is an internal object, testDTO its representation in testVersion.
+type testObject struct {
+	Name  string            `json:"name"`
+	Count int               `json:"count"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+type testDTO struct {
+	Title string            `json:"title"`
+	Count int               `json:"count"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+type testConverter struct{}
+
+func (testConverter) New() interface{} { return &testDTO{} }
+
+func (testConverter) FromInternal(obj interface{}) (interface{}, error) {
+	o, ok := obj.(*testObject)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %T", obj)
+	}
+
+	return &testDTO{Title: o.Name, Count: o.Count, Tags: o.Tags}, nil
+}
+
+func (testConverter) ToInternal(dto interface{}, obj interface{}) error {
+	in, out := dto.(*testDTO), obj.(*testObject)
+	*out = testObject{Name: in.Title, Count: in.Count, Tags: in.Tags}
+
+	return nil
+}
+
+var testVersion = &Version{Name: "v1", Converter: testConverter{}}
+
+// readPatch reads the patch of a request with the content type and body,
+// served with version v, unless it is nil.
+func readPatch(t *testing.T, v *Version, contentType, body string, limit int64) (*Patch, error) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	if limit > 0 {
+		c.Request.Body = http.MaxBytesReader(w, c.Request.Body, limit)
+	}
+	if v != nil {
+		c.Set(versionKey, v)
+	}
+
+	return ReadPatch(c)
+}
+
+func TestReadPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		limit       int64
+		code        int
+	}{
+		{name: "json patch", contentType: JSONPatchType, body: `[{"op":"remove","path":"/tags"}]`},
+		{name: "merge patch", contentType: MergePatchType, body: `{"count":1}`},
+		{name: "with parameters", contentType: MergePatchType + "; charset=utf-8", body: `{}`},
+		{name: "json", contentType: "application/json", body: `{}`, code: code.ErrUnsupportedMediaType},
+		{name: "none", body: `{}`, code: code.ErrUnsupportedMediaType},
+		{name: "json patch not an array", contentType: JSONPatchType, body: `{"op":"remove"}`, code: code.ErrBind},
+		{name: "merge patch not json", contentType: MergePatchType, body: `{"count":`, code: code.ErrBind},
+		{name: "too large", contentType: MergePatchType, body: `{"count":1}`, limit: 4, code: code.ErrRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readPatch(t, testVersion, tt.contentType, tt.body, tt.limit)
+			if tt.code == 0 && err != nil {
+				t.Fatalf("ReadPatch() error = %v", err)
+			}
+			if tt.code != 0 && !errors.IsCode(err, tt.code) {
+				t.Fatalf("ReadPatch() error = %v, want code %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestPatchApply(t *testing.T) {
+	object := func() *testObject {
+		return &testObject{Name: "a1", Count: 1, Tags: map[string]string{"env": "prod", "tier": "web"}}
+	}
+
+	tests := []struct {
+		name        string
+		version     *Version
+		contentType string
+		patch       string
+		want        *testObject
+		code        int
+	}{
+		{
+			name: "json patch replace", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/title","value":"a2"},{"op":"replace","path":"/count","value":2}]`,
+			want:  &testObject{Name: "a2", Count: 2, Tags: map[string]string{"env": "prod", "tier": "web"}},
+		},
+		{
+			name: "json patch add and remove", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"add","path":"/tags/canary","value":""},{"op":"remove","path":"/tags/tier"}]`,
+			want:  &testObject{Name: "a1", Count: 1, Tags: map[string]string{"env": "prod", "canary": ""}},
+		},
+		{
+			name: "json patch test passes", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"test","path":"/count","value":1},{"op":"remove","path":"/tags"}]`,
+			want:  &testObject{Name: "a1", Count: 1},
+		},
+		{
+			name: "json patch test fails", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"test","path":"/count","value":2}]`, code: code.ErrPatchConflict,
+		},
+		{
+			name: "json patch of the internal field name", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/name","value":"a2"}]`, code: code.ErrPatchConflict,
+		},
+		{
+			name: "json patch of a missing path", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"remove","path":"/tags/region"}]`, code: code.ErrPatchConflict,
+		},
+		{
+			name: "json patch wrong type", version: testVersion, contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/count","value":"two"}]`, code: code.ErrBind,
+		},
+		{
+			name: "merge patch", version: testVersion, contentType: MergePatchType,
+			patch: `{"title":"a2","tags":{"tier":null,"canary":""}}`,
+			want:  &testObject{Name: "a2", Count: 1, Tags: map[string]string{"env": "prod", "canary": ""}},
+		},
+		{
+			name: "merge patch null removes", version: testVersion, contentType: MergePatchType,
+			patch: `{"tags":null}`,
+			want:  &testObject{Name: "a1", Count: 1},
+		},
+		{
+			name: "merge patch of an unknown field", version: testVersion, contentType: MergePatchType,
+			patch: `{"name":"a2"}`,
+			want:  object(),
+		},
+		{
+			name: "merge patch wrong type", version: testVersion, contentType: MergePatchType,
+			patch: `{"count":"two"}`, code: code.ErrBind,
+		},
+		{
+			name: "unversioned merge patch", contentType: MergePatchType,
+			patch: `{"name":"a2","tags":null}`,
+			want:  &testObject{Name: "a2", Count: 1},
+		},
+		{
+			name: "unversioned json patch", contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/name","value":"a2"}]`,
+			want:  &testObject{Name: "a2", Count: 1, Tags: map[string]string{"env": "prod", "tier": "web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := readPatch(t, tt.version, tt.contentType, tt.patch, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a patch may be applied again, e.g. after a conflict
+			for i := 0; i < 2; i++ {
+				got := object()
+				err = p.Apply(got)
+				if tt.code != 0 {
+					if !errors.IsCode(err, tt.code) {
+						t.Fatalf("Apply() error = %v, want code %d", err, tt.code)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package versioning
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/internal/pkg/code"
+	"golang-standards-project-example/internal/pkg/request"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"golang-standards-project-example/pkg/openapi"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// dto and dtoList are the types of DTO and DTOList.
+type (
+	dto     struct{}
+	dtoList struct{}
+)
+
+var (
+	// DTO stands for the DTO of each version in the Request and Response of
+	// the openapi.Operation of a versioned route.
+	DTO interface{} = dto{}
+	// DTOList stands for a core.ListResponse of the DTOs of each version in
+	// the Response of the openapi.Operation of a versioned route.
+	DTOList interface{} = dtoList{}
+)
+
+// Router registers the routes of versioned resources. Each route is served
+// under the prefix of every version of its resource, e.g. /v1/users, and
+// without prefix, where the version is negotiated by the Accept header.
+type Router struct {
+	group *gin.RouterGroup
+	spec  *openapi.Spec
+	// verbs are the registered custom methods by HTTP method and verb.
+	verbs map[string]bool
+}
+
+// NewRouter returns a Router registering routes in the group and
+// documenting them in the spec.
+func NewRouter(group *gin.RouterGroup, spec *openapi.Spec) *Router {
+	return &Router{group: group, spec: spec, verbs: map[string]bool{}}
+}
+
+// GET registers a versioned route for GET requests, see Handle.
+func (rt *Router) GET(r *Resource, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.Handle(r, http.MethodGet, relativePath, op, handlers...)
+}
+
+// POST registers a versioned route for POST requests, see Handle.
+func (rt *Router) POST(r *Resource, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.Handle(r, http.MethodPost, relativePath, op, handlers...)
+}
+
+// PUT registers a versioned route for PUT requests, see Handle.
+func (rt *Router) PUT(r *Resource, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.Handle(r, http.MethodPut, relativePath, op, handlers...)
+}
+
+// PATCH registers a versioned route for PATCH requests, see Handle.
+func (rt *Router) PATCH(r *Resource, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.Handle(r, http.MethodPatch, relativePath, op, handlers...)
+}
+
+// DELETE registers a versioned route for DELETE requests, see Handle.
+func (rt *Router) DELETE(r *Resource, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.Handle(r, http.MethodDelete, relativePath, op, handlers...)
+}
+
+// Handle registers the handlers for every version of the resource and for
+// the negotiated version. The handlers work on the internal model and use
+// Bind and WriteResponse to convert from and to the DTO of the version.
+func (rt *Router) Handle(r *Resource, method, relativePath string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	for _, v := range r.versions {
+		versioned := "/" + v.Name + relativePath
+		rt.group.Handle(method, versioned, append([]gin.HandlerFunc{rt.pin(r, v)}, handlers...)...)
+		rt.spec.Describe(method, rt.absolutePath(versioned), versionedOperation(op, v))
+	}
+
+	rt.group.Handle(method, relativePath, append([]gin.HandlerFunc{rt.negotiate(r)}, handlers...)...)
+	rt.spec.Describe(method, rt.absolutePath(relativePath), negotiatedOperation(op, r))
+}
+
+// Custom registers the handlers of the custom method verb of the collection
+// or item at relativePath, e.g. POST /users:import or POST
+// /users/:name:restore, like Handle. gin cannot route paths like this, so
+// each verb gets its own route after the path, e.g. /users/@import, which
+// Rewrite routes the requests of the custom method to.
+func (rt *Router) Custom(r *Resource, method, relativePath, verb string, op openapi.Operation, handlers ...gin.HandlerFunc) {
+	rt.verbs[method+" "+verb] = true
+	for _, v := range r.versions {
+		versioned := "/" + v.Name + relativePath
+		rt.handleVerb(method, versioned, verb, append(gin.HandlersChain{rt.pin(r, v)}, handlers...))
+		rt.spec.AddRoute(method, rt.absolutePath(versioned)+":"+verb, versionedOperation(op, v))
+	}
+
+	rt.handleVerb(method, relativePath, verb, append(gin.HandlersChain{rt.negotiate(r)}, handlers...))
+	rt.spec.AddRoute(method, rt.absolutePath(relativePath)+":"+verb, negotiatedOperation(op, r))
+}
+
+// verbPrefix starts the last segment of the routes of custom methods.
+const verbPrefix = "@"
+
+// handleVerb registers the route of the custom method verb at relativePath.
+// The route only serves requests passed by Rewrite, whose path it restores
+// before the handlers run.
+func (rt *Router) handleVerb(method, relativePath, verb string, handlers gin.HandlersChain) {
+	route := path.Join(relativePath, verbPrefix+verb)
+	rt.group.Handle(method, route, append(gin.HandlersChain{restorePath}, handlers...)...)
+	rt.spec.Describe(method, rt.absolutePath(route), openapi.Operation{Hidden: true})
+}
+
+// Rewrite returns the request of a custom method with the path of its route,
+// e.g. /v1/users/@import for POST /v1/users:import, other requests unchanged.
+// It has to run before the request is routed, see request.OriginalPathFrom.
+func (rt *Router) Rewrite(r *http.Request) *http.Request {
+	p := r.URL.Path
+	i := strings.LastIndex(p, ":")
+	if i <= strings.LastIndex(p, "/")+1 || !rt.verbs[r.Method+" "+p[i+1:]] {
+		return r
+	}
+
+	u := *r.URL
+	u.Path, u.RawPath = p[:i]+"/"+verbPrefix+p[i+1:], ""
+	r = r.WithContext(request.WithOriginalPath(r.Context(), p))
+	r.URL = &u
+
+	return r
+}
+
+// restorePath restores the path of a request passed by Rewrite. Requests
+// sent to the route of a custom method directly are not found.
+func restorePath(c *gin.Context) {
+	original, ok := request.OriginalPathFrom(c.Request.Context())
+	if !ok {
+		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "%s not found", c.Request.URL.Path), nil)
+		c.Abort()
+		return
+	}
+	c.Request.URL.Path = original
+}
+
+// pin returns the handler serving requests with version v.
+func (rt *Router) pin(r *Resource, v *Version) gin.HandlerFunc {
+	prefix := rt.absolutePath("/" + v.Name)
+	successor := rt.absolutePath("/" + r.Preferred().Name)
+
+	return func(c *gin.Context) {
+		c.Set(versionKey, v)
+		v.setHeaders(c.Writer.Header())
+		if v.IsDeprecated() && v != r.Preferred() {
+			link := successor + strings.TrimPrefix(c.Request.URL.Path, prefix)
+			c.Writer.Header().Add("Link", "<"+link+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
+
+// negotiate returns the handler serving requests with the version of their
+// Accept header, the preferred version if none.
+func (rt *Router) negotiate(r *Resource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept")
+
+		v := r.Preferred()
+		if name := acceptedVersion(c.GetHeader("Accept")); name != "" {
+			var ok bool
+			if v, ok = r.Version(name); !ok {
+				core.WriteResponse(c, errors.WithCode(code.ErrUnsupportedVersion,
+					"version %q of %s is not supported", name, r.Name), nil)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(versionKey, v)
+		v.setHeaders(c.Writer.Header())
+		c.Next()
+	}
+}
+
+// absolutePath returns the path of relativePath in the group.
+func (rt *Router) absolutePath(relativePath string) string {
+	p := path.Join(rt.group.BasePath(), relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+
+	return p
+}
+
+// negotiatedOperation returns op documenting the route negotiating the version.
+func negotiatedOperation(op openapi.Operation, r *Resource) openapi.Operation {
+	negotiated := versionedOperation(op, r.Preferred())
+	negotiated.Deprecated = op.Deprecated
+	negotiated.Codes = append(negotiated.Codes, code.ErrUnsupportedVersion)
+	negotiated.Description = strings.TrimSpace(negotiated.Description + "\n\n" +
+		"The version is negotiated by the Accept header, e.g. `application/json; version=" +
+		r.Preferred().Name + "`, and defaults to " + r.Preferred().Name + ".")
+
+	return negotiated
+}
+
+// versionedOperation returns op documenting the version v.
+func versionedOperation(op openapi.Operation, v *Version) openapi.Operation {
+	if op.Request == DTO {
+		op.Request = v.Converter.New()
+	}
+	switch op.Response {
+	case DTO:
+		op.Response = v.Converter.New()
+	case DTOList:
+		op.Response = listOf(v.Converter.New())
+	}
+	op.Deprecated = op.Deprecated || v.IsDeprecated()
+	op.Codes = append([]int(nil), op.Codes...)
+
+	return op
+}
+
+// listOf returns a value of a struct type like core.ListResponse with items
+// of the type of dto, to document list responses.
+func listOf(dto interface{}) interface{} {
+	t := reflect.TypeOf(core.ListResponse{})
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "Items" {
+			f.Type = reflect.SliceOf(reflect.TypeOf(dto))
+		}
+		fields = append(fields, f)
+	}
+
+	return reflect.New(reflect.StructOf(fields)).Elem().Interface()
+}
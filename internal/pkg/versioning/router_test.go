@@ -0,0 +1,83 @@
+package versioning
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/pkg/openapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCustom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	rt := NewRouter(engine.Group("/api"), openapi.NewSpec(openapi.Info{}))
+	r := NewResource("items",
+		Version{Name: "v1", Converter: testConverter{}, Deprecated: time.Unix(0, 0)},
+		Version{Name: "v2", Converter: testConverter{}},
+	)
+	reply := func(c *gin.Context) {
+		v, _ := FromContext(c)
+		c.String(http.StatusOK, "%s %s %s %s", v.Name, c.Param("name"), c.Request.URL.Path, c.GetString("around"))
+	}
+	// handlers calling c.Next run around the later ones
+	around := func(c *gin.Context) {
+		c.Set("around", "before")
+		c.Next()
+		c.Writer.Header().Set("X-After", "true")
+	}
+	rt.Custom(r, http.MethodPost, "/items", "import", openapi.Operation{}, around, reply)
+	rt.Custom(r, http.MethodGet, "/items", "export", openapi.Operation{}, reply)
+	rt.Custom(r, http.MethodPost, "/items/:name", "restore", openapi.Operation{}, reply)
+	rt.GET(r, "/items/:name", openapi.Operation{}, reply)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+		link   string
+	}{
+		{method: http.MethodPost, path: "/api/v2/items:import", status: http.StatusOK,
+			body: "v2  /api/v2/items:import before"},
+		{method: http.MethodPost, path: "/api/items:import", status: http.StatusOK,
+			body: "v2  /api/items:import before"},
+		{method: http.MethodGet, path: "/api/v1/items:export", status: http.StatusOK,
+			body: "v1  /api/v1/items:export ", link: `</api/v2/items:export>; rel="successor-version"`},
+		{method: http.MethodPost, path: "/api/v2/items/a:restore", status: http.StatusOK,
+			body: "v2 a /api/v2/items/a:restore "},
+		{method: http.MethodGet, path: "/api/v2/items/a", status: http.StatusOK,
+			body: "v2 a /api/v2/items/a "},
+		{method: http.MethodGet, path: "/api/v2/items/a:b", status: http.StatusOK,
+			body: "v2 a:b /api/v2/items/a:b "},
+		{method: http.MethodGet, path: "/api/v2/items:import", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v2/itemsx:import", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v2/items/a:import", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v2/items/:restore", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v2/items/@import", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/v2/items/a/@restore", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, rt.Rewrite(httptest.NewRequest(tt.method, tt.path, nil)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+			if got := w.Header().Get("Link"); got != tt.link {
+				t.Errorf("Link = %q, want %q", got, tt.link)
+			}
+			if tt.path == "/api/v2/items:import" && w.Header().Get("X-After") != "true" {
+				t.Error("handler calling c.Next did not continue after the later handlers")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package versioning
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers set on the responses of versioned routes.
+const (
+	// HeaderAPIVersion holds the version a request was served with.
+	HeaderAPIVersion = "X-API-Version"
+	// HeaderDeprecation holds the time a version was deprecated, see RFC 9745.
+	HeaderDeprecation = "Deprecation"
+	// HeaderSunset holds the time a version will be removed, see RFC 8594.
+	HeaderSunset = "Sunset"
+)
+
+// Converter converts between the internal model of a resource and the DTO
+// of an API version.
+type Converter interface {
+	// New returns a pointer to an empty DTO, e.g. to bind a request body to.
+	New() interface{}
+	// FromInternal converts the internal object into the DTO.
+	FromInternal(obj interface{}) (interface{}, error)
+	// ToInternal converts the DTO returned by New into the internal object obj points to.
+	ToInternal(dto interface{}, obj interface{}) error
+}
+
+// Version is an API version of a resource.
+type Version struct {
+	// Name is the version in paths and Accept headers, e.g. "v1".
+	Name string
+	// Converter converts between the internal model and the DTO of this version.
+	Converter Converter
+	// Deprecated is the time the version was deprecated, zero if it is not.
+	Deprecated time.Time
+	// Sunset is the time the version will be removed, zero if not planned.
+	Sunset time.Time
+}
+
+// IsDeprecated reports whether the version is deprecated.
+func (v *Version) IsDeprecated() bool {
+	return !v.Deprecated.IsZero()
+}
+
+// setHeaders sets the version, deprecation and sunset headers of the response.
+func (v *Version) setHeaders(h http.Header) {
+	h.Set(HeaderAPIVersion, v.Name)
+	if v.IsDeprecated() {
+		h.Set(HeaderDeprecation, "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+	}
+	if !v.Sunset.IsZero() {
+		h.Set(HeaderSunset, v.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Resource is an API resource served in one or more versions.
+// Initialize with NewResource.
+type Resource struct {
+	Name     string
+	versions []*Version
+}
+
+// NewResource returns a resource served in the given versions, oldest first.
+func NewResource(name string, versions ...Version) *Resource {
+	r := &Resource{Name: name}
+	for i := range versions {
+		r.versions = append(r.versions, &versions[i])
+	}
+
+	return r
+}
+
+// Versions returns the versions of the resource, oldest first.
+func (r *Resource) Versions() []*Version {
+	return r.versions
+}
+
+// Version returns the version with the given name.
+func (r *Resource) Version(name string) (*Version, bool) {
+	for _, v := range r.versions {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// Preferred returns the version served if a client requests none: the newest
+// version which is not deprecated, or the newest version if all are.
+func (r *Resource) Preferred() *Version {
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if !r.versions[i].IsDeprecated() {
+			return r.versions[i]
+		}
+	}
+
+	return r.versions[len(r.versions)-1]
+}
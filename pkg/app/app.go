@@ -23,6 +23,8 @@ type App struct {
 	noVersion   bool
 	noConfig    bool
 	silence     bool
+	strictEnv   bool
+	envErr      error
 	runFunc     RunFunc
 	commands    []*Command //子命令
 	cmd         *cobra.Command
@@ -62,6 +64,15 @@ func WithNoConfig(noConfig bool) Option {
 	}
 }
 
+// WithStrictEnv sets the default of the --strict-env flag, which makes the
+// application refuse to start when an environment variable carrying the
+// application prefix is not bound to any flag.
+func WithStrictEnv(strictEnv bool) Option {
+	return func(app *App) {
+		app.strictEnv = strictEnv
+	}
+}
+
 func NewApp(name, baseName string, opts ...Option) *App {
 	app := &App{
 		name:     name,
@@ -70,6 +81,9 @@ func NewApp(name, baseName string, opts ...Option) *App {
 	for _, opt := range opts {
 		opt(app)
 	}
+	if !app.noVersion {
+		app.commands = append(app.commands, newVersionCommand())
+	}
 	app.buildCommond()
 	return app
 }
@@ -102,31 +116,42 @@ func (a *App) buildCommond() {
 		for _, f := range namedFlagSets.FlagSets {
 			fs.AddFlagSet(f)
 		}
+		markSensitiveFlags(a.options, fs)
 	}
 	if !a.noVersion {
 		verflag.AddFlags(namedFlagSets.FlagSet("global"))
 	}
+	// environment variables must be applied before the configuration file is read
+	cobra.OnInitialize(func() {
+		a.envErr = a.applyEnvs(&cmd)
+	})
 	//添加全局flag
 	if !a.noConfig {
-		addConfigFlag(a.basename, namedFlagSets.FlagSet("global"))
+		addConfigFlag(namedFlagSets.FlagSet("global"))
 	}
+	addStrictEnvFlag(namedFlagSets.FlagSet("global"), EnvPrefix(a.basename), &a.strictEnv)
 	AddGlobalFlags(namedFlagSets.FlagSet("global"), cmd.Name())
 	// add new global flagset to cmd FlagSet
 	cmd.Flags().AddFlagSet(namedFlagSets.FlagSet("global"))
+	bindFlagEnvs(EnvPrefix(a.basename), namedFlagSets)
 
 	addCmdTemplate(&cmd, namedFlagSets)
 	a.cmd = &cmd
 }
 
-// Run is used to launch the application.
+// Run is used to launch the application. If the error returned by the
+// application implements ExitCoder, its exit code is used, 1 otherwise.
 func (a *App) Run() {
 	if err := a.cmd.Execute(); err != nil {
 		fmt.Printf("%v %v\n", color.RedString("Error:"), err)
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
 
 func (a *App) runCommand(cmd *cobra.Command, args []string) error {
+	if a.envErr != nil {
+		return a.envErr
+	}
 	printWorkingDir()
 	PrintFlags(cmd.Flags())
 	if !a.noVersion {
@@ -139,7 +164,7 @@ func (a *App) runCommand(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		if err := viper.Unmarshal(a.options); err != nil {
+		if err := viper.Unmarshal(a.options, decodeHook); err != nil {
 			return err
 		}
 	}
@@ -147,7 +172,7 @@ func (a *App) runCommand(cmd *cobra.Command, args []string) error {
 	if !a.silence {
 		log.Printf("%v Starting %s ...\n", progressMessage, a.name)
 		if !a.noVersion {
-			log.Printf("%v Version: `%s`\n", progressMessage, version.Get().ToJSON())
+			log.Printf("%v Version: `%s`\n", progressMessage, version.Get().Brief().ToJSON())
 		}
 		if !a.noConfig {
 			log.Printf("%v Config file used: `%s`\n", progressMessage, viper.ConfigFileUsed())
@@ -166,6 +191,20 @@ func (a *App) runCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applyEnvs sets the flags of cmd from their environment variables and, in
+// strict mode, rejects unknown environment variables. Strict mode may itself
+// be enabled by its environment variable, so it is checked afterwards.
+func (a *App) applyEnvs(cmd *cobra.Command) error {
+	if err := applyFlagEnvs(cmd.Flags()); err != nil {
+		return err
+	}
+	if a.strictEnv {
+		return checkUnknownEnvs(EnvPrefix(a.basename), cmd.Flags())
+	}
+
+	return nil
+}
+
 func (a *App) applyOptionRules() error {
 	if completeableOptions, ok := a.options.(CompleteableOptions); ok {
 		if err := completeableOptions.Complete(); err != nil {
@@ -198,6 +237,12 @@ func addCmdTemplate(cmd *cobra.Command, namedFlagSets NamedFlagSets) {
 		return nil
 	})
 	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		// sub commands print their own flags
+		if cmd.HasParent() {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n"+usageFmt+"\nFlags:\n%s", cmd.Short, cmd.UseLine(),
+				cmd.LocalFlags().FlagUsagesWrapped(cols))
+			return
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n"+usageFmt, cmd.Long, cmd.UseLine())
 		PrintSections(cmd.OutOrStdout(), namedFlagSets, cols)
 	})
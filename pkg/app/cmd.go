@@ -60,7 +60,7 @@ func (c *Command) runCommand(_ *cobra.Command, args []string) {
 	if c.runFunc != nil {
 		if err := c.runFunc(args); err != nil {
 			fmt.Printf("%v %v\n", color.RedString("Error:"), err)
-			os.Exit(1)
+			os.Exit(ExitCode(err))
 		}
 	}
 }
@@ -84,6 +84,7 @@ func (c *Command) cobraCommand() *cobra.Command {
 		for _, f := range c.options.Flags().FlagSets {
 			cmd.Flags().AddFlagSet(f)
 		}
+		markSensitiveFlags(c.options, cmd.Flags())
 		// c.options.AddFlags(cmd.Flags())
 	}
 	addHelpCommandFlag(c.usage, cmd.Flags())
@@ -3,10 +3,14 @@ package app
 import (
 	"fmt"
 	"github.com/gosuri/uitable"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	my_error "golang-standards-project-example/pkg/errors"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -21,18 +25,17 @@ func init() {
 }
 
 // addConfigFlag adds flags for a specific server to the specified FlagSet
-// object.
-func addConfigFlag(basename string, fs *pflag.FlagSet) {
+// object. The configuration file is optional. Environment variables are bound
+// to the flags by bindFlagEnvs, so they take precedence over the configuration
+// file once flags are bound to viper.
+func addConfigFlag(fs *pflag.FlagSet) {
 	fs.AddFlag(pflag.Lookup(configFlagName))
 
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix(strings.Replace(strings.ToUpper(basename), "-", "_", -1))
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
-
 	cobra.OnInitialize(func() {
-		if cfgFile != "" {
-			viper.SetConfigFile(cfgFile)
+		if cfgFile == "" {
+			return
 		}
+		viper.SetConfigFile(cfgFile)
 		if err := viper.ReadInConfig(); err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to read configuration file(%s): %v\n", cfgFile, err)
 			os.Exit(1)
@@ -40,7 +43,43 @@ func addConfigFlag(basename string, fs *pflag.FlagSet) {
 	})
 }
 
-func printConfig() {
+// decodeHook decodes the values viper reads from flags and the configuration
+// file into options. It adds maps of StringToInt64 flags, e.g. "[a=1,b=2]",
+// to the default hooks of viper.
+var decodeHook = viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+	stringToInt64MapHook,
+))
+
+func stringToInt64MapHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(map[string]int64{}) {
+		return data, nil
+	}
+
+	m := map[string]int64{}
+	s := strings.TrimSuffix(strings.TrimPrefix(data.(string), "["), "]")
+	if s == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q must be formatted as key=value", pair)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pair, err)
+		}
+		m[key] = n
+	}
+
+	return m, nil
+}
+
+// printConfig prints all configuration items, redacting the values of the
+// given sensitive keys.
+func printConfig(sensitive my_error.String) {
 	if keys := viper.AllKeys(); len(keys) > 0 {
 		fmt.Printf("%v Configuration items:\n", progressMessage)
 		table := uitable.New()
@@ -48,7 +87,11 @@ func printConfig() {
 		table.MaxColWidth = 80
 		table.RightAlign(0)
 		for _, k := range keys {
-			table.AddRow(fmt.Sprintf("%s:", k), viper.Get(k))
+			var value interface{} = viper.Get(k)
+			if sensitive.Has(k) {
+				value = RedactedValue
+			}
+			table.AddRow(fmt.Sprintf("%s:", k), value)
 		}
 		fmt.Printf("%v", table)
 	}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"github.com/spf13/pflag"
+	my_error "golang-standards-project-example/pkg/errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// envAnnotation is the flag annotation key which holds the environment
+// variable bound to a flag.
+const envAnnotation = "app.env"
+
+// flagStrictEnv is the flag enabling strict environment mode.
+const flagStrictEnv = "strict-env"
+
+// flags which never read their value from the environment.
+var noEnvFlags = map[string]struct{}{flagHelp: {}, "version": {}}
+
+// EnvPrefix returns the environment variable prefix used by the application
+// with the given basename, e.g. "user-apiserver" becomes "USER_APISERVER".
+func EnvPrefix(basename string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(FormatBaseName(basename)))
+}
+
+// EnvName returns the environment variable bound to the flag or config key
+// with the given name, e.g. "http.bind-port" becomes "USER_APISERVER_HTTP_BIND_PORT".
+func EnvName(prefix, name string) string {
+	return prefix + "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// FlagEnv returns the environment variable bound to the flag, if any.
+func FlagEnv(f *pflag.Flag) (string, bool) {
+	if envs, ok := f.Annotations[envAnnotation]; ok && len(envs) > 0 {
+		return envs[0], true
+	}
+
+	return "", false
+}
+
+// bindFlagEnvs binds every flag in the flag sets to the environment variable
+// derived from its name and documents the variable in the flag usage.
+func bindFlagEnvs(prefix string, fss NamedFlagSets) {
+	for _, name := range fss.Order {
+		fss.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+			if _, ok := noEnvFlags[f.Name]; ok {
+				return
+			}
+			if _, ok := FlagEnv(f); ok {
+				return
+			}
+			env := EnvName(prefix, f.Name)
+			if f.Annotations == nil {
+				f.Annotations = map[string][]string{}
+			}
+			f.Annotations[envAnnotation] = []string{env}
+			f.Usage = fmt.Sprintf("%s [$%s]", f.Usage, env)
+		})
+	}
+}
+
+// applyFlagEnvs sets every flag which was not given on the command line from
+// its environment variable. Command line flags take precedence over
+// environment variables, which take precedence over the configuration file.
+func applyFlagEnvs(fs *pflag.FlagSet) error {
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		env, ok := FlagEnv(f)
+		if !ok || f.Changed {
+			return
+		}
+		if value, ok := os.LookupEnv(env); ok {
+			if err := fs.Set(f.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for environment variable %s: %v", value, env, err))
+			}
+		}
+	})
+
+	return my_error.NewAggregate(errs)
+}
+
+// checkUnknownEnvs returns an error if any environment variable carrying the
+// given prefix is not bound to a flag.
+func checkUnknownEnvs(prefix string, fs *pflag.FlagSet) error {
+	known := map[string]struct{}{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if env, ok := FlagEnv(f); ok {
+			known[env] = struct{}{}
+		}
+	})
+
+	var unknown []string
+	for _, kv := range os.Environ() {
+		env := strings.SplitN(kv, "=", 2)[0]
+		if _, ok := known[env]; strings.HasPrefix(env, prefix+"_") && !ok {
+			unknown = append(unknown, env)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	return fmt.Errorf("unknown environment variables: %s", strings.Join(unknown, ", "))
+}
+
+// addStrictEnvFlag adds the flag enabling strict environment mode, whose
+// default is strictEnv.
+func addStrictEnvFlag(fs *pflag.FlagSet, prefix string, strictEnv *bool) {
+	fs.BoolVar(strictEnv, flagStrictEnv, *strictEnv, fmt.Sprintf(""+
+		"Refuse to start if an environment variable starting with %s_ is not bound to a flag.", prefix))
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"github.com/spf13/pflag"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		basename string
+		name     string
+		want     string
+	}{
+		{"user-apiserver", "http.bind-port", "USER_APISERVER_HTTP_BIND_PORT"},
+		{"user-apiserver", "log.output-path", "USER_APISERVER_LOG_OUTPUT_PATH"},
+		{"app", "mode", "APP_MODE"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(EnvPrefix(tt.basename), tt.name); got != tt.want {
+			t.Errorf("EnvName(EnvPrefix(%q), %q) = %q, want %q", tt.basename, tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTestFlagSets returns flag sets with the flags port and mode bound to
+// environment variables with the prefix TEST.
+func newTestFlagSets() (*pflag.FlagSet, NamedFlagSets) {
+	var fss NamedFlagSets
+	fss.FlagSet("generic").Int("http.port", 8080, "port")
+	fss.FlagSet("generic").String("mode", "debug", "mode")
+	fss.FlagSet("global").Bool(flagHelp, false, "help")
+	bindFlagEnvs("TEST", fss)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	for _, name := range fss.Order {
+		fs.AddFlagSet(fss.FlagSets[name])
+	}
+
+	return fs, fss
+}
+
+func TestApplyFlagEnvs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		wantPort string
+		wantMode string
+		wantErr  bool
+	}{
+		{name: "defaults", wantPort: "8080", wantMode: "debug"},
+		{name: "env", env: map[string]string{"TEST_HTTP_PORT": "9090", "TEST_MODE": "release"}, wantPort: "9090", wantMode: "release"},
+		{name: "flag wins", args: []string{"--http.port=7070"}, env: map[string]string{"TEST_HTTP_PORT": "9090"}, wantPort: "7070", wantMode: "debug"},
+		{name: "invalid", env: map[string]string{"TEST_HTTP_PORT": "x"}, wantErr: true},
+		{name: "help not bound", env: map[string]string{"TEST_HELP": "true"}, wantPort: "8080", wantMode: "debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fs, _ := newTestFlagSets()
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := applyFlagEnvs(fs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyFlagEnvs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := fs.Lookup("http.port").Value.String(); got != tt.wantPort {
+				t.Errorf("http.port = %s, want %s", got, tt.wantPort)
+			}
+			if got := fs.Lookup("mode").Value.String(); got != tt.wantMode {
+				t.Errorf("mode = %s, want %s", got, tt.wantMode)
+			}
+			if got := fs.Lookup(flagHelp).Value.String(); got != "false" {
+				t.Errorf("help = %s, want false", got)
+			}
+		})
+	}
+}
+
+func TestBindFlagEnvsUsage(t *testing.T) {
+	fs, _ := newTestFlagSets()
+	if usage := fs.Lookup("http.port").Usage; !strings.HasSuffix(usage, "[$TEST_HTTP_PORT]") {
+		t.Errorf("usage %q does not document the environment variable", usage)
+	}
+	if _, ok := FlagEnv(fs.Lookup(flagHelp)); ok {
+		t.Errorf("help is bound to an environment variable")
+	}
+}
+
+func TestCheckUnknownEnvs(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "known", env: map[string]string{"TEST_MODE": "release"}},
+		{name: "other prefix", env: map[string]string{"TESTING_FOO": "1"}},
+		{name: "unknown", env: map[string]string{"TEST_FOO": "1", "TEST_BAR": "1"}, wantErr: "TEST_BAR, TEST_FOO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fs, _ := newTestFlagSets()
+
+			err := checkUnknownEnvs("TEST", fs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkUnknownEnvs() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkUnknownEnvs() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyEnvsStrictMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		strictEnv bool
+		args      []string
+		env       map[string]string
+		wantErr   bool
+	}{
+		{name: "off by default", env: map[string]string{"TEST_APP_FOO": "1"}},
+		{name: "option", strictEnv: true, env: map[string]string{"TEST_APP_FOO": "1"}, wantErr: true},
+		{name: "flag", args: []string{"--strict-env"}, env: map[string]string{"TEST_APP_FOO": "1"}, wantErr: true},
+		{name: "env", env: map[string]string{"TEST_APP_STRICT_ENV": "true", "TEST_APP_FOO": "1"}, wantErr: true},
+		{name: "flag off", strictEnv: true, args: []string{"--strict-env=false"}, env: map[string]string{"TEST_APP_FOO": "1"}},
+		{name: "strict without unknown", strictEnv: true, env: map[string]string{"TEST_APP_STRICT_ENV": "true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			a := NewApp("test", "test-app", WithNoConfig(true), WithNoVersion(true), WithStrictEnv(tt.strictEnv),
+				WithRunFunc(func(string) error { return nil }))
+			if err := a.cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := a.applyEnvs(a.cmd); (err != nil) != tt.wantErr {
+				t.Errorf("applyEnvs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package app
+
+import "errors"
+
+// DefaultExitCode is the exit code used for errors which do not implement ExitCoder.
+const DefaultExitCode = 1
+
+// ExitCoder abstracts errors which carry the exit code of the application.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// ExitCode returns the exit code of the first error in err's chain which
+// implements ExitCoder, DefaultExitCode otherwise.
+func ExitCode(err error) int {
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	return DefaultExitCode
+}
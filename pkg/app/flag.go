@@ -23,10 +23,15 @@ func InitFlags(flags *pflag.FlagSet) {
 	flags.AddGoFlagSet(flag.CommandLine)
 }
 
-// PrintFlags logs the flags in the flagset.
+// PrintFlags logs the flags in the flagset. Values of flags marked as
+// sensitive are redacted.
 func PrintFlags(flags *pflag.FlagSet) {
 	flags.VisitAll(func(flag *pflag.Flag) {
-		log.Printf("Flag name: %s and value: %s has been parsed", flag.Name, flag.Value)
+		value := flag.Value.String()
+		if IsFlagSensitive(flag) && value != "" {
+			value = RedactedValue
+		}
+		log.Printf("Flag name: %s and value: %s has been parsed", flag.Name, value)
 	})
 }
 
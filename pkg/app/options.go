@@ -13,6 +13,8 @@ type CompleteableOptions interface {
 }
 
 // PrintableOptions abstracts options which can be printed.
+// Implementations should redact fields tagged with `secret:"true"`,
+// e.g. by using RedactedJSON.
 type PrintableOptions interface {
 	String() string
 }
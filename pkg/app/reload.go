@@ -0,0 +1,58 @@
+package app
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	my_error "golang-standards-project-example/pkg/errors"
+	"io"
+)
+
+// LoadOptions reads opts of the application with the basename from the
+// command line arguments, the environment variables and the configuration
+// file read on start, if any, with the same precedence as on start, then
+// completes and validates them. Arguments which are not flags of opts, e.g.
+// --version, are ignored. Use it to reload the options at runtime, e.g.
+// LoadOptions(basename, newOpts, os.Args[1:]) on SIGHUP, the configuration
+// file is read again.
+func LoadOptions(basename string, opts CliOptions, args []string) error {
+	fss := opts.Flags()
+	bindFlagEnvs(EnvPrefix(basename), fss)
+
+	fs := pflag.NewFlagSet(basename, pflag.ContinueOnError)
+	fs.SetNormalizeFunc(WordSepNormalizeFunc)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	fs.SetOutput(io.Discard)
+	for _, name := range fss.Order {
+		fs.AddFlagSet(fss.FlagSets[name])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyFlagEnvs(fs); err != nil {
+		return err
+	}
+	if file := viper.ConfigFileUsed(); file != "" {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return err
+		}
+		if err := v.BindPFlags(fs); err != nil {
+			return err
+		}
+		if err := v.Unmarshal(opts, decodeHook); err != nil {
+			return err
+		}
+	}
+
+	if completeableOptions, ok := opts.(CompleteableOptions); ok {
+		if err := completeableOptions.Complete(); err != nil {
+			return err
+		}
+	}
+	if errs := opts.Validate(); len(errs) != 0 {
+		return my_error.NewAggregate(errs)
+	}
+
+	return nil
+}
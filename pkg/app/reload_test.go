@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// reloadOptions are options with a port, a mode and limits.
+type reloadOptions struct {
+	Port   int              `mapstructure:"port"`
+	Mode   string           `mapstructure:"mode"`
+	Limits map[string]int64 `mapstructure:"limits"`
+}
+
+func (o *reloadOptions) Flags() (fss NamedFlagSets) {
+	fss.FlagSet("generic").IntVar(&o.Port, "port", 8080, "port")
+	fss.FlagSet("generic").StringVar(&o.Mode, "mode", "debug", "mode")
+	fss.FlagSet("generic").StringToInt64Var(&o.Limits, "limits", nil, "limits")
+	return
+}
+
+func (o *reloadOptions) Validate() []error {
+	if o.Port < 0 {
+		return []error{fmt.Errorf("--port %d must not be negative", o.Port)}
+	}
+	return nil
+}
+
+func TestLoadOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		config   string
+		wantPort int
+		wantMode string
+		wantErr  bool
+	}{
+		{name: "defaults", wantPort: 8080, wantMode: "debug"},
+		{name: "flags", args: []string{"--port=7070", "--mode", "release"}, wantPort: 7070, wantMode: "release"},
+		{name: "env", env: map[string]string{"TEST_PORT": "9090"}, wantPort: 9090, wantMode: "debug"},
+		{name: "flag wins", args: []string{"--port=7070"}, env: map[string]string{"TEST_PORT": "9090"}, wantPort: 7070, wantMode: "debug"},
+		{name: "config file", config: "port: 6060\nmode: test\n", wantPort: 6060, wantMode: "test"},
+		{name: "env wins over config file", env: map[string]string{"TEST_PORT": "9090"}, config: "port: 6060\n", wantPort: 9090, wantMode: "debug"},
+		{name: "unknown flags ignored", args: []string{"--version", "--strict-env=true", "--mode=release"}, wantPort: 8080, wantMode: "release"},
+		{name: "invalid env", env: map[string]string{"TEST_PORT": "x"}, wantErr: true},
+		{name: "invalid flag", args: []string{"--port=x"}, wantErr: true},
+		{name: "invalid config file", config: "port: [\n", wantErr: true},
+		{name: "not valid", args: []string{"--port=-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer viper.Reset()
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if tt.config != "" {
+				file := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				viper.SetConfigFile(file)
+			}
+			opts := &reloadOptions{}
+			err := LoadOptions("test", opts, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if opts.Port != tt.wantPort || opts.Mode != tt.wantMode {
+				t.Errorf("options = %+v, want port %d and mode %q", opts, tt.wantPort, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestStringToInt64MapHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"empty", "[]", map[string]int64{}, false},
+		{"one", "[/import=100]", map[string]int64{"/import": 100}, false},
+		{"several", "[a=1,b=2]", map[string]int64{"a": 1, "b": 2}, false},
+		{"no brackets", "a=1", map[string]int64{"a": 1}, false},
+		{"missing value", "[a]", nil, true},
+		{"not a number", "[a=x]", nil, true},
+		{"map kept", map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}, false},
+	}
+	to := reflect.TypeOf(map[string]int64{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stringToInt64MapHook(reflect.TypeOf(tt.data), to, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/spf13/pflag"
+	my_error "golang-standards-project-example/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of sensitive options in logs.
+const RedactedValue = "******"
+
+// secretAnnotation is the flag annotation key which marks a flag as sensitive.
+const secretAnnotation = "app.secret"
+
+// secretTag is the struct tag which marks an option field as sensitive, e.g.
+//
+//	Password string `json:"password" mapstructure:"password" secret:"true"`
+const secretTag = "secret"
+
+// MarkFlagSensitive marks the flag with the given name so that its value is
+// redacted by PrintFlags.
+func MarkFlagSensitive(fs *pflag.FlagSet, name string) {
+	_ = fs.SetAnnotation(name, secretAnnotation, []string{"true"})
+}
+
+// IsFlagSensitive reports whether the flag was marked as sensitive.
+func IsFlagSensitive(f *pflag.Flag) bool {
+	_, ok := f.Annotations[secretAnnotation]
+	return ok
+}
+
+// SensitiveKeys returns the configuration keys of all option fields tagged
+// with `secret:"true"`. Keys are built from the mapstructure tags, so they
+// match the flag names, e.g. "mysql.password".
+func SensitiveKeys(options interface{}) my_error.String {
+	keys := my_error.NewString()
+	if options != nil {
+		collectSensitiveKeys(reflect.TypeOf(options), "", keys)
+	}
+
+	return keys
+}
+
+func collectSensitiveKeys(t reflect.Type, prefix string, keys my_error.String) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash := fieldName(f, "mapstructure")
+		if name == "-" {
+			continue
+		}
+		key := prefix
+		if !squash {
+			key = joinKey(prefix, name)
+		}
+		if isSecretField(f) {
+			keys.Insert(key)
+			continue
+		}
+		collectSensitiveKeys(f.Type, key, keys)
+	}
+}
+
+// RedactedJSON returns the JSON encoding of options with the values of all
+// fields tagged with `secret:"true"` replaced by RedactedValue.
+func RedactedJSON(options interface{}) string {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return ""
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return string(data)
+	}
+	redactJSON(reflect.TypeOf(options), m)
+	data, _ = json.Marshal(m)
+
+	return string(data)
+}
+
+func redactJSON(t reflect.Type, m map[string]interface{}) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash := fieldName(f, "json")
+		if name == "-" {
+			continue
+		}
+		if squash {
+			redactJSON(f.Type, m)
+			continue
+		}
+		if isSecretField(f) {
+			if v, ok := m[name]; ok && v != nil && v != "" {
+				m[name] = RedactedValue
+			}
+			continue
+		}
+		if sub, ok := m[name].(map[string]interface{}); ok {
+			redactJSON(f.Type, sub)
+		}
+	}
+}
+
+// markSensitiveFlags marks every flag whose name is a sensitive key of options.
+func markSensitiveFlags(options interface{}, fs *pflag.FlagSet) {
+	for key := range SensitiveKeys(options) {
+		if fs.Lookup(key) != nil {
+			MarkFlagSensitive(fs, key)
+		}
+	}
+}
+
+func isSecretField(f reflect.StructField) bool {
+	return f.Tag.Get(secretTag) == "true"
+}
+
+// fieldName returns the name of the field under the given tag, and whether
+// the field is squashed into its parent.
+func fieldName(f reflect.StructField, tag string) (string, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "-", false
+	}
+	parts := strings.Split(f.Tag.Get(tag), ",")
+	for _, opt := range parts[1:] {
+		if opt == "squash" || opt == "inline" {
+			return "", true
+		}
+	}
+	if parts[0] != "" {
+		return parts[0], false
+	}
+	if f.Anonymous {
+		return "", true
+	}
+	if tag == "json" {
+		return f.Name, false
+	}
+
+	return strings.ToLower(f.Name), false
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/spf13/pflag"
+	"log"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testDBOptions struct {
+	Host     string `json:"host"     mapstructure:"host"`
+	Password string `json:"password" mapstructure:"password" secret:"true"`
+}
+
+type testEmbedded struct {
+	Token string `json:"token" mapstructure:"token" secret:"true"`
+}
+
+type testOptions struct {
+	testEmbedded `mapstructure:",squash"`
+	DB           *testDBOptions `json:"db"      mapstructure:"db"`
+	APIKey       string         `json:"api-key" mapstructure:"api-key" secret:"true"`
+	Name         string         `json:"name"    mapstructure:"name"`
+	internal     string
+}
+
+func TestSensitiveKeys(t *testing.T) {
+	keys := SensitiveKeys(&testOptions{})
+	var got []string
+	for k := range keys {
+		got = append(got, k)
+	}
+	sort.Strings(got)
+
+	want := []string{"api-key", "db.password", "token"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("SensitiveKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestRedactedJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *testOptions
+		want    map[string]interface{}
+	}{
+		{
+			name:    "secrets set",
+			options: &testOptions{testEmbedded{"t"}, &testDBOptions{"db", "pw"}, "key", "n", "i"},
+			want: map[string]interface{}{
+				"token": RedactedValue, "api-key": RedactedValue, "name": "n",
+				"db": map[string]interface{}{"host": "db", "password": RedactedValue},
+			},
+		},
+		{
+			name:    "empty secrets stay empty",
+			options: &testOptions{DB: &testDBOptions{Host: "db"}},
+			want: map[string]interface{}{
+				"token": "", "api-key": "", "name": "",
+				"db": map[string]interface{}{"host": "db", "password": ""},
+			},
+		},
+		{
+			name:    "nil nested options",
+			options: &testOptions{APIKey: "key"},
+			want:    map[string]interface{}{"token": "", "api-key": RedactedValue, "name": "", "db": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(RedactedJSON(tt.options)), &got); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("RedactedJSON() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestPrintFlagsRedactsSensitiveFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("api-key", "", "")
+	fs.String("db.password", "", "")
+	fs.String("name", "", "")
+	markSensitiveFlags(&testOptions{}, fs)
+	if err := fs.Parse([]string{"--api-key=topsecret", "--name=visible"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	PrintFlags(fs)
+
+	out := buf.String()
+	if strings.Contains(out, "topsecret") {
+		t.Errorf("PrintFlags() leaked a secret: %s", out)
+	}
+	for _, want := range []string{"api-key and value: " + RedactedValue, "name and value: visible", "db.password and value:  has"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PrintFlags() = %q, want it to contain %q", out, want)
+		}
+	}
+}
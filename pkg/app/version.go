@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/gosuri/uitable"
+	my_error "golang-standards-project-example/pkg/errors"
+	"golang-standards-project-example/pkg/version"
+	"gopkg.in/yaml.v3"
+	"os"
+	"time"
+)
+
+// Output formats supported by the version command.
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputShort = "short"
+)
+
+// versionOptions is the options of the version command.
+type versionOptions struct {
+	Output  string
+	Client  bool
+	Server  string
+	Timeout time.Duration
+}
+
+// versions is the output of the version command.
+type versions struct {
+	ClientVersion *version.Info `json:"clientVersion,omitempty" yaml:"clientVersion,omitempty"`
+	ServerVersion *version.Info `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+}
+
+func newVersionOptions() *versionOptions {
+	return &versionOptions{
+		Client:  true,
+		Timeout: 5 * time.Second,
+	}
+}
+
+// Flags returns flags for the version command.
+func (o *versionOptions) Flags() (fss NamedFlagSets) {
+	fs := fss.FlagSet("version")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "One of '', 'json', 'yaml' or 'short'.")
+	fs.BoolVar(&o.Client, "client", o.Client, "Print the client version information.")
+	fs.StringVar(&o.Server, "server", o.Server, ""+
+		"The address of a running server, e.g. http://127.0.0.1:8080. "+
+		"If set, its version information is queried and printed next to the client version.")
+	fs.DurationVar(&o.Timeout, "timeout", o.Timeout, "The timeout of the server version request.")
+
+	return fss
+}
+
+// Validate checks the version command options.
+func (o *versionOptions) Validate() []error {
+	var errs []error
+
+	switch o.Output {
+	case "", outputJSON, outputYAML, outputShort:
+	default:
+		errs = append(errs, fmt.Errorf("--output %q must be one of '', 'json', 'yaml' or 'short'", o.Output))
+	}
+	if !o.Client && o.Server == "" {
+		errs = append(errs, fmt.Errorf("--server must be set when --client=false"))
+	}
+
+	return errs
+}
+
+// newVersionCommand returns the version sub command of the application.
+func newVersionCommand() *Command {
+	opts := newVersionOptions()
+
+	return NewCommand("version", "Print the client and server version information.",
+		WithCommandOptions(opts),
+		WithCommandRunFunc(func(args []string) error {
+			return runVersion(opts)
+		}),
+	)
+}
+
+func runVersion(opts *versionOptions) error {
+	if err := my_error.NewAggregate(opts.Validate()); err != nil {
+		return err
+	}
+
+	var v versions
+	if opts.Client {
+		info := version.Get()
+		v.ClientVersion = &info
+	}
+	if opts.Server != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		info, err := version.GetServerVersion(ctx, nil, opts.Server)
+		if err != nil {
+			return err
+		}
+		v.ServerVersion = info
+	}
+
+	switch opts.Output {
+	case outputJSON:
+		data, err := json.MarshalIndent(v.brief(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case outputYAML:
+		data, err := yaml.Marshal(v.brief())
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+	case outputShort:
+		if v.ClientVersion != nil {
+			fmt.Printf("Client Version: %s\n", v.ClientVersion.GitVersion)
+		}
+		if v.ServerVersion != nil {
+			fmt.Printf("Server Version: %s\n", v.ServerVersion.GitVersion)
+		}
+	default:
+		fmt.Print(v.table())
+	}
+
+	if v.ClientVersion != nil && v.ServerVersion != nil {
+		if err := version.CheckCompatibility(*v.ClientVersion, *v.ServerVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "%s version skew: %v\n", color.YellowString("WARNING:"), err)
+		}
+	}
+
+	return nil
+}
+
+// brief drops the dependency lists and build settings from the output.
+func (v versions) brief() versions {
+	if v.ClientVersion != nil {
+		info := v.ClientVersion.Brief()
+		v.ClientVersion = &info
+	}
+	if v.ServerVersion != nil {
+		info := v.ServerVersion.Brief()
+		v.ServerVersion = &info
+	}
+
+	return v
+}
+
+// table renders the client and server version information side by side.
+func (v versions) table() string {
+	if v.ServerVersion == nil {
+		return v.ClientVersion.String() + "\n"
+	}
+	if v.ClientVersion == nil {
+		return v.ServerVersion.String() + "\n"
+	}
+
+	c, s := v.ClientVersion, v.ServerVersion
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.Separator = "  "
+	table.RightAlign(0)
+	table.AddRow("", "CLIENT", "SERVER")
+	table.AddRow("gitVersion:", c.GitVersion, s.GitVersion)
+	table.AddRow("gitCommit:", c.GitCommit, s.GitCommit)
+	table.AddRow("gitTreeState:", c.GitTreeState, s.GitTreeState)
+	table.AddRow("gitCommitDate:", c.GitCommitDate, s.GitCommitDate)
+	table.AddRow("buildDate:", c.BuildDate, s.BuildDate)
+	table.AddRow("goVersion:", c.GoVersion, s.GoVersion)
+	table.AddRow("compiler:", c.Compiler, s.Compiler)
+	table.AddRow("platform:", c.Platform, s.Platform)
+
+	return table.String() + "\n"
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Bind decodes the request body into obj according to its Content-Type, one
+// of the formats of WriteResponse and JSON if none, and validates obj.
+// YAML and protobuf bodies, unless obj is a proto.Message, are decoded into
+// obj through their JSON form, so json tags apply.
+func Bind(c *gin.Context, obj interface{}) error {
+	format := MIMEJSON
+	if mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err == nil {
+		if f, ok := formatOf(mediaType); ok {
+			format = f
+		}
+	}
+	if format == MIMEJSON {
+		return c.ShouldBindWith(obj, binding.JSON)
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case MIMEYAML:
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		if err := fromGeneric(generic, obj); err != nil {
+			return err
+		}
+	case MIMEProtobuf:
+		if err := unmarshalProto(data, obj); err != nil {
+			return err
+		}
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// IsBodyTooLarge reports whether err is caused by reading a request body
+// beyond the limit of http.MaxBytesReader, and returns the limit.
+func IsBodyTooLarge(err error) (int64, bool) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return tooLarge.Limit, true
+	}
+
+	return 0, false
+}
+
+// IsValidationError reports whether err is caused by the validation of the
+// object Bind decoded, rather than by decoding the body.
+func IsValidationError(err error) bool {
+	var invalid validator.ValidationErrors
+
+	return errors.As(err, &invalid)
+}
+
+// unmarshalProto decodes the protobuf data into obj, see marshalProto.
+func unmarshalProto(data []byte, obj interface{}) error {
+	if m, ok := obj.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	s := &structpb.Struct{}
+	if err := proto.Unmarshal(data, s); err != nil {
+		return err
+	}
+
+	return fromGeneric(s.AsMap(), obj)
+}
+
+// fromGeneric decodes the JSON form generic into obj.
+func fromGeneric(generic interface{}, obj interface{}) error {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, obj)
+}
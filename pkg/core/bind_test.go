@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindUser struct {
+	Name  string `json:"name"  binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+func TestBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		limit       int64
+		wantErr     bool
+		invalid     bool
+		tooLarge    bool
+	}{
+		{name: "json", contentType: MIMEJSON, body: `{"name":"a1"}`},
+		{name: "yaml", contentType: MIMEYAML, body: "name: a1\n"},
+		{name: "malformed json", contentType: MIMEJSON, body: `{"name":`, wantErr: true},
+		{name: "invalid json", contentType: MIMEJSON, body: `{"email":"x"}`, wantErr: true, invalid: true},
+		{name: "invalid yaml", contentType: MIMEYAML, body: "email: x\n", wantErr: true, invalid: true},
+		{name: "too large", contentType: MIMEYAML, body: "name: a1\n", limit: 4, wantErr: true, tooLarge: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			if tt.limit > 0 {
+				c.Request.Body = http.MaxBytesReader(w, c.Request.Body, tt.limit)
+			}
+
+			err := Bind(c, &bindUser{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := IsValidationError(err); got != tt.invalid {
+				t.Errorf("IsValidationError() = %v, want %v", got, tt.invalid)
+			}
+			if _, got := IsBodyTooLarge(err); got != tt.tooLarge {
+				t.Errorf("IsBodyTooLarge() = %v, want %v", got, tt.tooLarge)
+			}
+		})
+	}
+}
+
+func TestIsBodyTooLarge(t *testing.T) {
+	tooLarge := &http.MaxBytesError{Limit: 10}
+	tests := []struct {
+		name  string
+		err   error
+		limit int64
+		ok    bool
+	}{
+		{"nil", nil, 0, false},
+		{"other", io.ErrUnexpectedEOF, 0, false},
+		{"too large", tooLarge, 10, true},
+		{"wrapped", fmt.Errorf("read row: %w", tooLarge), 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, ok := IsBodyTooLarge(tt.err)
+			if limit != tt.limit || ok != tt.ok {
+				t.Errorf("IsBodyTooLarge() = %d, %v, want %d, %v", limit, ok, tt.limit, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"net/http"
+	"strings"
+)
+
+// ETag returns the strong entity tag of the resource version.
+func ETag(resourceVersion string) string {
+	return `"` + resourceVersion + `"`
+}
+
+// SetETag sets the ETag header of the response to the entity tag of the
+// resource version.
+func SetETag(c *gin.Context, resourceVersion string) {
+	if resourceVersion != "" {
+		c.Header("ETag", ETag(resourceVersion))
+	}
+}
+
+// NotModified reports whether the If-None-Match header of the request
+// matches the resource version and, if so, writes 304 Not Modified.
+func NotModified(c *gin.Context, resourceVersion string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	// If-None-Match uses the weak comparison
+	for _, tag := range entityTags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == ETag(resourceVersion) {
+			SetETag(c, resourceVersion)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Preconditions returns the preconditions of the If-Match header of the
+// request, nil if it has none. "*" only requires the resource to exist.
+func Preconditions(c *gin.Context) *metav1.Preconditions {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+
+	p := &metav1.Preconditions{}
+	for _, tag := range entityTags(header) {
+		if tag == "*" {
+			return &metav1.Preconditions{}
+		}
+		// If-Match uses the strong comparison: weak tags are kept as they
+		// are, so they never match a resource version
+		if len(tag) >= 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`) {
+			tag = tag[1 : len(tag)-1]
+		}
+		p.ResourceVersions = append(p.ResourceVersions, tag)
+	}
+
+	return p
+}
+
+// entityTags splits the list of entity tags of a conditional header.
+func entityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,19 @@
+package core
+
+import "context"
+
+// traceIDContextKey is the key of the trace ID in request contexts.
+type traceIDContextKey struct{}
+
+// WithTraceID returns a copy of ctx carrying the trace ID of the request,
+// it is reported in the error responses.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceIDFrom returns the trace ID of the request of ctx, empty if it is unknown.
+func TraceIDFrom(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDContextKey{}).(string)
+
+	return traceID
+}
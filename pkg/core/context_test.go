@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewErrResponseTraceID(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"without trace", context.Background(), ""},
+		{"with trace", WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736"), "4bf92f3577b34da6a3ce929d0e0e4736"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil).WithContext(tt.ctx)
+
+			if got := NewErrResponse(c, errors.New("failed")).TraceID; got != tt.want {
+				t.Errorf("TraceID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,23 +20,51 @@ type ErrResponse struct {
 
 	// Reference returns the reference document which maybe useful to solve this error.
 	Reference string `json:"reference,omitempty"`
+
+	// TraceID is the ID of the trace of the request, to correlate the error with logs and spans.
+	TraceID string `json:"traceID,omitempty"`
+}
+
+// NewErrResponse returns the ErrResponse of the error of the request.
+func NewErrResponse(c *gin.Context, err error) ErrResponse {
+	coder := errors.ParseCoder(err)
+
+	return ErrResponse{
+		Code:      coder.Code(),
+		Message:   coder.String(),
+		Reference: coder.Reference(),
+		TraceID:   TraceIDFrom(c.Request.Context()),
+	}
 }
 
 // WriteResponse write an error or the response data into http response body.
 // It use errors.ParseCoder to parse any error into errors.Coder
 // errors.Coder contains error code, user-safe error message and http status code.
+// The body is encoded as JSON, YAML or protobuf, see NegotiateFormat.
 func WriteResponse(c *gin.Context, err error, data interface{}) {
+	writeResponse(c, http.StatusOK, err, data)
+}
+
+// WriteCreated writes the data of the resource the request created with
+// 201 Created and the URL of the resource in the Location header.
+func WriteCreated(c *gin.Context, location string, data interface{}) {
+	c.Header("Location", location)
+	writeResponse(c, http.StatusCreated, nil, data)
+}
+
+// writeResponse writes the error or, if err is nil, the data with status.
+func writeResponse(c *gin.Context, status int, err error, data interface{}) {
 	if err != nil {
 		log.Printf("%#+v\n", err)
-		coder := errors.ParseCoder(err)
-		c.JSON(coder.HTTPStatus(), ErrResponse{
-			Code:      coder.Code(),
-			Message:   coder.String(),
-			Reference: coder.Reference(),
-		})
-
-		return
+		status, data = errors.ParseCoder(err).HTTPStatus(), NewErrResponse(c, err)
 	}
 
-	c.JSON(http.StatusOK, data)
+	if err := render(c, status, data); err != nil {
+		log.Printf("encode response: %v\n", err)
+		c.JSON(http.StatusInternalServerError, ErrResponse{
+			Code:    errors.GetCoder(0).Code(),
+			Message: errors.GetCoder(0).String(),
+			TraceID: TraceIDFrom(c.Request.Context()),
+		})
+	}
 }
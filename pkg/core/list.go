@@ -0,0 +1,91 @@
+package core
+
+import (
+	"github.com/gin-gonic/gin"
+	metav1 "golang-standards-project-example/pkg/meta/v1"
+	"reflect"
+	"strings"
+)
+
+// ListResponse is the envelope of list responses.
+type ListResponse struct {
+	// TotalCount is the number of items matching the selectors, on all pages.
+	TotalCount int64 `json:"totalCount"`
+	// Items are the items of this page.
+	Items []interface{} `json:"items"`
+	// NextCursor continues the list after this page, empty if it is the last.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// WriteList writes an error or a page of items, which must be a slice, in
+// a ListResponse, see WriteResponse. If fields is not empty only these fields
+// of the items are written, nested fields by their dotted path, e.g.
+// "metadata.name".
+func WriteList(c *gin.Context, err error, list metav1.ListMeta, items interface{}, fields []string) {
+	if err != nil {
+		WriteResponse(c, err, nil)
+		return
+	}
+
+	resp := ListResponse{
+		TotalCount: list.TotalCount,
+		Items:      []interface{}{},
+		NextCursor: list.NextCursor,
+	}
+	v := reflect.ValueOf(items)
+	for i := 0; v.Kind() == reflect.Slice && i < v.Len(); i++ {
+		item := v.Index(i).Interface()
+		if len(fields) > 0 {
+			if item, err = selectFields(item, fields); err != nil {
+				WriteResponse(c, err, nil)
+				return
+			}
+		}
+		resp.Items = append(resp.Items, item)
+	}
+
+	WriteResponse(c, nil, resp)
+}
+
+// selectFields returns the JSON form of obj restricted to the fields.
+func selectFields(obj interface{}, fields []string) (interface{}, error) {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := generic.(map[string]interface{})
+	if !ok {
+		return generic, nil
+	}
+
+	selected := map[string]interface{}{}
+	for _, f := range fields {
+		copyField(selected, m, strings.Split(f, "."))
+	}
+
+	return selected, nil
+}
+
+// copyField copies the field at path from src to dst, creating the objects
+// on the path in dst.
+func copyField(dst, src map[string]interface{}, path []string) {
+	v, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = v
+		return
+	}
+
+	next, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	sub, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		sub = map[string]interface{}{}
+		dst[path[0]] = sub
+	}
+	copyField(sub, next, path[1:])
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types of the response formats.
+const (
+	MIMEJSON     = "application/json"
+	MIMEYAML     = "application/yaml"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// formats maps the accepted media types to the response formats.
+var formats = map[string]string{
+	MIMEJSON:                          MIMEJSON,
+	"text/json":                       MIMEJSON,
+	MIMEYAML:                          MIMEYAML,
+	"application/x-yaml":              MIMEYAML,
+	"text/yaml":                       MIMEYAML,
+	MIMEProtobuf:                      MIMEProtobuf,
+	"application/protobuf":            MIMEProtobuf,
+	"application/vnd.google.protobuf": MIMEProtobuf,
+}
+
+// NegotiateFormat returns the response format preferred by the Accept
+// header, honouring quality values. Structured syntax suffixes, e.g.
+// "application/vnd.user.v2+json", select their format. JSON is returned if
+// the header is empty or accepts none of the formats.
+func NegotiateFormat(accept string) string {
+	format, best := MIMEJSON, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= best {
+			continue
+		}
+
+		f, ok := formatOf(mediaType)
+		if !ok {
+			switch mediaType {
+			case "*/*", "application/*":
+				f = MIMEJSON
+			default:
+				continue
+			}
+		}
+		format, best = f, q
+	}
+
+	return format
+}
+
+// formatOf returns the format of the media type, including media types with
+// a structured syntax suffix, e.g. "application/vnd.user.v2+json".
+func formatOf(mediaType string) (string, bool) {
+	if f, ok := formats[mediaType]; ok {
+		return f, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return MIMEJSON, true
+	case strings.HasSuffix(mediaType, "+yaml"):
+		return MIMEYAML, true
+	}
+
+	return "", false
+}
+
+// render writes obj with the status in the format negotiated by the Accept
+// header of the request.
+//
+// YAML is encoded from the JSON form of obj, so json tags apply. Protobuf
+// encodes obj directly if it is a proto.Message and as a google.protobuf.Struct
+// (or google.protobuf.Value if it is not an object) of its JSON form otherwise.
+func render(c *gin.Context, status int, obj interface{}) error {
+	format := NegotiateFormat(c.GetHeader("Accept"))
+	if !varies(c.Writer.Header(), "Accept") {
+		c.Writer.Header().Add("Vary", "Accept")
+	}
+	if format == MIMEJSON {
+		c.JSON(status, obj)
+		return nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case MIMEYAML:
+		var generic interface{}
+		if generic, err = toGeneric(obj); err == nil {
+			data, err = yaml.Marshal(generic)
+		}
+		format += "; charset=utf-8"
+	case MIMEProtobuf:
+		data, err = marshalProto(obj)
+	}
+	if err != nil {
+		return err
+	}
+	c.Data(status, format, data)
+
+	return nil
+}
+
+// varies reports whether the Vary header lists the request header.
+func varies(h http.Header, header string) bool {
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), header) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// marshalProto encodes obj in the protobuf wire format.
+func marshalProto(obj interface{}) ([]byte, error) {
+	if m, ok := obj.(proto.Message); ok {
+		return proto.Marshal(m)
+	}
+
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := generic.(map[string]interface{}); ok {
+		s, err := structpb.NewStruct(m)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(s)
+	}
+	v, err := structpb.NewValue(generic)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(v)
+}
+
+// toGeneric returns the JSON form of obj as maps, slices and scalars.
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	return generic, nil
+}
@@ -117,6 +117,19 @@ func ParseCoder(err error) Coder {
 	return unknownCoder
 }
 
+// GetCoder returns the coder registered for the given code.
+// Unregistered codes return the ErrUnknown coder.
+func GetCoder(code int) Coder {
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if coder, ok := codes[code]; ok {
+		return coder
+	}
+
+	return unknownCoder
+}
+
 // IsCode reports whether any error in err's chain contains the given error code.
 func IsCode(err error, code int) bool {
 	if v, ok := err.(*withCode); ok {
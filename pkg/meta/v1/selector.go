@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator is the operator of a selector requirement.
+type Operator string
+
+// Selector operators.
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Set is a set of keys and values, e.g. labels or fields, a selector matches.
+type Set map[string]string
+
+// Requirement is a condition on the value of a key.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Value    string
+}
+
+// Matches reports whether the set meets the requirement.
+func (r Requirement) Matches(set Set) bool {
+	v, ok := set[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && v == r.Value
+	case NotEquals:
+		return !ok || v != r.Value
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+
+	return false
+}
+
+// Selector is a conjunction of requirements; the empty selector matches everything.
+type Selector []Requirement
+
+// Matches reports whether the set meets all requirements.
+func (s Selector) Matches(set Set) bool {
+	for _, r := range s {
+		if !r.Matches(set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseLabelSelector parses a comma separated list of "key=value",
+// "key==value", "key!=value", "key" (exists) and "!key" (does not exist).
+func ParseLabelSelector(s string) (Selector, error) {
+	return parseSelector(s, true)
+}
+
+// ParseFieldSelector parses a comma separated list of "key=value",
+// "key==value" and "key!=value".
+func ParseFieldSelector(s string) (Selector, error) {
+	return parseSelector(s, false)
+}
+
+func parseSelector(s string, existence bool) (Selector, error) {
+	var sel Selector
+	for _, term := range strings.Split(s, ",") {
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+
+		var r Requirement
+		switch {
+		case strings.Contains(term, "!="):
+			r.Key, r.Value, _ = strings.Cut(term, "!=")
+			r.Operator = NotEquals
+		case strings.Contains(term, "=="):
+			r.Key, r.Value, _ = strings.Cut(term, "==")
+			r.Operator = Equals
+		case strings.Contains(term, "="):
+			r.Key, r.Value, _ = strings.Cut(term, "=")
+			r.Operator = Equals
+		case !existence:
+			return nil, fmt.Errorf("invalid requirement %q, expected key=value or key!=value", term)
+		case strings.HasPrefix(term, "!"):
+			r.Key, r.Operator = term[1:], DoesNotExist
+		default:
+			r.Key, r.Operator = term, Exists
+		}
+		r.Key, r.Value = strings.TrimSpace(r.Key), strings.TrimSpace(r.Value)
+		if r.Key == "" {
+			return nil, fmt.Errorf("invalid requirement %q, empty key", term)
+		}
+		sel = append(sel, r)
+	}
+
+	return sel, nil
+}
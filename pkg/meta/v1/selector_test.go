@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Selector
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "env=prod", want: Selector{{Key: "env", Operator: Equals, Value: "prod"}}},
+		{in: "env==prod", want: Selector{{Key: "env", Operator: Equals, Value: "prod"}}},
+		{in: " env != prod ", want: Selector{{Key: "env", Operator: NotEquals, Value: "prod"}}},
+		{in: "canary", want: Selector{{Key: "canary", Operator: Exists}}},
+		{in: "!legacy", want: Selector{{Key: "legacy", Operator: DoesNotExist}}},
+		{in: "env=", want: Selector{{Key: "env", Operator: Equals}}},
+		{in: "env=prod,,tier!=db,canary,!legacy", want: Selector{
+			{Key: "env", Operator: Equals, Value: "prod"},
+			{Key: "tier", Operator: NotEquals, Value: "db"},
+			{Key: "canary", Operator: Exists},
+			{Key: "legacy", Operator: DoesNotExist},
+		}},
+		{in: "=prod", wantErr: true},
+		{in: "!", wantErr: true},
+		{in: "!=db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLabelSelector(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabelSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabelSelector() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldSelector(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Selector
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "email=a@b.com", want: Selector{{Key: "email", Operator: Equals, Value: "a@b.com"}}},
+		{in: "metadata.name!=admin,nickname==a1", want: Selector{
+			{Key: "metadata.name", Operator: NotEquals, Value: "admin"},
+			{Key: "nickname", Operator: Equals, Value: "a1"},
+		}},
+		{in: "email", wantErr: true},
+		{in: "!email", wantErr: true},
+		{in: " =a1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFieldSelector(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFieldSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFieldSelector() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	set := Set{"env": "prod", "tier": "web"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env=dev", false},
+		{"env!=dev", true},
+		{"env!=prod", false},
+		{"region!=eu", true},
+		{"tier", true},
+		{"region", false},
+		{"!region", true},
+		{"!tier", false},
+		{"env=prod,tier=web", true},
+		{"env=prod,tier=db", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sel.Matches(set); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SortField is a field to sort a list by.
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// ParseSort parses a comma separated list of fields, each descending if
+// prefixed with "-" and ascending otherwise, optionally prefixed with "+".
+func ParseSort(s string) ([]SortField, error) {
+	var fields []SortField
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		var field SortField
+		switch f[0] {
+		case '-':
+			field = SortField{Key: f[1:], Desc: true}
+		case '+':
+			field = SortField{Key: f[1:]}
+		default:
+			field = SortField{Key: f}
+		}
+		if field.Key == "" {
+			return nil, fmt.Errorf("invalid sort field %q", f)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Cursor is the position of the last item of a page: its sort key values.
+type Cursor struct {
+	// SortBy is the ListOptions.SortBy of the list, a cursor only continues the same order.
+	SortBy string `json:"s,omitempty"`
+	// Values are the values of the sort fields and the name of the item.
+	Values []string `json:"v"`
+}
+
+// Encode returns the opaque form of the cursor used in ListMeta.NextCursor.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes an encoded cursor, nil if s is empty.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil || len(c.Values) == 0 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	return c, nil
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []SortField
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "email", want: []SortField{{Key: "email"}}},
+		{in: "+email", want: []SortField{{Key: "email"}}},
+		{in: "-metadata.createdAt, nickname", want: []SortField{
+			{Key: "metadata.createdAt", Desc: true},
+			{Key: "nickname"},
+		}},
+		{in: "-", wantErr: true},
+		{in: "email,+", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSort(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSort() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cursors := []*Cursor{
+		{Values: []string{"a1"}},
+		{SortBy: "-email", Values: []string{"a@b.com", "a1"}},
+		{SortBy: "nickname", Values: []string{"", "name with spaces/and?query"}},
+	}
+	for _, c := range cursors {
+		encoded := c.Encode()
+		if _, err := base64.RawURLEncoding.DecodeString(encoded); err != nil {
+			t.Errorf("Encode() = %q is not URL safe: %v", encoded, err)
+		}
+		got, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) error = %v", encoded, err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("DecodeCursor(Encode()) = %+v, want %+v", got, c)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Cursor
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "not base64", in: "%%%", wantErr: true},
+		{name: "padded", in: base64.URLEncoding.EncodeToString([]byte(`{"v":["a12"]}`)), wantErr: true},
+		{name: "not json", in: base64.RawURLEncoding.EncodeToString([]byte("a1")), wantErr: true},
+		{name: "no values", in: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"email"}`)), wantErr: true},
+		{name: "valid", in: base64.RawURLEncoding.EncodeToString([]byte(`{"v":["a1"]}`)), want: &Cursor{Values: []string{"a1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeCursor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
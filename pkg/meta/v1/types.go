@@ -0,0 +1,152 @@
+// Package v1 contains the metadata and list options shared by all API resources.
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// List limits.
+const (
+	// DefaultListLimit is the page size if ListOptions.Limit is not set.
+	DefaultListLimit = 100
+	// MaxListLimit is the largest page size.
+	MaxListLimit = 1000
+)
+
+// ObjectMeta is the metadata every resource has.
+type ObjectMeta struct {
+	// ID is the unique identifier of the resource, assigned by the store.
+	ID uint64 `json:"id,omitempty"`
+	// Name identifies the resource, unique within its kind.
+	Name string `json:"name" validate:"required,min=1,max=64"`
+	// Labels are key/value pairs to organize and select resources.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CreatedAt is the time the resource was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time the resource was last modified.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// UpdatedBy is the user who made the last modification, empty if unknown.
+	UpdatedBy string `json:"updatedBy,omitempty"`
+	// DeletedAt is the time the resource was deleted. Deleted resources can
+	// be restored until they are purged.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// ResourceVersion changes whenever the resource is modified; it is the
+	// entity tag of the resource for conditional requests.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Preconditions must be fulfilled before an update or delete is carried out.
+type Preconditions struct {
+	// ResourceVersions are the versions the resource must have, any if empty,
+	// which still requires the resource to exist.
+	ResourceVersions []string
+}
+
+// Check returns an error if the resource version does not fulfill the preconditions.
+func (p *Preconditions) Check(resourceVersion string) error {
+	if p == nil || len(p.ResourceVersions) == 0 {
+		return nil
+	}
+	for _, rv := range p.ResourceVersions {
+		if rv == resourceVersion {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("resource version %s does not match %s", resourceVersion, strings.Join(p.ResourceVersions, ", "))
+}
+
+// ListMeta describes the page of a list.
+type ListMeta struct {
+	// TotalCount is the number of resources matching the selectors.
+	TotalCount int64 `json:"totalCount"`
+	// NextCursor continues the list after this page, empty if it is the last.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListOptions are the query parameters of list requests.
+type ListOptions struct {
+	// LabelSelector selects resources by their labels, e.g. "env=prod,tier!=db,canary,!legacy".
+	LabelSelector string `json:"labelSelector,omitempty" form:"labelSelector"`
+	// FieldSelector selects resources by their fields, e.g. "email=a@b.com,metadata.name!=admin".
+	FieldSelector string `json:"fieldSelector,omitempty" form:"fieldSelector"`
+	// SortBy is a comma separated list of fields to sort by, descending if prefixed
+	// with "-", e.g. "-metadata.createdAt". Ties are sorted by name.
+	SortBy string `json:"sortBy,omitempty" form:"sortBy"`
+	// Offset is the number of resources to skip, not allowed with Cursor.
+	Offset int64 `json:"offset,omitempty" form:"offset" validate:"omitempty,min=0"`
+	// Limit is the page size, DefaultListLimit if zero.
+	Limit int64 `json:"limit,omitempty" form:"limit" validate:"omitempty,min=0,max=1000"`
+	// Cursor is the NextCursor of the previous page.
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+	// Fields is a comma separated list of the fields of the items to return,
+	// e.g. "metadata.name,email". All fields if empty.
+	Fields string `json:"fields,omitempty" form:"fields"`
+	// Watch streams the changes of the selected resources instead of listing them.
+	Watch bool `json:"watch,omitempty" form:"watch"`
+	// ResourceVersion is the version after which a watch starts. If empty the
+	// watch starts with the resources as they are, each as an added event.
+	ResourceVersion string `json:"resourceVersion,omitempty" form:"resourceVersion"`
+}
+
+// Validate checks the list options are well-formed.
+func (o *ListOptions) Validate() error {
+	if o.Offset < 0 {
+		return fmt.Errorf("offset %d must not be negative", o.Offset)
+	}
+	if o.Limit < 0 || o.Limit > MaxListLimit {
+		return fmt.Errorf("limit %d must be between 0 and %d", o.Limit, MaxListLimit)
+	}
+	if o.Offset > 0 && o.Cursor != "" {
+		return fmt.Errorf("offset and cursor are mutually exclusive")
+	}
+	if _, err := ParseLabelSelector(o.LabelSelector); err != nil {
+		return fmt.Errorf("labelSelector: %w", err)
+	}
+	if _, err := ParseFieldSelector(o.FieldSelector); err != nil {
+		return fmt.Errorf("fieldSelector: %w", err)
+	}
+	if _, err := ParseSort(o.SortBy); err != nil {
+		return fmt.Errorf("sortBy: %w", err)
+	}
+	if _, err := DecodeCursor(o.Cursor); err != nil {
+		return fmt.Errorf("cursor: %w", err)
+	}
+	if o.ResourceVersion != "" {
+		if !o.Watch {
+			return fmt.Errorf("resourceVersion requires watch")
+		}
+		if _, err := strconv.ParseUint(o.ResourceVersion, 10, 64); err != nil {
+			return fmt.Errorf("resourceVersion %q is not a resource version", o.ResourceVersion)
+		}
+	}
+	if o.Watch && (o.SortBy != "" || o.Offset > 0 || o.Limit > 0 || o.Cursor != "" || o.Fields != "") {
+		return fmt.Errorf("watch only supports labelSelector, fieldSelector and resourceVersion")
+	}
+
+	return nil
+}
+
+// PageSize returns Limit, or DefaultListLimit if it is not set.
+func (o *ListOptions) PageSize() int64 {
+	if o.Limit <= 0 {
+		return DefaultListLimit
+	}
+
+	return o.Limit
+}
+
+// FieldSet returns the fields of Fields, nil for all fields.
+func (o *ListOptions) FieldSet() []string {
+	var fields []string
+	for _, f := range strings.Split(o.Fields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,33 @@
+package v1
+
+import "testing"
+
+func TestListOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ListOptions
+		wantErr bool
+	}{
+		{name: "empty", opts: ListOptions{}},
+		{name: "page", opts: ListOptions{LabelSelector: "env=prod", SortBy: "-email", Limit: 10, Offset: 20}},
+		{name: "cursor", opts: ListOptions{Cursor: (&Cursor{Values: []string{"a1"}}).Encode()}},
+		{name: "watch", opts: ListOptions{Watch: true, FieldSelector: "nickname=a1", ResourceVersion: "12"}},
+		{name: "negative offset", opts: ListOptions{Offset: -1}, wantErr: true},
+		{name: "limit too large", opts: ListOptions{Limit: MaxListLimit + 1}, wantErr: true},
+		{name: "offset and cursor", opts: ListOptions{Offset: 1, Cursor: (&Cursor{Values: []string{"a1"}}).Encode()}, wantErr: true},
+		{name: "bad label selector", opts: ListOptions{LabelSelector: "=prod"}, wantErr: true},
+		{name: "bad field selector", opts: ListOptions{FieldSelector: "email"}, wantErr: true},
+		{name: "bad sort", opts: ListOptions{SortBy: "-"}, wantErr: true},
+		{name: "bad cursor", opts: ListOptions{Cursor: "%%%"}, wantErr: true},
+		{name: "resource version without watch", opts: ListOptions{ResourceVersion: "12"}, wantErr: true},
+		{name: "bad resource version", opts: ListOptions{Watch: true, ResourceVersion: "x"}, wantErr: true},
+		{name: "watch with sort", opts: ListOptions{Watch: true, SortBy: "email"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
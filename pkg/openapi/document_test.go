@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPathItemSet(t *testing.T) {
+	tests := []struct {
+		method string
+		get    func(p *PathItem) *OperationObject
+	}{
+		{method: http.MethodGet, get: func(p *PathItem) *OperationObject { return p.Get }},
+		{method: http.MethodPut, get: func(p *PathItem) *OperationObject { return p.Put }},
+		{method: http.MethodPost, get: func(p *PathItem) *OperationObject { return p.Post }},
+		{method: http.MethodDelete, get: func(p *PathItem) *OperationObject { return p.Delete }},
+		{method: http.MethodOptions, get: func(p *PathItem) *OperationObject { return p.Options }},
+		{method: http.MethodHead, get: func(p *PathItem) *OperationObject { return p.Head }},
+		{method: http.MethodPatch, get: func(p *PathItem) *OperationObject { return p.Patch }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			item, op := &PathItem{}, &OperationObject{}
+			item.set(tt.method, op)
+			if tt.get(item) != op {
+				t.Errorf("set(%s) did not set the operation", tt.method)
+			}
+		})
+	}
+}
+
+func TestDocumentJSON(t *testing.T) {
+	min := 1.0
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "reference",
+			v:    &Schema{Ref: componentRefPrefix + "User"},
+			want: `{"$ref":"#/components/schemas/User"}`,
+		},
+		{
+			name: "constraints",
+			v:    &Schema{Type: "integer", Minimum: &min, ExclusiveMinimum: true},
+			want: `{"type":"integer","minimum":1,"exclusiveMinimum":true}`,
+		},
+		{
+			name: "any value",
+			v:    &Schema{},
+			want: `{}`,
+		},
+		{
+			name: "operation without responses",
+			v:    &OperationObject{Summary: "list"},
+			want: `{"summary":"list","responses":null}`,
+		},
+		{
+			name: "empty document",
+			v:    &Document{OpenAPI: Version, Info: Info{Title: "api", Version: "v1"}, Paths: map[string]*PathItem{}},
+			want: `{"openapi":"3.0.3","info":{"title":"api","version":"v1"},"paths":{},"components":{}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
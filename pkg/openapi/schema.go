@@ -0,0 +1,302 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// componentRefPrefix prefixes the references to component schemas.
+const componentRefPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+	// invalidNameChars are the characters not allowed in component names.
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// schemas generates the schemas of Go types. Named struct types become
+// components which are referenced by $ref.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns the schema of the type of v, nil if v is nil.
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	return s.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of t.
+func (s *schemas) schema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds", Nullable: nullable}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float", Nullable: nullable}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem()), Nullable: nullable}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem()), Nullable: nullable}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: componentRefPrefix + s.component(t)}
+	default:
+		// interfaces, functions and channels accept any value
+		return &Schema{}
+	}
+}
+
+// component registers the named struct type t as a component schema and
+// returns its name.
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := invalidNameChars.ReplaceAllString(t.Name(), "_")
+	if _, taken := s.components[name]; taken {
+		name = invalidNameChars.ReplaceAllString(path.Base(t.PkgPath())+"."+t.Name(), "_")
+	}
+	for i := 2; s.components[name] != nil; i++ {
+		name = invalidNameChars.ReplaceAllString(t.Name(), "_") + strconv.Itoa(i)
+	}
+
+	// register the name first, so recursive types refer to themselves
+	s.names[t] = name
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+
+	return name
+}
+
+// object returns the inline object schema of the struct type t.
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(obj, t)
+
+	return obj
+}
+
+// addFields adds the exported fields of the struct type t to obj, flattening
+// embedded structs like encoding/json does.
+func (s *schemas) addFields(obj *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(obj, ft)
+				continue
+			}
+			name = f.Name
+		}
+
+		prop := s.schema(f.Type)
+		obj.Properties[name] = prop
+		if applyRules(prop, fieldRules(f)) {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+// jsonName returns the JSON name of the field, empty for embedded structs
+// without a name, and whether the field is encoded at all.
+func jsonName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" && !f.Anonymous {
+		name = f.Name
+	}
+
+	return name, true
+}
+
+// fieldRules returns the validation rules of the field, taken from the
+// validate tag or, for gin bindings, the binding tag.
+func fieldRules(f reflect.StructField) []string {
+	tag, ok := f.Tag.Lookup("validate")
+	if !ok {
+		tag = f.Tag.Get("binding")
+	}
+	if tag == "" || tag == "-" {
+		return nil
+	}
+
+	return strings.Split(tag, ",")
+}
+
+// applyRules applies the validation rules, in go-playground/validator
+// syntax, as constraints to the schema and reports whether the field is
+// required. Rules without a schema equivalent are ignored.
+func applyRules(s *Schema, rules []string) bool {
+	required := false
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if s.Items != nil {
+				applyRules(s.Items, rules[i+1:])
+			} else if s.AdditionalProperties != nil {
+				applyRules(s.AdditionalProperties, rules[i+1:])
+			}
+			return required
+		case "min", "gte":
+			setMinimum(s, param, false)
+		case "max", "lte":
+			setMaximum(s, param, false)
+		case "gt":
+			setMinimum(s, param, true)
+		case "lt":
+			setMaximum(s, param, true)
+		case "len", "eq":
+			setMinimum(s, param, false)
+			setMaximum(s, param, false)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(s, v))
+			}
+		case "email":
+			s.Format = "email"
+		case "url", "uri", "http_url":
+			s.Format = "uri"
+		case "uuid", "uuid3", "uuid4", "uuid5":
+			s.Format = "uuid"
+		case "ipv4", "ipv6", "hostname":
+			s.Format = name
+		case "ip":
+			s.Format = "ip"
+		case "datetime":
+			s.Format = "date-time"
+		}
+	}
+
+	return required
+}
+
+// setMinimum sets the lower bound matching the schema type: the length of
+// strings, the number of items of arrays or the value of numbers.
+func setMinimum(s *Schema, param string, exclusive bool) {
+	switch s.Type {
+	case "string", "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if exclusive {
+			n++
+		}
+		if s.Type == "string" {
+			s.MinLength = &n
+		} else {
+			s.MinItems = &n
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		s.Minimum, s.ExclusiveMinimum = &v, exclusive
+	}
+}
+
+// setMaximum sets the upper bound matching the schema type, see setMinimum.
+func setMaximum(s *Schema, param string, exclusive bool) {
+	switch s.Type {
+	case "string", "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if exclusive {
+			n--
+		}
+		if s.Type == "string" {
+			s.MaxLength = &n
+		} else {
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		s.Maximum, s.ExclusiveMaximum = &v, exclusive
+	}
+}
+
+// enumValue converts the oneof value to the schema type.
+func enumValue(s *Schema, v string) interface{} {
+	switch s.Type {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ruleUser struct {
+	Name     string            `json:"name"               validate:"required,min=1,max=30"`
+	Email    string            `json:"email"              validate:"required,email"`
+	Age      int               `json:"age,omitempty"      validate:"gte=0,lt=150"`
+	Role     string            `json:"role"               validate:"oneof=admin user"`
+	Tags     []string          `json:"tags"               validate:"max=5,dive,min=2"`
+	Labels   map[string]string `json:"labels"             validate:"dive,max=10"`
+	Homepage string            `json:"homepage"           binding:"url"`
+	Note     string            `json:"note"               validate:"-"`
+	Created  time.Time         `json:"created"`
+	Timeout  *time.Duration    `json:"timeout"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestSchemaRules(t *testing.T) {
+	gen := newSchemas()
+	ref := gen.of(ruleUser{})
+	if ref.Ref != componentRefPrefix+"ruleUser" {
+		t.Fatalf("of() = %+v, want a reference to ruleUser", ref)
+	}
+	obj := gen.components["ruleUser"]
+
+	tests := []struct {
+		field string
+		want  *Schema
+	}{
+		{field: "name", want: &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(30)}},
+		{field: "email", want: &Schema{Type: "string", Format: "email"}},
+		{field: "age", want: &Schema{
+			Type: "integer", Format: "int64",
+			Minimum: floatPtr(0), Maximum: floatPtr(150), ExclusiveMaximum: true,
+		}},
+		{field: "role", want: &Schema{Type: "string", Enum: []interface{}{"admin", "user"}}},
+		{field: "tags", want: &Schema{Type: "array", MaxItems: intPtr(5), Items: &Schema{Type: "string", MinLength: intPtr(2)}}},
+		{field: "labels", want: &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string", MaxLength: intPtr(10)}}},
+		{field: "homepage", want: &Schema{Type: "string", Format: "uri"}},
+		{field: "note", want: &Schema{Type: "string"}},
+		{field: "created", want: &Schema{Type: "string", Format: "date-time"}},
+		{field: "timeout", want: &Schema{Type: "integer", Format: "int64", Description: "nanoseconds", Nullable: true}},
+		{field: "-"},
+		{field: "Ignored"},
+		{field: "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got := obj.Properties[tt.field]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("property %s = %+v, want %+v", tt.field, got, tt.want)
+			}
+		})
+	}
+
+	if want := []string{"name", "email"}; !reflect.DeepEqual(obj.Required, want) {
+		t.Errorf("required = %v, want %v", obj.Required, want)
+	}
+}
+
+type ruleBase struct {
+	ID string `json:"id"`
+}
+
+type ruleNode struct {
+	ruleBase
+	Children []ruleNode `json:"children"`
+	Parent   *ruleNode  `json:"parent"`
+	Inline   struct {
+		Value float32 `json:"value" validate:"gt=0"`
+	} `json:"inline"`
+}
+
+func TestSchemaComponents(t *testing.T) {
+	gen := newSchemas()
+	gen.of(&ruleNode{})
+	node := gen.components["ruleNode"]
+	if node == nil {
+		t.Fatalf("components = %v, want ruleNode", gen.components)
+	}
+
+	tests := []struct {
+		field string
+		want  *Schema
+	}{
+		{field: "id", want: &Schema{Type: "string"}},
+		{field: "children", want: &Schema{Type: "array", Items: &Schema{Ref: componentRefPrefix + "ruleNode"}}},
+		{field: "parent", want: &Schema{Ref: componentRefPrefix + "ruleNode"}},
+		{field: "inline", want: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"value": {Type: "number", Format: "float", Minimum: floatPtr(0), ExclusiveMinimum: true},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := node.Properties[tt.field]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("property %s = %+v, want %+v", tt.field, got, tt.want)
+			}
+		})
+	}
+	if _, ok := gen.components["ruleBase"]; ok {
+		t.Error("embedded struct registered as a component")
+	}
+}
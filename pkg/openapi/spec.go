@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// jsonContentType is the media type of request and response bodies.
+const jsonContentType = "application/json"
+
+// Operation documents the route registered for a method and path.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Query is a struct whose fields tagged with `form` are the query parameters.
+	Query interface{}
+	// Request is the model of the JSON request body, nil if there is none.
+	Request interface{}
+	// Status is the HTTP status of a successful response, 200 if zero.
+	Status int
+	// Response is the model of the JSON response body of a successful request.
+	Response interface{}
+	// Codes are the registered error codes the operation may respond with.
+	// The unknown error code is always documented.
+	Codes      []int
+	Deprecated bool
+	// Hidden excludes the route from the document.
+	Hidden bool
+}
+
+// Spec collects the descriptions of the routes of a server and builds its
+// OpenAPI document. Initialize with NewSpec.
+type Spec struct {
+	Info Info
+
+	mu         sync.Mutex
+	operations map[string]Operation
+	routes     gin.RoutesInfo
+}
+
+// NewSpec returns a Spec with the given API metadata.
+func NewSpec(info Info) *Spec {
+	return &Spec{
+		Info:       info,
+		operations: map[string]Operation{},
+	}
+}
+
+// Describe documents the route registered for method and path, in gin
+// syntax, e.g. "/v1/users/:name".
+func (s *Spec) Describe(method, path string, op Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operations[method+" "+path] = op
+}
+
+// AddRoute documents a route gin does not list under its path, e.g. a custom
+// method like "/users:import" served by a route under another path.
+func (s *Spec) AddRoute(method, path string, op Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operations[method+" "+path] = op
+	s.routes = append(s.routes, gin.RouteInfo{Method: method, Path: path})
+}
+
+// Document builds the OpenAPI document of the given routes. Routes which
+// were not described are documented with their handler name and a plain
+// success response.
+func (s *Spec) Document(routes gin.RoutesInfo) *Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.Info,
+		Paths:   map[string]*PathItem{},
+	}
+	gen := newSchemas()
+	errSchema := gen.of(core.ErrResponse{})
+
+	for _, route := range append(append(gin.RoutesInfo{}, routes...), s.routes...) {
+		op, ok := s.operations[route.Method+" "+route.Path]
+		if !ok {
+			op = Operation{Summary: route.Handler}
+		}
+		if op.Hidden {
+			continue
+		}
+
+		path, params := convertPath(route.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		obj := &OperationObject{
+			Tags:        op.Tags,
+			Summary:     op.Summary,
+			Description: op.Description,
+			Parameters:  append(params, queryParameters(gen, op.Query)...),
+			Responses:   map[string]*Response{},
+			Deprecated:  op.Deprecated,
+		}
+		if op.Request != nil {
+			obj.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{jsonContentType: {Schema: gen.of(op.Request)}},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &Response{Description: http.StatusText(status)}
+		if op.Response != nil {
+			success.Content = map[string]*MediaType{jsonContentType: {Schema: gen.of(op.Response)}}
+		}
+		obj.Responses[strconv.Itoa(status)] = success
+		addErrorResponses(obj, errSchema, op.Codes)
+
+		item.set(route.Method, obj)
+	}
+	doc.Components.Schemas = gen.components
+
+	return doc
+}
+
+// set sets the operation of the method.
+func (p *PathItem) set(method string, op *OperationObject) {
+	switch method {
+	case http.MethodGet:
+		p.Get = op
+	case http.MethodPut:
+		p.Put = op
+	case http.MethodPost:
+		p.Post = op
+	case http.MethodDelete:
+		p.Delete = op
+	case http.MethodOptions:
+		p.Options = op
+	case http.MethodHead:
+		p.Head = op
+	case http.MethodPatch:
+		p.Patch = op
+	}
+}
+
+// convertPath converts a gin path to an OpenAPI path and returns its
+// parameters, e.g. "/users/:name" becomes "/users/{name}" and
+// "/users/:name:restore" becomes "/users/{name}:restore".
+func convertPath(path string) (string, []*Parameter) {
+	var params []*Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name, verb := segment[1:], ""
+		if j := strings.Index(name, ":"); j >= 0 {
+			name, verb = name[:j], name[j:]
+		}
+		segments[i] = "{" + name + "}" + verb
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// queryParameters returns the query parameters of the fields of the struct
+// query which are tagged with `form`.
+func queryParameters(gen *schemas, query interface{}) []*Parameter {
+	if query == nil {
+		return nil
+	}
+	t := reflect.TypeOf(query)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "" || name == "-" || f.PkgPath != "" {
+			continue
+		}
+		schema := gen.schema(f.Type)
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: applyRules(schema, fieldRules(f)),
+			Schema:   schema,
+		})
+	}
+
+	return params
+}
+
+// addErrorResponses documents the error codes, grouped by their HTTP status,
+// with an example core.ErrResponse per code.
+func addErrorResponses(op *OperationObject, errSchema *Schema, codes []int) {
+	// code 0 is reserved, so it resolves to the unknown error coder
+	unknown := errors.GetCoder(0)
+	coders := []errors.Coder{unknown}
+	for _, code := range codes {
+		if coder := errors.GetCoder(code); coder.Code() != unknown.Code() {
+			coders = append(coders, coder)
+		}
+	}
+	sort.SliceStable(coders, func(i, j int) bool {
+		return coders[i].Code() < coders[j].Code()
+	})
+
+	for _, coder := range coders {
+		status := strconv.Itoa(coder.HTTPStatus())
+		resp := op.Responses[status]
+		if resp == nil {
+			resp = &Response{
+				Description: http.StatusText(coder.HTTPStatus()),
+				Content: map[string]*MediaType{jsonContentType: {
+					Schema:   errSchema,
+					Examples: map[string]*Example{},
+				}},
+			}
+			op.Responses[status] = resp
+		}
+		media := resp.Content[jsonContentType]
+		if media == nil || media.Examples == nil {
+			// the success response uses the same status
+			continue
+		}
+		media.Examples[strconv.Itoa(coder.Code())] = &Example{
+			Summary: coder.String(),
+			Value: core.ErrResponse{
+				Code:      coder.Code(),
+				Message:   coder.String(),
+				Reference: coder.Reference(),
+			},
+		}
+	}
+}
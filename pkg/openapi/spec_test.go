@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"golang-standards-project-example/pkg/core"
+	"golang-standards-project-example/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const (
+	errSpecNotFound = 990001 + iota
+	errSpecExists
+	errSpecInvalid
+	errSpecValidation
+)
+
+// specCoder is an error code registered by the tests.
+type specCoder struct {
+	code   int
+	status int
+}
+
+func (c specCoder) Code() int         { return c.code }
+func (c specCoder) HTTPStatus() int   { return c.status }
+func (c specCoder) String() string    { return http.StatusText(c.status) }
+func (c specCoder) Reference() string { return "" }
+
+func init() {
+	errors.Register(specCoder{errSpecNotFound, http.StatusNotFound})
+	errors.Register(specCoder{errSpecExists, http.StatusConflict})
+	errors.Register(specCoder{errSpecInvalid, http.StatusBadRequest})
+	errors.Register(specCoder{errSpecValidation, http.StatusBadRequest})
+}
+
+func TestConvertPath(t *testing.T) {
+	tests := []struct {
+		path       string
+		want       string
+		wantParams []string
+	}{
+		{path: "/healthz", want: "/healthz"},
+		{path: "/v1/users/:name", want: "/v1/users/{name}", wantParams: []string{"name"}},
+		{path: "/v1/users/:name:restore", want: "/v1/users/{name}:restore", wantParams: []string{"name"}},
+		{path: "/v1/users:import", want: "/v1/users:import"},
+		{path: "/files/*path", want: "/files/{path}", wantParams: []string{"path"}},
+		{path: "/:group/items/:id", want: "/{group}/items/{id}", wantParams: []string{"group", "id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, params := convertPath(tt.path)
+			if got != tt.want {
+				t.Errorf("convertPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			var names []string
+			for _, p := range params {
+				if p.In != "path" || !p.Required {
+					t.Errorf("parameter %s in %s required %v, want a required path parameter", p.Name, p.In, p.Required)
+				}
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantParams) {
+				t.Errorf("convertPath(%q) parameters = %v, want %v", tt.path, names, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	unknown := errors.GetCoder(0)
+	tests := []struct {
+		name   string
+		status int
+		codes  []int
+		want   map[string][]int
+	}{
+		{
+			name: "unknown only",
+			want: map[string][]int{"200": nil, "500": {unknown.Code()}},
+		},
+		{
+			name:  "grouped by status",
+			codes: []int{errSpecValidation, errSpecNotFound, errSpecInvalid},
+			want: map[string][]int{
+				"200": nil,
+				"400": {errSpecInvalid, errSpecValidation},
+				"404": {errSpecNotFound},
+				"500": {unknown.Code()},
+			},
+		},
+		{
+			name:  "unregistered codes are dropped",
+			codes: []int{990999, errSpecExists},
+			want:  map[string][]int{"200": nil, "409": {errSpecExists}, "500": {unknown.Code()}},
+		},
+		{
+			name:   "success status is kept",
+			status: http.StatusConflict,
+			codes:  []int{errSpecExists},
+			want:   map[string][]int{"409": nil, "500": {unknown.Code()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := NewSpec(Info{Title: "test", Version: "v1"})
+			spec.Describe(http.MethodGet, "/items", Operation{Status: tt.status, Codes: tt.codes})
+			doc := spec.Document(gin.RoutesInfo{{Method: http.MethodGet, Path: "/items"}})
+
+			got := map[string][]int{}
+			for status, resp := range doc.Paths["/items"].Get.Responses {
+				got[status] = nil
+				media := resp.Content[jsonContentType]
+				if media == nil {
+					continue
+				}
+				for _, example := range media.Examples {
+					value := example.Value.(core.ErrResponse)
+					got[status] = append(got[status], value.Code)
+				}
+				sort.Ints(got[status])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("responses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type specQuery struct {
+	Limit  int    `form:"limit"  validate:"min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Field  string `form:"field"  validate:"required"`
+	Other  string
+}
+
+type specUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestOpenAPIJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	spec := NewSpec(Info{Title: "test", Version: "v1"})
+	noop := func(c *gin.Context) {}
+
+	engine.POST("/v1/users", noop)
+	spec.Describe(http.MethodPost, "/v1/users", Operation{
+		Summary:  "Create a user",
+		Tags:     []string{"users"},
+		Query:    specQuery{},
+		Request:  specUser{},
+		Status:   http.StatusCreated,
+		Response: specUser{},
+		Codes:    []int{errSpecExists},
+	})
+	engine.GET("/v1/users/:name", noop)
+	engine.GET("/internal", noop)
+	spec.Describe(http.MethodGet, "/internal", Operation{Hidden: true})
+	spec.AddRoute(http.MethodPost, "/v1/users:import", Operation{Summary: "Import users"})
+	engine.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec.Document(engine.Routes()))
+	})
+	spec.Describe(http.MethodGet, "/openapi.json", Operation{Hidden: true})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", w.Code)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path []string
+		want interface{}
+	}{
+		{path: []string{"openapi"}, want: Version},
+		{path: []string{"info", "title"}, want: "test"},
+		{path: []string{"paths", "/v1/users", "post", "summary"}, want: "Create a user"},
+		{path: []string{"paths", "/v1/users", "post", "requestBody", "content", jsonContentType, "schema", "$ref"},
+			want: componentRefPrefix + "specUser"},
+		{path: []string{"paths", "/v1/users", "post", "responses", "201", "content", jsonContentType, "schema", "$ref"},
+			want: componentRefPrefix + "specUser"},
+		{path: []string{"paths", "/v1/users", "post", "responses", "409", "content", jsonContentType, "schema", "$ref"},
+			want: componentRefPrefix + "ErrResponse"},
+		{path: []string{"paths", "/v1/users:import", "post", "summary"}, want: "Import users"},
+		{path: []string{"paths", "/internal"}, want: nil},
+		{path: []string{"paths", "/openapi.json"}, want: nil},
+		{path: []string{"components", "schemas", "specUser", "required"}, want: []interface{}{"name"}},
+	}
+	for _, tt := range tests {
+		var got interface{} = doc
+		for _, key := range tt.path {
+			m, _ := got.(map[string]interface{})
+			got = m[key]
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	paths := doc["paths"].(map[string]interface{})
+	// undescribed routes are summarized with their handler name
+	get, _ := paths["/v1/users/{name}"].(map[string]interface{})["get"].(map[string]interface{})
+	if summary, _ := get["summary"].(string); summary == "" {
+		t.Errorf("GET /v1/users/{name} = %v, want the handler name as summary", get)
+	}
+
+	post := paths["/v1/users"].(map[string]interface{})["post"].(map[string]interface{})
+	var query []string
+	for _, p := range post["parameters"].([]interface{}) {
+		param := p.(map[string]interface{})
+		query = append(query, param["name"].(string))
+		if param["name"] == "field" && param["required"] != true {
+			t.Error("query parameter field is not required")
+		}
+		if param["name"] == "limit" && param["schema"].(map[string]interface{})["maximum"] != float64(100) {
+			t.Errorf("query parameter limit schema = %v, want maximum 100", param["schema"])
+		}
+	}
+	if want := []string{"limit", "cursor", "field"}; !reflect.DeepEqual(query, want) {
+		t.Errorf("query parameters = %v, want %v", query, want)
+	}
+}
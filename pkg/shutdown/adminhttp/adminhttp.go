@@ -0,0 +1,152 @@
+package adminhttp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"golang-standards-project-example/pkg/shutdown"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Name defines shutdown manager name.
+const Name = "AdminHTTPManager"
+
+// Path is the path on which shutdown is requested with POST.
+const Path = "/shutdown"
+
+// AdminHTTPManager implements ShutdownManager interface that is added
+// to GracefulShutdown. It initiates shutdown on an authenticated
+// `POST /shutdown` request carrying `Authorization: Bearer <token>`.
+// Initialize with NewAdminHTTPManager.
+type AdminHTTPManager struct {
+	address string
+	token   string
+
+	mu       sync.Mutex
+	gs       shutdown.GSInterface
+	server   *http.Server
+	stopOnce sync.Once
+}
+
+// NewAdminHTTPManager initializes the AdminHTTPManager. If address is not
+// empty, Start serves the shutdown endpoint on it, otherwise mount Handler
+// on an existing server.
+func NewAdminHTTPManager(address, token string) *AdminHTTPManager {
+	return &AdminHTTPManager{
+		address: address,
+		token:   token,
+	}
+}
+
+// GetName returns name of this ShutdownManager.
+func (m *AdminHTTPManager) GetName() string {
+	return Name
+}
+
+// Start starts listening for shutdown requests.
+func (m *AdminHTTPManager) Start(gs shutdown.GSInterface) error {
+	if m.token == "" {
+		return errors.New("admin shutdown endpoint requires a token")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gs = gs
+	if m.address == "" {
+		return nil
+	}
+	// the shutdown may be triggered by another ShutdownManager
+	if n, ok := gs.(shutdown.Notifier); ok {
+		go func() {
+			<-n.Done()
+			gs.ReportError(m.stop())
+		}()
+	}
+
+	ln, err := net.Listen("tcp", m.address)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := m.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			gs.ReportError(err)
+		}
+	}()
+
+	return nil
+}
+
+// Handler returns the http.Handler serving shutdown requests.
+func (m *AdminHTTPManager) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if !m.authorized(r) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		m.mu.Lock()
+		gs := m.gs
+		m.mu.Unlock()
+		if gs == nil {
+			http.Error(w, "shutdown manager not started", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("shutting down\n"))
+		go gs.StartShutdown(m)
+	})
+}
+
+func (m *AdminHTTPManager) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || m.token == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) == 1
+}
+
+// ShutdownStart does nothing.
+func (m *AdminHTTPManager) ShutdownStart() error {
+	return nil
+}
+
+// ShutdownFinish stops serving the shutdown endpoint. It is stopped as well
+// when another ShutdownManager triggered the shutdown, if the GSInterface is
+// a shutdown.Notifier.
+func (m *AdminHTTPManager) ShutdownFinish() error {
+	return m.stop()
+}
+
+// stop stops serving the shutdown endpoint, only the first call does.
+func (m *AdminHTTPManager) stop() (err error) {
+	m.mu.Lock()
+	server := m.server
+	m.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	m.stopOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err = server.Shutdown(ctx)
+	})
+
+	return err
+}
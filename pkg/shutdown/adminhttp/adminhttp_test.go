@@ -0,0 +1,160 @@
+package adminhttp
+
+import (
+	"golang-standards-project-example/pkg/shutdown"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// otherManager is a ShutdownManager triggering the shutdown on demand.
+type otherManager struct{}
+
+func (otherManager) GetName() string                  { return "other" }
+func (otherManager) Start(shutdown.GSInterface) error { return nil }
+func (otherManager) ShutdownStart() error             { return nil }
+func (otherManager) ShutdownFinish() error            { return nil }
+
+// start starts a GracefulShutdown with m and returns it and a channel
+// receiving the name of the manager which triggered the shutdown.
+func start(t *testing.T, m shutdown.ShutdownManager) (*shutdown.GracefulShutdown, <-chan string) {
+	t.Helper()
+	triggered := make(chan string, 1)
+	gs := shutdown.New()
+	gs.AddShutdownManager(m)
+	gs.AddShutdownCallback(shutdown.ShutdownFunc(func(manager string) error {
+		triggered <- manager
+		return nil
+	}))
+	if err := gs.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return gs, triggered
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantStatus    int
+		wantShutdown  bool
+	}{
+		{"get", http.MethodGet, "Bearer secret", http.StatusMethodNotAllowed, false},
+		{"put", http.MethodPut, "Bearer secret", http.StatusMethodNotAllowed, false},
+		{"missing token", http.MethodPost, "", http.StatusUnauthorized, false},
+		{"empty token", http.MethodPost, "Bearer ", http.StatusUnauthorized, false},
+		{"wrong token", http.MethodPost, "Bearer secreT", http.StatusUnauthorized, false},
+		{"token prefix", http.MethodPost, "Bearer secre", http.StatusUnauthorized, false},
+		{"no bearer scheme", http.MethodPost, "secret", http.StatusUnauthorized, false},
+		{"basic scheme", http.MethodPost, "Basic secret", http.StatusUnauthorized, false},
+		{"valid", http.MethodPost, "Bearer secret", http.StatusAccepted, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAdminHTTPManager("", "secret")
+			_, triggered := start(t, m)
+
+			req := httptest.NewRequest(tt.method, Path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed && w.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("Allow = %q, want POST", w.Header().Get("Allow"))
+			}
+
+			select {
+			case manager := <-triggered:
+				if !tt.wantShutdown {
+					t.Errorf("shutdown triggered by %s", manager)
+				} else if manager != Name {
+					t.Errorf("shutdown triggered by %s, want %s", manager, Name)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantShutdown {
+					t.Error("shutdown not triggered")
+				}
+			}
+		})
+	}
+}
+
+func TestStartRequiresToken(t *testing.T) {
+	if err := NewAdminHTTPManager("", "").Start(shutdown.New()); err == nil {
+		t.Error("Start() without token succeeded")
+	}
+}
+
+func TestHandlerNotStarted(t *testing.T) {
+	m := NewAdminHTTPManager("", "secret")
+	req := httptest.NewRequest(http.MethodPost, Path, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestListenerClosedOnShutdown(t *testing.T) {
+	tests := []struct {
+		name string
+		by   func(m *AdminHTTPManager) shutdown.ShutdownManager
+	}{
+		{"by itself", func(m *AdminHTTPManager) shutdown.ShutdownManager { return m }},
+		{"by another manager", func(*AdminHTTPManager) shutdown.ShutdownManager { return otherManager{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			address := ln.Addr().String()
+			ln.Close()
+
+			m := NewAdminHTTPManager(address, "secret")
+			gs, _ := start(t, m)
+			url := "http://" + address + Path
+			waitFor(t, func() bool {
+				resp, err := http.Get(url)
+				if err != nil {
+					return false
+				}
+				resp.Body.Close()
+				return resp.StatusCode == http.StatusMethodNotAllowed
+			})
+
+			gs.StartShutdown(tt.by(m))
+			<-gs.Done()
+			waitFor(t, func() bool {
+				conn, err := net.Dial("tcp", address)
+				if err != nil {
+					return true
+				}
+				conn.Close()
+				return false
+			})
+		})
+	}
+}
+
+// waitFor waits until cond holds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,58 @@
+package contextmanager
+
+import (
+	"context"
+	"golang-standards-project-example/pkg/shutdown"
+)
+
+// Name defines shutdown manager name.
+const Name = "ContextManager"
+
+// ContextManager implements ShutdownManager interface that is added
+// to GracefulShutdown. It initiates shutdown when its context is done,
+// which is useful when the app is embedded into another program.
+// Initialize with NewContextManager.
+type ContextManager struct {
+	ctx context.Context
+}
+
+// NewContextManager initializes the ContextManager.
+func NewContextManager(ctx context.Context) *ContextManager {
+	return &ContextManager{
+		ctx: ctx,
+	}
+}
+
+// GetName returns name of this ShutdownManager.
+func (m *ContextManager) GetName() string {
+	return Name
+}
+
+// Start starts waiting for the context to be done. It stops waiting once
+// another ShutdownManager triggered the shutdown, if the GSInterface is a
+// shutdown.Notifier.
+func (m *ContextManager) Start(gs shutdown.GSInterface) error {
+	var done <-chan struct{}
+	if n, ok := gs.(shutdown.Notifier); ok {
+		done = n.Done()
+	}
+	go func() {
+		select {
+		case <-m.ctx.Done():
+			gs.StartShutdown(m)
+		case <-done:
+		}
+	}()
+
+	return nil
+}
+
+// ShutdownStart does nothing.
+func (m *ContextManager) ShutdownStart() error {
+	return nil
+}
+
+// ShutdownFinish does nothing, wait on GracefulShutdown.Done instead.
+func (m *ContextManager) ShutdownFinish() error {
+	return nil
+}
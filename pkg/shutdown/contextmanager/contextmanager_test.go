@@ -0,0 +1,68 @@
+package contextmanager
+
+import (
+	"context"
+	"golang-standards-project-example/pkg/shutdown"
+	"testing"
+	"time"
+)
+
+// otherManager is a ShutdownManager triggering the shutdown on demand.
+type otherManager struct{}
+
+func (otherManager) GetName() string                  { return "other" }
+func (otherManager) Start(shutdown.GSInterface) error { return nil }
+func (otherManager) ShutdownStart() error             { return nil }
+func (otherManager) ShutdownFinish() error            { return nil }
+
+func TestContextManager(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    func() (context.Context, context.CancelFunc)
+		other  bool
+		wantBy string
+	}{
+		{"canceled", func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, false, Name},
+		{"deadline", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 10*time.Millisecond)
+		}, false, Name},
+		{"other manager first", func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, true, "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			triggered := make(chan string, 2)
+			gs := shutdown.New()
+			gs.AddShutdownManager(NewContextManager(ctx))
+			gs.AddShutdownCallback(shutdown.ShutdownFunc(func(manager string) error {
+				triggered <- manager
+				return nil
+			}))
+			if err := gs.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.other {
+				gs.StartShutdown(otherManager{})
+			}
+			cancel()
+
+			select {
+			case <-gs.Done():
+			case <-time.After(time.Second):
+				t.Fatal("shutdown not finished")
+			}
+			if got := <-triggered; got != tt.wantBy {
+				t.Errorf("shutdown triggered by %s, want %s", got, tt.wantBy)
+			}
+			if len(triggered) != 0 {
+				t.Errorf("callbacks ran %d more times", len(triggered))
+			}
+		})
+	}
+}
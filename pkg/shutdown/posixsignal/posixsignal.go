@@ -1,19 +1,38 @@
 package posixsignal
 
 import (
+	"fmt"
 	"golang-standards-project-example/pkg/shutdown"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
 // Name defines shutdown manager name.
 const Name = "PosixSignalManager"
 
+// Default exit codes.
+const (
+	DefaultExitCode        = 0
+	DefaultFailureExitCode = 1
+	DefaultForceExitCode   = 130
+)
+
 // PosixSignalManager implements ShutdownManager interface that is added
 // to GracefulShutdown. Initialize with NewPosixSignalManager.
 type PosixSignalManager struct {
 	signals []os.Signal
+
+	exit            bool
+	exitCode        int
+	failureExitCode int
+	forceExitCode   int
+
+	gs       shutdown.GSInterface
+	done     chan struct{}
+	doneOnce sync.Once
+	err      error
 }
 
 // NewPosixSignalManager initializes the PosixSignalManager.
@@ -27,24 +46,64 @@ func NewPosixSignalManager(sig ...os.Signal) *PosixSignalManager {
 	}
 
 	return &PosixSignalManager{
-		signals: sig,
+		signals:         sig,
+		exit:            true,
+		exitCode:        DefaultExitCode,
+		failureExitCode: DefaultFailureExitCode,
+		forceExitCode:   DefaultForceExitCode,
+		done:            make(chan struct{}),
 	}
 }
 
+// SetExit sets whether ShutdownFinish exits the app, true by default.
+// If false, ShutdownFinish closes the Done channel instead and the result
+// of the shutdown is available from Err.
+func (p *PosixSignalManager) SetExit(exit bool) {
+	p.exit = exit
+}
+
+// SetExitCodes sets the exit codes used by ShutdownFinish when all shutdown
+// callbacks succeeded or any of them failed.
+func (p *PosixSignalManager) SetExitCodes(success, failure int) {
+	p.exitCode = success
+	p.failureExitCode = failure
+}
+
+// SetForceExitCode sets the exit code used when a second signal is received
+// before the shutdown finished.
+func (p *PosixSignalManager) SetForceExitCode(code int) {
+	p.forceExitCode = code
+}
+
 // GetName returns name of this ShutdownManager.
 func (p *PosixSignalManager) GetName() string {
 	return Name
 }
 
-// Start starts listening for posix signals.
+// Start starts listening for posix signals. A second signal received during
+// the shutdown exits the app immediately with the force exit code.
 func (p *PosixSignalManager) Start(gs shutdown.GSInterface) error {
+	p.gs = gs
+
 	go func() {
-		c := make(chan os.Signal, 1)
+		c := make(chan os.Signal, 2)
 		signal.Notify(c, p.signals...)
 		// Block until a signal is received.
 		<-c
+		go func() {
+			sig := <-c
+			fmt.Fprintf(os.Stderr, "Received second signal %s, forcing exit\n", sig)
+			os.Exit(p.forceExitCode)
+		}()
 		gs.StartShutdown(p)
 	}()
+	// the shutdown may be triggered by another ShutdownManager
+	if n, ok := gs.(shutdown.Notifier); ok {
+		go func() {
+			<-n.Done()
+			p.finish()
+		}()
+	}
 	return nil
 }
 
@@ -53,8 +112,46 @@ func (p *PosixSignalManager) ShutdownStart() error {
 	return nil
 }
 
-// ShutdownFinish exits the app with os.Exit(0).
+// ShutdownFinish exits the app with the exit code matching the shutdown
+// result, or closes the Done channel if exiting is disabled.
 func (p *PosixSignalManager) ShutdownFinish() error {
-	os.Exit(0)
+	p.finish()
+	if p.exit {
+		os.Exit(p.ExitCode())
+	}
 	return nil
 }
+
+// finish records the shutdown result and closes the Done channel.
+func (p *PosixSignalManager) finish() {
+	p.doneOnce.Do(func() {
+		if r, ok := p.gs.(shutdown.Reporter); ok {
+			if report := r.Report(); report != nil && len(report.Failed()) > 0 {
+				p.err = report
+			}
+		}
+		close(p.done)
+	})
+}
+
+// Done returns a channel which is closed once the shutdown finished,
+// whichever ShutdownManager triggered it.
+func (p *PosixSignalManager) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the *shutdown.ShutdownReport if any shutdown callback failed.
+// It must only be called after Done is closed.
+func (p *PosixSignalManager) Err() error {
+	return p.err
+}
+
+// ExitCode returns the exit code matching the shutdown result.
+// It must only be called after Done is closed.
+func (p *PosixSignalManager) ExitCode() int {
+	if p.err != nil {
+		return p.failureExitCode
+	}
+
+	return p.exitCode
+}
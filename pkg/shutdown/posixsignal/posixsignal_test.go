@@ -0,0 +1,66 @@
+package posixsignal
+
+import (
+	"errors"
+	"golang-standards-project-example/pkg/shutdown"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// otherManager is a ShutdownManager triggering the shutdown on demand.
+type otherManager struct{}
+
+func (otherManager) GetName() string                  { return "other" }
+func (otherManager) Start(shutdown.GSInterface) error { return nil }
+func (otherManager) ShutdownStart() error             { return nil }
+func (otherManager) ShutdownFinish() error            { return nil }
+
+func TestDoneClosedByAnyManager(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name         string
+		callbackErr  error
+		self         bool
+		wantExitCode int
+	}{
+		{name: "other manager", wantExitCode: DefaultExitCode},
+		{name: "other manager failed", callbackErr: errFailed, wantExitCode: DefaultFailureExitCode},
+		{name: "signal", self: true, wantExitCode: DefaultExitCode},
+		{name: "signal failed", self: true, callbackErr: errFailed, wantExitCode: DefaultFailureExitCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// SIGUSR2 is not sent by the test, the manager only waits for it
+			p := NewPosixSignalManager(syscall.SIGUSR2)
+			p.SetExit(false)
+			gs := shutdown.New()
+			gs.AddShutdownManager(p)
+			gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+				return tt.callbackErr
+			}))
+			if err := gs.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.self {
+				gs.StartShutdown(p)
+			} else {
+				gs.StartShutdown(otherManager{})
+			}
+
+			select {
+			case <-p.Done():
+			case <-time.After(time.Second):
+				t.Fatal("Done() is not closed after the shutdown")
+			}
+			if got := p.ExitCode(); got != tt.wantExitCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.wantExitCode)
+			}
+			var report *shutdown.ShutdownReport
+			if (tt.callbackErr != nil) != errors.As(p.Err(), &report) {
+				t.Errorf("Err() = %v, want a report %v", p.Err(), tt.callbackErr != nil)
+			}
+		})
+	}
+}
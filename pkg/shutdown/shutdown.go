@@ -1,6 +1,32 @@
 package shutdown
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the default deadline of a whole shutdown.
+const DefaultTimeout = 30 * time.Second
+
+// Phase orders ShutdownCallbacks. Callbacks of a lower phase finish before
+// callbacks of a higher phase start, callbacks of the same phase run concurrently.
+type Phase int
+
+// Predefined phases, callbacks are added to PhaseDefault unless WithPhase is given.
+const (
+	// PhaseStopAccepting stops accepting new work, e.g. closes listeners.
+	PhaseStopAccepting Phase = 10
+	// PhaseDrain waits for in-flight work to finish.
+	PhaseDrain Phase = 20
+	// PhaseDefault is the phase of callbacks added without a phase.
+	PhaseDefault Phase = 50
+	// PhaseCloseResources closes resources used by the work, e.g. database connections.
+	PhaseCloseResources Phase = 90
+)
 
 // ShutdownCallback is an interface you have to implement for callbacks.
 // OnShutdown will be called when shutdown is requested.
@@ -17,6 +43,138 @@ func (f ShutdownFunc) OnShutdown(shutdownManager string) error {
 	return f(shutdownManager)
 }
 
+// ContextShutdownCallback is a ShutdownCallback that is notified through
+// the context when its timeout or the shutdown timeout expires.
+type ContextShutdownCallback interface {
+	ShutdownCallback
+	OnShutdownContext(ctx context.Context, shutdownManager string) error
+}
+
+// ShutdownContextFunc is a helper type, so you can easily provide anonymous
+// functions as ContextShutdownCallbacks.
+type ShutdownContextFunc func(ctx context.Context, shutdownManager string) error
+
+// OnShutdown calls the function with a background context.
+func (f ShutdownContextFunc) OnShutdown(shutdownManager string) error {
+	return f(context.Background(), shutdownManager)
+}
+
+// OnShutdownContext defines the action needed to run when shutdown triggered.
+func (f ShutdownContextFunc) OnShutdownContext(ctx context.Context, shutdownManager string) error {
+	return f(ctx, shutdownManager)
+}
+
+// CallbackOption configures a ShutdownCallback added with AddShutdownCallbackWithOptions.
+type CallbackOption func(*callback)
+
+// WithPhase sets the phase in which the callback runs.
+func WithPhase(phase Phase) CallbackOption {
+	return func(c *callback) {
+		c.phase = phase
+	}
+}
+
+// WithTimeout sets the maximal duration of the callback. The callback is
+// also bounded by the timeout of the whole shutdown.
+func WithTimeout(timeout time.Duration) CallbackOption {
+	return func(c *callback) {
+		c.timeout = timeout
+	}
+}
+
+// WithName sets the name used for the callback in the ShutdownReport.
+func WithName(name string) CallbackOption {
+	return func(c *callback) {
+		c.name = name
+	}
+}
+
+type callback struct {
+	ShutdownCallback
+	name    string
+	phase   Phase
+	timeout time.Duration
+}
+
+// CallbackResult is the outcome of a single ShutdownCallback.
+type CallbackResult struct {
+	Name     string
+	Phase    Phase
+	Duration time.Duration
+	Err      error
+	// TimedOut is set if the callback did not return before its timeout
+	// or the shutdown timeout expired.
+	TimedOut bool
+	// Skipped is set if the shutdown timeout expired before the phase of
+	// the callback was reached.
+	Skipped bool
+}
+
+// Failed reports whether the callback failed, timed out or was skipped.
+func (r CallbackResult) Failed() bool {
+	return r.Err != nil || r.TimedOut || r.Skipped
+}
+
+func (r CallbackResult) message() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("%s: skipped", r.Name)
+	case r.TimedOut:
+		return fmt.Sprintf("%s: timed out after %s", r.Name, r.Duration)
+	}
+
+	return fmt.Sprintf("%s: %v", r.Name, r.Err)
+}
+
+// CallbackError is passed to the ErrorHandler as soon as a callback fails,
+// times out or is skipped.
+type CallbackError struct {
+	ShutdownManager string
+	CallbackResult
+}
+
+// Error implements the error interface.
+func (e *CallbackError) Error() string {
+	return fmt.Sprintf("shutdown by %s: callback %s", e.ShutdownManager, e.message())
+}
+
+// Unwrap returns the error of the callback.
+func (e *CallbackError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownReport summarizes the results of all ShutdownCallbacks. It is passed
+// to the ErrorHandler after all callbacks returned if any of them failed, in
+// addition to the CallbackError of each failure. Use errors.As to retrieve it.
+type ShutdownReport struct {
+	ShutdownManager string
+	Duration        time.Duration
+	Results         []CallbackResult
+}
+
+// Failed returns the results of the callbacks which failed, timed out or were skipped.
+func (r *ShutdownReport) Failed() []CallbackResult {
+	var failed []CallbackResult
+	for _, result := range r.Results {
+		if result.Failed() {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Error implements the error interface.
+func (r *ShutdownReport) Error() string {
+	var msgs []string
+	for _, result := range r.Failed() {
+		msgs = append(msgs, result.message())
+	}
+
+	return fmt.Sprintf("shutdown by %s: %d callback(s) failed: %s",
+		r.ShutdownManager, len(msgs), strings.Join(msgs, "; "))
+}
+
 // ShutdownManager is an interface implemented by ShutdownManagers.
 // GetName returns the name of ShutdownManager.
 // ShutdownManagers start listening for shutdown requests in Start.
@@ -54,19 +212,39 @@ type GSInterface interface {
 	AddShutdownCallback(shutdownCallback ShutdownCallback)
 }
 
+// Reporter is implemented by GSInterfaces which keep the ShutdownReport of
+// the last shutdown, e.g. GracefulShutdown.
+type Reporter interface {
+	Report() *ShutdownReport
+}
+
+// Notifier is implemented by GSInterfaces which signal the end of a
+// shutdown to all ShutdownManagers, e.g. GracefulShutdown.
+type Notifier interface {
+	Done() <-chan struct{}
+}
+
 // GracefulShutdown is main struct that handles ShutdownCallbacks and
 // ShutdownManagers. Initialize it with New.
 type GracefulShutdown struct {
-	callbacks    []ShutdownCallback
+	callbacks    []*callback
 	managers     []ShutdownManager
 	errorHandler ErrorHandler
+	timeout      time.Duration
+
+	reportMux    sync.Mutex
+	report       *ShutdownReport
+	shutdownOnce sync.Once
+	done         chan struct{}
 }
 
 // New initializes GracefulShutdown.
 func New() *GracefulShutdown {
 	return &GracefulShutdown{
-		callbacks: make([]ShutdownCallback, 0, 10),
+		callbacks: make([]*callback, 0, 10),
 		managers:  make([]ShutdownManager, 0, 3),
+		timeout:   DefaultTimeout,
+		done:      make(chan struct{}),
 	}
 }
 
@@ -101,40 +279,173 @@ func (gs *GracefulShutdown) SetErrorHandler(errorHandler ErrorHandler) {
 	gs.errorHandler = errorHandler
 }
 
-// AddShutdownCallback adds a ShutdownCallback that will be called when
-// shutdown is requested.
+// SetTimeout sets the deadline of the whole shutdown, DefaultTimeout by default.
+// Zero means no deadline.
+func (gs *GracefulShutdown) SetTimeout(timeout time.Duration) {
+	gs.timeout = timeout
+}
+
+// AddShutdownCallback adds a ShutdownCallback that will be called in
+// PhaseDefault when shutdown is requested.
 //
 // You can provide anything that implements ShutdownCallback interface,
 // or you can supply a function like this:
 //
-//	AddShutdownCallback(shutdown.ShutdownFunc(func() error {
+//	AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
 //		// callback code
 //		return nil
 //	}))
 func (gs *GracefulShutdown) AddShutdownCallback(shutdownCallback ShutdownCallback) {
-	gs.callbacks = append(gs.callbacks, shutdownCallback)
+	gs.AddShutdownCallbackWithOptions(shutdownCallback)
+}
+
+// AddShutdownCallbackWithOptions adds a ShutdownCallback configured by opts,
+// e.g.
+//
+//	AddShutdownCallbackWithOptions(shutdown.ShutdownFunc(func(string) error {
+//		// callback code
+//		return nil
+//	}), shutdown.WithPhase(shutdown.PhaseDrain), shutdown.WithTimeout(5*time.Second))
+func (gs *GracefulShutdown) AddShutdownCallbackWithOptions(shutdownCallback ShutdownCallback, opts ...CallbackOption) {
+	c := &callback{
+		ShutdownCallback: shutdownCallback,
+		name:             fmt.Sprintf("callback#%d", len(gs.callbacks)),
+		phase:            PhaseDefault,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	gs.callbacks = append(gs.callbacks, c)
 }
 
 // StartShutdown is called from a ShutdownManager and will initiate shutdown.
 // first call ShutdownStart on Shutdownmanager,
-// call all ShutdownCallbacks, wait for callbacks to finish and
-// call ShutdownFinish on ShutdownManager.
+// call all ShutdownCallbacks phase by phase, wait for callbacks to finish
+// or time out and call ShutdownFinish on ShutdownManager.
+// Each failed callback is passed to the ErrorHandler as a CallbackError once
+// it returns, the ShutdownReport once all callbacks returned.
+// Only the first call initiates shutdown, later calls wait for it to finish.
 func (gs *GracefulShutdown) StartShutdown(sm ShutdownManager) {
-	gs.ReportError(sm.ShutdownStart())
+	gs.shutdownOnce.Do(func() {
+		defer close(gs.done)
+
+		gs.ReportError(sm.ShutdownStart())
+
+		report := gs.runCallbacks(sm.GetName())
+		gs.reportMux.Lock()
+		gs.report = report
+		gs.reportMux.Unlock()
+		if len(report.Failed()) > 0 {
+			gs.ReportError(report)
+		}
+
+		gs.ReportError(sm.ShutdownFinish())
+	})
+}
+
+// Done returns a channel which is closed once shutdown finished, unless the
+// ShutdownManager exited the app in ShutdownFinish.
+func (gs *GracefulShutdown) Done() <-chan struct{} {
+	return gs.done
+}
+
+// Report returns the ShutdownReport of the last shutdown, nil if no shutdown
+// has finished running its callbacks yet. ShutdownManagers can use it in
+// ShutdownFinish.
+func (gs *GracefulShutdown) Report() *ShutdownReport {
+	gs.reportMux.Lock()
+	defer gs.reportMux.Unlock()
+
+	return gs.report
+}
+
+// runCallbacks runs the callbacks ordered by phase and returns their results.
+func (gs *GracefulShutdown) runCallbacks(shutdownManager string) *ShutdownReport {
+	start := time.Now()
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if gs.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, gs.timeout)
+	}
+	defer cancel()
+
+	callbacks := make([]*callback, len(gs.callbacks))
+	copy(callbacks, gs.callbacks)
+	sort.SliceStable(callbacks, func(i, j int) bool {
+		return callbacks[i].phase < callbacks[j].phase
+	})
+
+	report := &ShutdownReport{
+		ShutdownManager: shutdownManager,
+		Results:         make([]CallbackResult, len(callbacks)),
+	}
+	for begin := 0; begin < len(callbacks); {
+		end := begin
+		for end < len(callbacks) && callbacks[end].phase == callbacks[begin].phase {
+			end++
+		}
+
+		var wg sync.WaitGroup
+		for i := begin; i < end; i++ {
+			if ctx.Err() != nil {
+				report.Results[i] = CallbackResult{Name: callbacks[i].name, Phase: callbacks[i].phase, Skipped: true}
+				gs.ReportError(&CallbackError{ShutdownManager: shutdownManager, CallbackResult: report.Results[i]})
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
 
-	var wg sync.WaitGroup
-	for _, shutdownCallback := range gs.callbacks {
-		wg.Add(1)
-		go func(shutdownCallback ShutdownCallback) {
-			defer wg.Done()
+				report.Results[i] = callbacks[i].run(ctx, shutdownManager)
+				if report.Results[i].Failed() {
+					gs.ReportError(&CallbackError{ShutdownManager: shutdownManager, CallbackResult: report.Results[i]})
+				}
+			}(i)
+		}
+		wg.Wait()
 
-			gs.ReportError(shutdownCallback.OnShutdown(sm.GetName()))
-		}(shutdownCallback)
+		begin = end
 	}
+	report.Duration = time.Since(start)
 
-	wg.Wait()
+	return report
+}
+
+// run calls the callback and waits until it returns or its context expires.
+func (c *callback) run(ctx context.Context, shutdownManager string) CallbackResult {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		if cc, ok := c.ShutdownCallback.(ContextShutdownCallback); ok {
+			done <- cc.OnShutdownContext(ctx, shutdownManager)
+			return
+		}
+		done <- c.OnShutdown(shutdownManager)
+	}()
+
+	result := CallbackResult{Name: c.name, Phase: c.phase}
+	select {
+	case result.Err = <-done:
+		result.TimedOut = result.Err != nil && result.Err == ctx.Err()
+	case <-ctx.Done():
+		result.Err = ctx.Err()
+		result.TimedOut = true
+	}
+	result.Duration = time.Since(start)
 
-	gs.ReportError(sm.ShutdownFinish())
+	return result
 }
 
 // ReportError is a function that can be used to report errors to
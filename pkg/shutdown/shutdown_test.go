@@ -0,0 +1,229 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testManager is a ShutdownManager which records its calls.
+type testManager struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *testManager) GetName() string { return "test" }
+
+func (m *testManager) Start(GSInterface) error { return nil }
+
+func (m *testManager) ShutdownStart() error {
+	m.record("start")
+	return nil
+}
+
+func (m *testManager) ShutdownFinish() error {
+	m.record("finish")
+	return nil
+}
+
+func (m *testManager) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+// recordingFunc returns a callback which records its name in m.
+func recordingFunc(m *testManager, name string) ShutdownFunc {
+	return func(string) error {
+		m.record(name)
+		return nil
+	}
+}
+
+func TestStartShutdownPhaseOrder(t *testing.T) {
+	m := &testManager{}
+	gs := New()
+	gs.AddShutdownCallbackWithOptions(recordingFunc(m, "close"), WithPhase(PhaseCloseResources))
+	gs.AddShutdownCallback(recordingFunc(m, "default"))
+	gs.AddShutdownCallbackWithOptions(recordingFunc(m, "drain"), WithPhase(PhaseDrain))
+	gs.AddShutdownCallbackWithOptions(recordingFunc(m, "stop"), WithPhase(PhaseStopAccepting))
+
+	gs.StartShutdown(m)
+
+	want := []string{"start", "stop", "drain", "default", "close", "finish"}
+	if len(m.calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", m.calls, want)
+	}
+	for i := range want {
+		if m.calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", m.calls, want)
+		}
+	}
+	if report := gs.Report(); report == nil || len(report.Failed()) != 0 || len(report.Results) != 4 {
+		t.Errorf("Report() = %+v, want 4 successful results", report)
+	}
+}
+
+func TestStartShutdownSamePhaseConcurrent(t *testing.T) {
+	gs := New()
+	var started sync.WaitGroup
+	started.Add(2)
+	for i := 0; i < 2; i++ {
+		gs.AddShutdownCallback(ShutdownFunc(func(string) error {
+			started.Done()
+			// both callbacks must be running to get past here
+			started.Wait()
+			return nil
+		}))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		gs.StartShutdown(&testManager{})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("callbacks of the same phase did not run concurrently")
+	}
+}
+
+func TestStartShutdownTimeouts(t *testing.T) {
+	errFailed := errors.New("failed")
+	block := ShutdownContextFunc(func(ctx context.Context, _ string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	ignore := ShutdownFunc(func(string) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		add       func(gs *GracefulShutdown)
+		wantFails map[string]CallbackResult
+	}{
+		{
+			name:    "callback timeout",
+			timeout: time.Second,
+			add: func(gs *GracefulShutdown) {
+				gs.AddShutdownCallbackWithOptions(block, WithName("block"), WithTimeout(10*time.Millisecond))
+				gs.AddShutdownCallbackWithOptions(ShutdownFunc(func(string) error { return nil }),
+					WithName("next"), WithPhase(PhaseCloseResources))
+			},
+			wantFails: map[string]CallbackResult{"block": {TimedOut: true}},
+		},
+		{
+			name:    "callback ignoring its context",
+			timeout: time.Second,
+			add: func(gs *GracefulShutdown) {
+				gs.AddShutdownCallbackWithOptions(ignore, WithName("ignore"), WithTimeout(10*time.Millisecond))
+			},
+			wantFails: map[string]CallbackResult{"ignore": {TimedOut: true}},
+		},
+		{
+			name:    "shutdown timeout skips later phases",
+			timeout: 20 * time.Millisecond,
+			add: func(gs *GracefulShutdown) {
+				gs.AddShutdownCallbackWithOptions(block, WithName("block"), WithPhase(PhaseDrain))
+				gs.AddShutdownCallbackWithOptions(ShutdownFunc(func(string) error { return nil }),
+					WithName("skipped"), WithPhase(PhaseCloseResources))
+			},
+			wantFails: map[string]CallbackResult{"block": {TimedOut: true}, "skipped": {Skipped: true}},
+		},
+		{
+			name:    "error and panic",
+			timeout: time.Second,
+			add: func(gs *GracefulShutdown) {
+				gs.AddShutdownCallbackWithOptions(ShutdownFunc(func(string) error { return errFailed }), WithName("error"))
+				gs.AddShutdownCallbackWithOptions(ShutdownFunc(func(string) error { panic("boom") }), WithName("panic"))
+			},
+			wantFails: map[string]CallbackResult{"error": {Err: errFailed}, "panic": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := New()
+			gs.SetTimeout(tt.timeout)
+			var mu sync.Mutex
+			var errs []error
+			gs.SetErrorHandler(ErrorFunc(func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			}))
+			tt.add(gs)
+
+			start := time.Now()
+			gs.StartShutdown(&testManager{})
+			if d := time.Since(start); d > 500*time.Millisecond {
+				t.Errorf("StartShutdown() took %s", d)
+			}
+
+			failed := gs.Report().Failed()
+			if len(failed) != len(tt.wantFails) {
+				t.Fatalf("Failed() = %+v, want %d results", failed, len(tt.wantFails))
+			}
+			for _, got := range failed {
+				want, ok := tt.wantFails[got.Name]
+				if !ok {
+					t.Errorf("unexpected failure %+v", got)
+					continue
+				}
+				if got.TimedOut != want.TimedOut || got.Skipped != want.Skipped {
+					t.Errorf("result %s = %+v, want %+v", got.Name, got, want)
+				}
+				if want.Err != nil && !errors.Is(got.Err, want.Err) {
+					t.Errorf("result %s error = %v, want %v", got.Name, got.Err, want.Err)
+				}
+			}
+
+			// every failure is reported as it happens, then the report
+			mu.Lock()
+			defer mu.Unlock()
+			if len(errs) != len(tt.wantFails)+1 {
+				t.Fatalf("reported errors = %v, want %d", errs, len(tt.wantFails)+1)
+			}
+			for _, err := range errs[:len(errs)-1] {
+				var cbErr *CallbackError
+				if !errors.As(err, &cbErr) {
+					t.Errorf("reported error %v is not a *CallbackError", err)
+				}
+			}
+			var report *ShutdownReport
+			if !errors.As(errs[len(errs)-1], &report) {
+				t.Errorf("last reported error %v is not a *ShutdownReport", errs[len(errs)-1])
+			}
+		})
+	}
+}
+
+func TestStartShutdownOnce(t *testing.T) {
+	m := &testManager{}
+	gs := New()
+	gs.AddShutdownCallback(recordingFunc(m, "callback"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gs.StartShutdown(m)
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-gs.Done():
+	default:
+		t.Fatal("Done() is not closed after the shutdown")
+	}
+	if len(m.calls) != 3 {
+		t.Errorf("calls = %v, want a single shutdown", m.calls)
+	}
+}
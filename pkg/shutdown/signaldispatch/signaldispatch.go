@@ -0,0 +1,149 @@
+package signaldispatch
+
+import (
+	"fmt"
+	"golang-standards-project-example/pkg/shutdown"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// SignalHandler is an interface you have to implement for signal handlers.
+// OnSignal will be called every time one of the signals it was registered
+// for is received.
+type SignalHandler interface {
+	OnSignal(sig os.Signal) error
+}
+
+// SignalFunc is a helper type, so you can easily provide anonymous functions
+// as SignalHandlers.
+type SignalFunc func(sig os.Signal) error
+
+// OnSignal defines the action needed to run when the signal is received.
+func (f SignalFunc) OnSignal(sig os.Signal) error {
+	return f(sig)
+}
+
+// Dispatcher maps non-terminating posix signals such as SIGHUP or SIGUSR1
+// to registered SignalHandlers. Unlike PosixSignalManager it does not
+// initiate shutdown. Initialize it with New.
+type Dispatcher struct {
+	mu           sync.Mutex
+	handlers     map[os.Signal][]SignalHandler
+	errorHandler shutdown.ErrorHandler
+	signals      chan os.Signal
+	stop         chan struct{}
+}
+
+// New initializes the Dispatcher.
+func New() *Dispatcher {
+	return &Dispatcher{
+		handlers: map[os.Signal][]SignalHandler{},
+	}
+}
+
+// Handle registers a SignalHandler for the given signals. Handlers of the
+// same signal are called in registration order. Handle must be called
+// before Start.
+//
+// You can provide anything that implements SignalHandler interface,
+// or you can supply a function like this:
+//
+//	Handle(signaldispatch.SignalFunc(func(os.Signal) error {
+//		// handler code
+//		return nil
+//	}), syscall.SIGHUP)
+func (d *Dispatcher) Handle(handler SignalHandler, sig ...os.Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, s := range sig {
+		d.handlers[s] = append(d.handlers[s], handler)
+	}
+}
+
+// SetErrorHandler sets an ErrorHandler that will be called when a
+// SignalHandler returns an error or panics.
+func (d *Dispatcher) SetErrorHandler(errorHandler shutdown.ErrorHandler) {
+	d.errorHandler = errorHandler
+}
+
+// Start starts listening for the signals with registered handlers.
+// Signals are handled one after another.
+func (d *Dispatcher) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.signals != nil {
+		return fmt.Errorf("signal dispatcher already started")
+	}
+	sigs := make([]os.Signal, 0, len(d.handlers))
+	for s := range d.handlers {
+		sigs = append(sigs, s)
+	}
+	if len(sigs) == 0 {
+		return nil
+	}
+
+	d.signals = make(chan os.Signal, len(sigs))
+	d.stop = make(chan struct{})
+	signal.Notify(d.signals, sigs...)
+	go d.loop(d.signals, d.stop)
+
+	return nil
+}
+
+// Stop stops listening for signals. Signals received afterwards get their
+// default behavior.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.signals == nil {
+		return
+	}
+	signal.Stop(d.signals)
+	close(d.stop)
+	d.signals = nil
+}
+
+// ShutdownCallback returns a ShutdownCallback that stops the Dispatcher,
+// so signals are not handled while the app shuts down.
+func (d *Dispatcher) ShutdownCallback() shutdown.ShutdownCallback {
+	return shutdown.ShutdownFunc(func(string) error {
+		d.Stop()
+		return nil
+	})
+}
+
+func (d *Dispatcher) loop(signals <-chan os.Signal, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case sig := <-signals:
+			d.mu.Lock()
+			handlers := d.handlers[sig]
+			d.mu.Unlock()
+			for _, handler := range handlers {
+				d.reportError(d.dispatch(handler, sig))
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(handler SignalHandler, sig os.Signal) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler of signal %s panicked: %v", sig, r)
+		}
+	}()
+
+	return handler.OnSignal(sig)
+}
+
+func (d *Dispatcher) reportError(err error) {
+	if err != nil && d.errorHandler != nil {
+		d.errorHandler.OnError(err)
+	}
+}
@@ -0,0 +1,181 @@
+//go:build !windows
+
+package signaldispatch
+
+import (
+	"errors"
+	"fmt"
+	"golang-standards-project-example/pkg/shutdown"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// call is a call of a handler by name with a signal.
+type call struct {
+	handler string
+	sig     os.Signal
+}
+
+// recorder returns a handler sending its calls to calls.
+func recorder(name string, calls chan<- call, err error) SignalHandler {
+	return SignalFunc(func(sig os.Signal) error {
+		calls <- call{name, sig}
+		return err
+	})
+}
+
+// receive returns the next n calls.
+func receive(t *testing.T, calls <-chan call, n int) []call {
+	t.Helper()
+	var got []call
+	for len(got) < n {
+		select {
+		case c := <-calls:
+			got = append(got, c)
+		case <-time.After(time.Second):
+			t.Fatalf("got calls %v, want %d", got, n)
+		}
+	}
+	select {
+	case c := <-calls:
+		t.Fatalf("unexpected call %v after %v", c, got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	return got
+}
+
+func kill(t *testing.T, sig syscall.Signal) {
+	t.Helper()
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name string
+		send []syscall.Signal
+		want []call
+	}{
+		{"registration order", []syscall.Signal{syscall.SIGUSR1},
+			[]call{{"first", syscall.SIGUSR1}, {"both", syscall.SIGUSR1}}},
+		{"other signal", []syscall.Signal{syscall.SIGUSR2},
+			[]call{{"both", syscall.SIGUSR2}}},
+		{"one after another", []syscall.Signal{syscall.SIGUSR2, syscall.SIGUSR1},
+			[]call{{"both", syscall.SIGUSR2}, {"first", syscall.SIGUSR1}, {"both", syscall.SIGUSR1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make(chan call, 10)
+			d := New()
+			d.Handle(recorder("first", calls, nil), syscall.SIGUSR1)
+			d.Handle(recorder("both", calls, nil), syscall.SIGUSR2, syscall.SIGUSR1)
+			if err := d.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer d.Stop()
+
+			// signals are coalesced, wait for the handlers of each
+			handlers := map[syscall.Signal]int{syscall.SIGUSR1: 2, syscall.SIGUSR2: 1}
+			var got []call
+			for _, sig := range tt.send {
+				kill(t, sig)
+				got = append(got, receive(t, calls, handlers[sig])...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+	calls := make(chan call, 10)
+	errs := make(chan error, 10)
+	d := New()
+	d.SetErrorHandler(shutdown.ErrorFunc(func(err error) { errs <- err }))
+	d.Handle(recorder("failing", calls, errFailed), syscall.SIGUSR1)
+	d.Handle(SignalFunc(func(os.Signal) error { panic("boom") }), syscall.SIGUSR1)
+	d.Handle(recorder("last", calls, nil), syscall.SIGUSR1)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Stop()
+
+	kill(t, syscall.SIGUSR1)
+	want := []call{{"failing", syscall.SIGUSR1}, {"last", syscall.SIGUSR1}}
+	if got := receive(t, calls, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+	if err := <-errs; !errors.Is(err, errFailed) {
+		t.Errorf("first error = %v, want %v", err, errFailed)
+	}
+	if err, want := <-errs, fmt.Sprintf("handler of signal %s panicked: boom", syscall.SIGUSR1); err.Error() != want {
+		t.Errorf("second error = %v, want %s", err, want)
+	}
+}
+
+func TestDispatchNeverShutsDown(t *testing.T) {
+	calls := make(chan call, 10)
+	gs := shutdown.New()
+	gs.AddShutdownCallback(shutdown.ShutdownFunc(func(manager string) error {
+		calls <- call{"shutdown by " + manager, nil}
+		return nil
+	}))
+	if err := gs.Start(); err != nil {
+		t.Fatal(err)
+	}
+	d := New()
+	d.Handle(recorder("handler", calls, nil), syscall.SIGHUP, syscall.SIGUSR1)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Stop()
+
+	for _, sig := range []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1} {
+		kill(t, sig)
+		if got := receive(t, calls, 1); got[0].handler != "handler" {
+			t.Errorf("calls = %v, want the handler only", got)
+		}
+	}
+	select {
+	case <-gs.Done():
+		t.Error("signal started the shutdown")
+	default:
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	if err := New().Start(); err != nil {
+		t.Errorf("Start() without handlers = %v", err)
+	}
+
+	calls := make(chan call, 10)
+	d := New()
+	d.Handle(recorder("handler", calls, nil), syscall.SIGUSR1)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Start(); err == nil {
+		t.Error("second Start() succeeded")
+	}
+
+	// keep SIGUSR1 from terminating the test once the dispatcher stopped
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	if err := d.ShutdownCallback().OnShutdown("test"); err != nil {
+		t.Fatal(err)
+	}
+	d.Stop()
+	kill(t, syscall.SIGUSR1)
+	<-ignored
+	receive(t, calls, 0)
+}
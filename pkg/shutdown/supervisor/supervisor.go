@@ -0,0 +1,118 @@
+package supervisor
+
+import (
+	"golang-standards-project-example/pkg/shutdown"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Name defines shutdown manager name.
+const Name = "SupervisorManager"
+
+// DefaultInterval is the default interval in which the watched file is polled.
+const DefaultInterval = time.Second
+
+// SupervisorManager implements ShutdownManager interface that is added
+// to GracefulShutdown. It initiates shutdown when a watched file appears or
+// stdin is closed, which lets supervisors stop the app without signals.
+// Initialize with NewSupervisorManager.
+type SupervisorManager struct {
+	file     string
+	stdin    io.Reader
+	interval time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewSupervisorManager initializes the SupervisorManager. If file is not
+// empty, shutdown is initiated once it is created; a file existing at Start
+// is ignored until it is removed and created again. If watchStdin is true,
+// shutdown is initiated once stdin reaches EOF.
+func NewSupervisorManager(file string, watchStdin bool) *SupervisorManager {
+	m := &SupervisorManager{
+		file:     file,
+		interval: DefaultInterval,
+		stop:     make(chan struct{}),
+	}
+	if watchStdin {
+		m.stdin = os.Stdin
+	}
+
+	return m
+}
+
+// SetInterval sets the interval in which the watched file is polled.
+func (m *SupervisorManager) SetInterval(interval time.Duration) {
+	m.interval = interval
+}
+
+// GetName returns name of this ShutdownManager.
+func (m *SupervisorManager) GetName() string {
+	return Name
+}
+
+// Start starts watching the file and stdin.
+func (m *SupervisorManager) Start(gs shutdown.GSInterface) error {
+	if m.file != "" {
+		// a file existing at Start is ignored until it is created again
+		go m.watchFile(gs, fileExists(m.file))
+		// the shutdown may be triggered by another ShutdownManager
+		if n, ok := gs.(shutdown.Notifier); ok {
+			go func() {
+				<-n.Done()
+				_ = m.ShutdownFinish()
+			}()
+		}
+	}
+	if m.stdin != nil {
+		go func() {
+			// Block until stdin is closed.
+			_, _ = io.Copy(io.Discard, m.stdin)
+			gs.StartShutdown(m)
+		}()
+	}
+
+	return nil
+}
+
+func (m *SupervisorManager) watchFile(gs shutdown.GSInterface, existed bool) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+
+		exists := fileExists(m.file)
+		if exists && !existed {
+			gs.StartShutdown(m)
+			return
+		}
+		existed = exists
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// ShutdownStart does nothing.
+func (m *SupervisorManager) ShutdownStart() error {
+	return nil
+}
+
+// ShutdownFinish stops watching the file.
+func (m *SupervisorManager) ShutdownFinish() error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+
+	return nil
+}
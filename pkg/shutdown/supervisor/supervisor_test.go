@@ -0,0 +1,122 @@
+package supervisor
+
+import (
+	"golang-standards-project-example/pkg/shutdown"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// otherManager is a ShutdownManager triggering the shutdown on demand.
+type otherManager struct{}
+
+func (otherManager) GetName() string                  { return "other" }
+func (otherManager) Start(shutdown.GSInterface) error { return nil }
+func (otherManager) ShutdownStart() error             { return nil }
+func (otherManager) ShutdownFinish() error            { return nil }
+
+// start starts a GracefulShutdown with m and returns it and a channel
+// receiving the name of the manager which triggered the shutdown.
+func start(t *testing.T, m shutdown.ShutdownManager) (*shutdown.GracefulShutdown, <-chan string) {
+	t.Helper()
+	triggered := make(chan string, 1)
+	gs := shutdown.New()
+	gs.AddShutdownManager(m)
+	gs.AddShutdownCallback(shutdown.ShutdownFunc(func(manager string) error {
+		triggered <- manager
+		return nil
+	}))
+	if err := gs.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return gs, triggered
+}
+
+// expect fails the test unless the shutdown is triggered by the manager as wanted.
+func expect(t *testing.T, triggered <-chan string, want bool) {
+	t.Helper()
+	select {
+	case manager := <-triggered:
+		if !want {
+			t.Fatalf("shutdown triggered by %s", manager)
+		}
+		if manager != Name {
+			t.Fatalf("shutdown triggered by %s, want %s", manager, Name)
+		}
+	case <-time.After(200 * time.Millisecond):
+		if want {
+			t.Fatal("shutdown not triggered")
+		}
+	}
+}
+
+func touch(t *testing.T, name string) {
+	t.Helper()
+	if err := os.WriteFile(name, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStdin(t *testing.T) {
+	r, w := io.Pipe()
+	m := NewSupervisorManager("", false)
+	m.stdin = r
+	_, triggered := start(t, m)
+
+	if _, err := w.Write([]byte("input is ignored\n")); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, triggered, false)
+	w.Close()
+	expect(t, triggered, true)
+}
+
+func TestWatchFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		before bool
+		change func(t *testing.T, file string)
+		want   bool
+	}{
+		{"created", false, touch, true},
+		{"not created", false, func(*testing.T, string) {}, false},
+		{"existing ignored", true, func(*testing.T, string) {}, false},
+		{"existing recreated", true, func(t *testing.T, file string) {
+			if err := os.Remove(file); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			touch(t, file)
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "stop")
+			if tt.before {
+				touch(t, file)
+			}
+			m := NewSupervisorManager(file, false)
+			m.SetInterval(10 * time.Millisecond)
+			_, triggered := start(t, m)
+
+			tt.change(t, file)
+			expect(t, triggered, tt.want)
+		})
+	}
+}
+
+func TestWatchStoppedByOtherManager(t *testing.T) {
+	m := NewSupervisorManager(filepath.Join(t.TempDir(), "stop"), false)
+	m.SetInterval(10 * time.Millisecond)
+	gs, _ := start(t, m)
+
+	gs.StartShutdown(otherManager{})
+	select {
+	case <-m.stop:
+	case <-time.After(time.Second):
+		t.Fatal("file watch not stopped")
+	}
+}
@@ -0,0 +1,396 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"golang-standards-project-example/pkg/shutdown"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Name defines shutdown manager name.
+const Name = "UpgradeManager"
+
+// Environment variables passed to the new process.
+const (
+	// EnvListenFds is the number of listeners passed, starting at file descriptor 3.
+	EnvListenFds = "UPGRADE_LISTEN_FDS"
+	// EnvPacketFds is the number of packet connections passed after the listeners.
+	EnvPacketFds = "UPGRADE_PACKET_FDS"
+	// EnvReadyFd is the file descriptor the new process reports readiness on.
+	EnvReadyFd = "UPGRADE_READY_FD"
+)
+
+// DefaultTimeout is the default time to wait for the new process to become ready.
+const DefaultTimeout = 30 * time.Second
+
+// fdsStart is the first file descriptor of exec.Cmd.ExtraFiles.
+const fdsStart = 3
+
+// readyMessage is written by the new process once it is ready.
+const readyMessage = "ready\n"
+
+// Sockets are the sockets handed over to the new process.
+type Sockets struct {
+	// Listeners are stream sockets, e.g. of HTTP and HTTPS servers.
+	Listeners []net.Listener
+	// PacketConns are datagram sockets, e.g. of HTTP/3 servers.
+	PacketConns []net.PacketConn
+}
+
+// SocketsFunc returns the sockets to hand over to the new process.
+type SocketsFunc func() Sockets
+
+// UpgradeManager implements ShutdownManager interface that is added
+// to GracefulShutdown. On a signal it starts the current executable again
+// with the same arguments, passes the sockets returned by SocketsFunc,
+// waits for the new process to report readiness and then initiates shutdown,
+// so connections are never refused during an upgrade. The new process must
+// only report readiness once it serves requests itself, see NotifyReady.
+// Initialize with NewUpgradeManager.
+type UpgradeManager struct {
+	sockets SocketsFunc
+	signals []os.Signal
+	timeout time.Duration
+}
+
+// NewUpgradeManager initializes the UpgradeManager, listening to the given signals.
+func NewUpgradeManager(sockets SocketsFunc, sig ...os.Signal) *UpgradeManager {
+	return &UpgradeManager{
+		sockets: sockets,
+		signals: sig,
+		timeout: DefaultTimeout,
+	}
+}
+
+// SetTimeout sets the time to wait for the new process to become ready.
+func (m *UpgradeManager) SetTimeout(timeout time.Duration) {
+	m.timeout = timeout
+}
+
+// GetName returns name of this ShutdownManager.
+func (m *UpgradeManager) GetName() string {
+	return Name
+}
+
+// Start starts listening for upgrade signals. If starting the new process
+// fails, the error is reported and the current process keeps serving.
+func (m *UpgradeManager) Start(gs shutdown.GSInterface) error {
+	if len(m.signals) == 0 {
+		return errors.New("upgrade manager requires a signal")
+	}
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, m.signals...)
+		for range c {
+			if err := m.Upgrade(); err != nil {
+				gs.ReportError(fmt.Errorf("upgrade failed: %w", err))
+				continue
+			}
+			signal.Stop(c)
+			gs.StartShutdown(m)
+			return
+		}
+	}()
+
+	return nil
+}
+
+// Upgrade starts the new process and waits until it is ready. The new
+// process is killed if it does not become ready in time.
+func (m *UpgradeManager) Upgrade() error {
+	sockets := m.sockets()
+	if len(sockets.Listeners) == 0 && len(sockets.PacketConns) == 0 {
+		return errors.New("no sockets to hand over")
+	}
+
+	files := make([]*os.File, 0, len(sockets.Listeners)+len(sockets.PacketConns)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, ln := range sockets.Listeners {
+		f, err := socketFile(ln, ln.Addr())
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	for _, conn := range sockets.PacketConns {
+		f, err := socketFile(conn, conn.LocalAddr())
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		EnvListenFds+"="+strconv.Itoa(len(sockets.Listeners)),
+		EnvPacketFds+"="+strconv.Itoa(len(sockets.PacketConns)),
+		EnvReadyFd+"="+strconv.Itoa(fdsStart+len(files)-1),
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// only the new process may hold the write end, so a crash closes the pipe
+	_ = readyW.Close()
+	files = files[:len(files)-1]
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, len(readyMessage))
+		_, err := io.ReadFull(readyR, buf)
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+	case <-time.After(m.timeout):
+		err = fmt.Errorf("new process %d not ready within %s", cmd.Process.Pid, m.timeout)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	// the new process serves the unix sockets now, do not remove them on close
+	for _, ln := range sockets.Listeners {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	_ = cmd.Process.Release()
+
+	return nil
+}
+
+// socketFile returns a duplicate of the file descriptor of the socket.
+func socketFile(socket interface{}, addr net.Addr) (*os.File, error) {
+	fs, ok := socket.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("socket %s:%s cannot be handed over", addr.Network(), addr)
+	}
+
+	return fs.File()
+}
+
+// ShutdownStart does nothing.
+func (m *UpgradeManager) ShutdownStart() error {
+	return nil
+}
+
+// ShutdownFinish does nothing, the new process keeps running.
+func (m *UpgradeManager) ShutdownFinish() error {
+	return nil
+}
+
+// Inherited are the sockets handed over by the previous process. The new
+// process adopts them by address, so it can be configured like the previous one.
+type Inherited struct {
+	mu          sync.Mutex
+	listeners   []net.Listener
+	packetConns []net.PacketConn
+}
+
+// Inherit returns the sockets handed over by the previous process, nil if
+// the process was not started by an UpgradeManager. The environment
+// variables are unset so later upgrades are not affected.
+func Inherit() (*Inherited, error) {
+	listenFds, packetFds := os.Getenv(EnvListenFds), os.Getenv(EnvPacketFds)
+	_ = os.Unsetenv(EnvListenFds)
+	_ = os.Unsetenv(EnvPacketFds)
+	if listenFds == "" {
+		return nil, nil
+	}
+	nListeners, err := strconv.Atoi(listenFds)
+	if err != nil || nListeners < 0 {
+		return nil, fmt.Errorf("invalid %s %q", EnvListenFds, listenFds)
+	}
+	nPacketConns := 0
+	if packetFds != "" {
+		if nPacketConns, err = strconv.Atoi(packetFds); err != nil || nPacketConns < 0 {
+			return nil, fmt.Errorf("invalid %s %q", EnvPacketFds, packetFds)
+		}
+	}
+
+	files := make([]*os.File, nListeners+nPacketConns)
+	for i := range files {
+		files[i] = os.NewFile(uintptr(fdsStart+i), fmt.Sprintf("upgrade-socket-%d", i))
+	}
+
+	return inherit(files[:nListeners], files[nListeners:])
+}
+
+// inherit creates the sockets of the files and closes the files.
+func inherit(listenerFiles, packetFiles []*os.File) (*Inherited, error) {
+	i := &Inherited{}
+	var err error
+	for _, f := range listenerFiles {
+		var ln net.Listener
+		if err == nil {
+			// FileListener duplicates the file descriptor with close-on-exec set.
+			ln, err = net.FileListener(f)
+		}
+		if ln != nil {
+			i.listeners = append(i.listeners, ln)
+		}
+		_ = f.Close()
+	}
+	for _, f := range packetFiles {
+		var conn net.PacketConn
+		if err == nil {
+			conn, err = net.FilePacketConn(f)
+		}
+		if conn != nil {
+			i.packetConns = append(i.packetConns, conn)
+		}
+		_ = f.Close()
+	}
+	if err != nil {
+		_ = i.Close()
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Listener returns the inherited listener on the address, nil if there is
+// none. The listener is returned only once.
+func (i *Inherited) Listener(network, address string) net.Listener {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for n, ln := range i.listeners {
+		if sameAddr(ln.Addr(), network, address) {
+			i.listeners = append(i.listeners[:n], i.listeners[n+1:]...)
+			return ln
+		}
+	}
+
+	return nil
+}
+
+// PacketConn returns the inherited packet connection on the address, nil if
+// there is none. The connection is returned only once.
+func (i *Inherited) PacketConn(network, address string) net.PacketConn {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for n, conn := range i.packetConns {
+		if sameAddr(conn.LocalAddr(), network, address) {
+			i.packetConns = append(i.packetConns[:n], i.packetConns[n+1:]...)
+			return conn
+		}
+	}
+
+	return nil
+}
+
+// Listeners returns the inherited listeners which were not returned by
+// Listener yet, e.g. the ones passed by systemd socket activation to the
+// first process.
+func (i *Inherited) Listeners() []net.Listener {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	listeners := i.listeners
+	i.listeners = nil
+
+	return listeners
+}
+
+// Close closes the inherited sockets which were not returned yet.
+func (i *Inherited) Close() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var errs []error
+	for _, ln := range i.listeners {
+		errs = append(errs, ln.Close())
+	}
+	for _, conn := range i.packetConns {
+		errs = append(errs, conn.Close())
+	}
+	i.listeners, i.packetConns = nil, nil
+
+	return errors.Join(errs...)
+}
+
+// sameAddr reports whether addr is the address the socket was configured
+// with. Unspecified IP addresses, e.g. "", "0.0.0.0" and "::", are equal.
+func sameAddr(addr net.Addr, network, address string) bool {
+	switch a := addr.(type) {
+	case *net.UnixAddr:
+		return network == "unix" && a.Name == address
+	case *net.TCPAddr:
+		if !strings.HasPrefix(network, "tcp") {
+			return false
+		}
+		want, err := net.ResolveTCPAddr(network, address)
+		return err == nil && a.Port == want.Port && sameIP(a.IP, want.IP)
+	case *net.UDPAddr:
+		if !strings.HasPrefix(network, "udp") {
+			return false
+		}
+		want, err := net.ResolveUDPAddr(network, address)
+		return err == nil && a.Port == want.Port && sameIP(a.IP, want.IP)
+	}
+
+	return false
+}
+
+func sameIP(a, b net.IP) bool {
+	if len(a) == 0 || a.IsUnspecified() {
+		return len(b) == 0 || b.IsUnspecified()
+	}
+
+	return a.Equal(b)
+}
+
+// NotifyReady tells the previous process that this process is ready to
+// serve, so it starts shutting down. As the processes share the sockets,
+// requests on them may be answered by either, call it only once this process
+// answered a request without going through the sockets. It does nothing if
+// the process was not started by an UpgradeManager.
+func NotifyReady() error {
+	fd := os.Getenv(EnvReadyFd)
+	_ = os.Unsetenv(EnvReadyFd)
+	if fd == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", EnvReadyFd, fd)
+	}
+
+	f := os.NewFile(uintptr(n), "upgrade-ready")
+	defer f.Close()
+	_, err = f.Write([]byte(readyMessage))
+
+	return err
+}
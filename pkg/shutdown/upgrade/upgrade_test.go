@@ -0,0 +1,157 @@
+//go:build !windows
+
+package upgrade
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSameAddr(t *testing.T) {
+	tests := []struct {
+		addr    net.Addr
+		network string
+		address string
+		want    bool
+	}{
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}, "tcp", "127.0.0.1:8080", true},
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}, "tcp", "localhost:8080", true},
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}, "tcp", "127.0.0.1:8081", false},
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}, "tcp", "0.0.0.0:8080", false},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 8443}, "tcp", "0.0.0.0:8443", true},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 8443}, "tcp", ":8443", true},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 8443}, "udp", ":8443", false},
+		{&net.UDPAddr{IP: net.IPv6unspecified, Port: 8443}, "udp", "0.0.0.0:8443", true},
+		{&net.UDPAddr{IP: net.IPv6unspecified, Port: 8443}, "tcp", "0.0.0.0:8443", false},
+		{&net.UnixAddr{Name: "/run/a.sock", Net: "unix"}, "unix", "/run/a.sock", true},
+		{&net.UnixAddr{Name: "/run/a.sock", Net: "unix"}, "unix", "/run/b.sock", false},
+		{&net.UnixAddr{Name: "/run/a.sock", Net: "unix"}, "tcp", "/run/a.sock", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr.String()+"_"+tt.network+"_"+tt.address, func(t *testing.T) {
+			if got := sameAddr(tt.addr, tt.network, tt.address); got != tt.want {
+				t.Errorf("sameAddr(%s, %s, %s) = %v, want %v", tt.addr, tt.network, tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInherit(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	unixPath := filepath.Join(t.TempDir(), "server.sock")
+	unix, err := net.Listen("unix", unixPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close()
+	extra, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer extra.Close()
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+
+	var listenerFiles, packetFiles []*os.File
+	for _, ln := range []net.Listener{tcp, unix, extra} {
+		f, err := socketFile(ln, ln.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		listenerFiles = append(listenerFiles, f)
+	}
+	f, err := socketFile(udp, udp.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	packetFiles = append(packetFiles, f)
+
+	inherited, err := inherit(listenerFiles, packetFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inherited.Close()
+
+	ln := inherited.Listener("tcp", tcp.Addr().String())
+	if ln == nil || ln.Addr().String() != tcp.Addr().String() {
+		t.Fatalf("Listener(tcp, %s) = %v", tcp.Addr(), ln)
+	}
+	defer ln.Close()
+	if again := inherited.Listener("tcp", tcp.Addr().String()); again != nil {
+		t.Errorf("Listener(tcp, %s) returned the listener twice", tcp.Addr())
+	}
+	if ul := inherited.Listener("unix", unixPath); ul == nil {
+		t.Errorf("Listener(unix, %s) = nil", unixPath)
+	} else {
+		defer ul.Close()
+	}
+	if conn := inherited.PacketConn("udp", udp.LocalAddr().String()); conn == nil {
+		t.Errorf("PacketConn(udp, %s) = nil", udp.LocalAddr())
+	} else {
+		defer conn.Close()
+	}
+	if conn := inherited.PacketConn("udp", "127.0.0.1:1"); conn != nil {
+		t.Errorf("PacketConn(udp, 127.0.0.1:1) = %v, want nil", conn.LocalAddr())
+	}
+
+	rest := inherited.Listeners()
+	if len(rest) != 1 || rest[0].Addr().String() != extra.Addr().String() {
+		t.Fatalf("Listeners() = %v, want the listener on %s", rest, extra.Addr())
+	}
+	defer rest[0].Close()
+
+	// the inherited listener accepts connections made to the original one
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+	conn, err := net.Dial("tcp", tcp.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+}
+
+func TestInheritEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "not upgraded", wantNil: true},
+		{name: "invalid listeners", env: map[string]string{EnvListenFds: "x"}, wantErr: true},
+		{name: "invalid packet conns", env: map[string]string{EnvListenFds: "0", EnvPacketFds: "-1"}, wantErr: true},
+		{name: "none", env: map[string]string{EnvListenFds: "0", EnvPacketFds: "0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvListenFds, "")
+			t.Setenv(EnvPacketFds, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			inherited, err := Inherit()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inherit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (inherited == nil) != tt.wantNil {
+				t.Errorf("Inherit() = %v, want nil %v", inherited, tt.wantNil)
+			}
+			if os.Getenv(EnvListenFds) != "" || os.Getenv(EnvPacketFds) != "" {
+				t.Errorf("Inherit() did not unset the environment variables")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnyValue(t *testing.T) {
+	tests := []struct {
+		name string
+		kv   attribute.KeyValue
+		want string
+	}{
+		{"string", attribute.String("k", "v"), `{"stringValue":"v"}`},
+		{"bool", attribute.Bool("k", true), `{"boolValue":true}`},
+		{"int", attribute.Int64("k", 1<<40), `{"intValue":"1099511627776"}`},
+		{"double", attribute.Float64("k", 1.5), `{"doubleValue":1.5}`},
+		{"strings", attribute.StringSlice("k", []string{"a", "b"}),
+			`{"arrayValue":{"values":[{"stringValue":"a"},{"stringValue":"b"}]}}`},
+		{"ints", attribute.Int64Slice("k", []int64{1}), `{"arrayValue":{"values":[{"intValue":"1"}]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(anyValue(tt.kv.Value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("anyValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(newJSONExporter(&buf)),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "test"))),
+	)
+	tracer := tp.Tracer("scope", trace.WithInstrumentationVersion("v1"))
+
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child", trace.WithAttributes(attribute.Int("n", 2)))
+	child.AddEvent("event")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per span: %s", len(lines), buf.String())
+	}
+	var spans []otlpSpan
+	for _, line := range lines {
+		var req otlpRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+		if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+			t.Fatalf("unexpected grouping: %s", line)
+		}
+		rs := req.ResourceSpans[0]
+		if got := rs.Resource.Attributes; len(got) != 1 || got[0].Key != "service.name" {
+			t.Errorf("resource attributes = %+v", got)
+		}
+		if got := rs.ScopeSpans[0].Scope; got != (otlpScope{Name: "scope", Version: "v1"}) {
+			t.Errorf("scope = %+v", got)
+		}
+		spans = append(spans, *rs.ScopeSpans[0].Spans[0])
+	}
+
+	child2, parent2 := spans[0], spans[1]
+	traceID := parent.SpanContext().TraceID().String()
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"trace id is hex", parent2.TraceID, traceID},
+		{"same trace", child2.TraceID, traceID},
+		{"parent span id", child2.ParentSpanID, parent.SpanContext().SpanID().String()},
+		{"root has no parent", parent2.ParentSpanID, ""},
+		{"server kind", parent2.Kind, 2},
+		{"internal kind", child2.Kind, 1},
+		{"ok status", parent2.Status, otlpStatus{Code: otlpStatusOk}},
+		{"error status", child2.Status, otlpStatus{Code: otlpStatusError, Message: "failed"}},
+		{"attributes", child2.Attributes, keyValues([]attribute.KeyValue{attribute.Int("n", 2)})},
+		{"events", len(child2.Events), 1},
+		{"nanos", parent2.StartTimeUnixNano != "0" && parent2.EndTimeUnixNano != "0", true},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// TracerName is the name of the tracer used by this module.
+const TracerName = "golang-standards-project-example"
+
+// Span exporters.
+const (
+	// ExporterNone disables tracing.
+	ExporterNone = ""
+	// ExporterStdout writes spans as OTLP/JSON lines to stdout.
+	ExporterStdout = "stdout"
+	// ExporterFile appends spans as OTLP/JSON lines to Config.FilePath.
+	ExporterFile = "file"
+	// ExporterOTLP sends spans via OTLP/HTTP to Config.Endpoint.
+	ExporterOTLP = "otlp"
+)
+
+// Config is the configuration of the tracer provider.
+type Config struct {
+	ServiceName string
+	Exporter    string
+	FilePath    string
+	// Endpoint is the OTLP/HTTP collector address, e.g. localhost:4318.
+	Endpoint string
+	Insecure bool
+	// SampleRatio is the ratio of new traces to sample, parent decisions are honored.
+	SampleRatio float64
+}
+
+// Provider exports the spans of the application.
+type Provider struct {
+	*sdktrace.TracerProvider
+	file *os.File
+}
+
+// NewProvider creates a tracer provider from cfg and installs it, with the
+// W3C trace context and baggage propagators, as the global provider. It
+// returns nil if tracing is disabled.
+func NewProvider(ctx context.Context, cfg *Config) (*Provider, error) {
+	p := &Provider{}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		exporter = newJSONExporter(os.Stdout)
+	case ExporterFile:
+		if p.file, err = os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return nil, err
+		}
+		exporter = newJSONExporter(p.file)
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		p.closeFile()
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		p.closeFile()
+		return nil, err
+	}
+	p.TracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(p.TracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return p, nil
+}
+
+// Shutdown flushes the pending spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.TracerProvider.Shutdown(ctx)
+	p.closeFile()
+
+	return err
+}
+
+func (p *Provider) closeFile() {
+	if p.file != nil {
+		_ = p.file.Close()
+	}
+}
+
+// Tracer returns the tracer of this module from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// TraceID returns the ID of the trace the span in ctx belongs to, empty if
+// ctx carries no valid span.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
+	return ""
+}
@@ -0,0 +1,122 @@
+package activation
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var envs = []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"}
+
+func TestListenersNotActivated(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"no pid", map[string]string{"LISTEN_FDS": "1"}, false},
+		{"no count", map[string]string{"LISTEN_PID": pid}, false},
+		{"other pid", map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "http"}, false},
+		{"zero", map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "0"}, false},
+		{"count not a number", map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "x"}, true},
+		{"negative count", map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "-1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range envs {
+				t.Setenv(env, "")
+				os.Unsetenv(env)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			listeners, err := Listeners()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Listeners() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(listeners) != 0 {
+				t.Errorf("Listeners() = %v, want none", listeners)
+			}
+			for _, env := range envs {
+				if value, ok := os.LookupEnv(env); ok {
+					t.Errorf("%s = %q after Listeners(), want it unset", env, value)
+				}
+			}
+		})
+	}
+}
+
+// TestListenersHelper is run by TestListeners in a child process which was
+// passed the sockets.
+func TestListenersHelper(t *testing.T) {
+	if os.Getenv("ACTIVATION_TEST_HELPER") == "" {
+		t.Skip("run by TestListeners")
+	}
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	listeners, err := Listeners()
+	if err != nil {
+		fmt.Print(err)
+		os.Exit(1)
+	}
+	var addrs []string
+	for _, ln := range listeners {
+		addrs = append(addrs, ln.Addr().String())
+	}
+	for _, env := range envs {
+		if _, ok := os.LookupEnv(env); ok {
+			addrs = append(addrs, env+" set")
+		}
+	}
+	fmt.Print(strings.Join(addrs, ","))
+	os.Exit(0)
+}
+
+func TestListeners(t *testing.T) {
+	var files []*os.File
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		f, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		files = append(files, f)
+		addrs = append(addrs, ln.Addr().String())
+	}
+
+	tests := []struct {
+		name string
+		fds  string
+		want string
+	}{
+		{"all", "2", strings.Join(addrs, ",")},
+		{"first", "1", addrs[0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestListenersHelper$")
+			cmd.Env = append(os.Environ(), "ACTIVATION_TEST_HELPER=1", "LISTEN_FDS="+tt.fds, "LISTEN_FDNAMES=http:https")
+			cmd.ExtraFiles = files
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("helper error = %v, output %q", err, out)
+			}
+			if string(out) != tt.want {
+				t.Errorf("listeners = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package logfile
+
+import (
+	"os"
+	"sync"
+)
+
+// File is an io.Writer appending to a log file which can be reopened, e.g.
+// after it was moved away by logrotate.
+type File struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// Open opens the log file at path for appending, creating it if needed.
+func Open(path string) (*File, error) {
+	f := &File{path: path}
+	if err := f.Reopen(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write writes p to the current log file.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Write(p)
+}
+
+// Reopen closes the current log file and opens the file at the same path again.
+func (f *File) Reopen() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	old := f.file
+	f.file = file
+	if old != nil {
+		return old.Close()
+	}
+
+	return nil
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
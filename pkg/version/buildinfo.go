@@ -0,0 +1,85 @@
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Module describes a Go module the binary was built with.
+type Module struct {
+	Path    string  `json:"path"              yaml:"path"`
+	Version string  `json:"version"           yaml:"version"`
+	Sum     string  `json:"sum,omitempty"     yaml:"sum,omitempty"`
+	Replace *Module `json:"replace,omitempty" yaml:"replace,omitempty"`
+}
+
+// BuildSetting is a key/value pair describing one setting that influenced
+// the build, e.g. "-ldflags", "CGO_ENABLED" or "vcs.revision".
+type BuildSetting struct {
+	Key   string `json:"key"   yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// isPlaceholder reports whether s was not set through -ldflags.
+func isPlaceholder(s string) bool {
+	return s == "" || strings.Contains(s, "$Format")
+}
+
+// applyBuildInfo fills the fields which were not set through -ldflags from
+// the build information embedded by the go command, and adds the module
+// dependency list and build settings.
+func (info *Info) applyBuildInfo(bi *debug.BuildInfo) {
+	var revision, modified, commitDate string
+	for _, s := range bi.Settings {
+		info.Settings = append(info.Settings, BuildSetting{Key: s.Key, Value: s.Value})
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			commitDate = s.Value
+		}
+	}
+	for _, dep := range bi.Deps {
+		info.Deps = append(info.Deps, newModule(dep))
+	}
+
+	if isPlaceholder(info.GitCommit) && revision != "" {
+		info.GitCommit = revision
+	}
+	if info.GitTreeState == "" && modified != "" {
+		info.GitTreeState = "clean"
+		if modified == "true" {
+			info.GitTreeState = "dirty"
+		}
+	}
+	if info.GitCommitDate == "" {
+		info.GitCommitDate = commitDate
+	}
+	if isPlaceholder(info.GitVersion) {
+		switch {
+		case bi.Main.Version != "" && bi.Main.Version != "(devel)":
+			info.GitVersion = bi.Main.Version
+		case revision != "":
+			if len(revision) > 7 {
+				revision = revision[:7]
+			}
+			info.GitVersion = "v0.0.0-master+" + revision
+		}
+	}
+}
+
+func newModule(m *debug.Module) Module {
+	module := Module{
+		Path:    m.Path,
+		Version: m.Version,
+		Sum:     m.Sum,
+	}
+	if m.Replace != nil {
+		replace := newModule(m.Replace)
+		module.Replace = &replace
+	}
+
+	return module
+}
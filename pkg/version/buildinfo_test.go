@@ -0,0 +1,72 @@
+package version
+
+import (
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestApplyBuildInfo(t *testing.T) {
+	vcs := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef"},
+		{Key: "vcs.modified", Value: "true"},
+		{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+	}
+	tests := []struct {
+		name string
+		info Info
+		bi   debug.BuildInfo
+		want Info
+	}{
+		{
+			name: "placeholders from vcs",
+			info: Info{GitVersion: "v0.0.0-master+$Format:%h$", GitCommit: "$Format:%H$"},
+			bi:   debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: vcs},
+			want: Info{GitVersion: "v0.0.0-master+0123456", GitCommit: "0123456789abcdef",
+				GitTreeState: "dirty", GitCommitDate: "2024-01-02T03:04:05Z"},
+		},
+		{
+			name: "module version",
+			info: Info{GitVersion: "v0.0.0-master+$Format:%h$"},
+			bi: debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{{Key: "vcs.modified", Value: "false"}}},
+			want: Info{GitVersion: "v1.2.3", GitTreeState: "clean"},
+		},
+		{
+			name: "ldflags win",
+			info: Info{GitVersion: "v1.0.0", GitCommit: "fedcba", GitTreeState: "clean"},
+			bi:   debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}, Settings: vcs},
+			want: Info{GitVersion: "v1.0.0", GitCommit: "fedcba", GitTreeState: "clean",
+				GitCommitDate: "2024-01-02T03:04:05Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.applyBuildInfo(&tt.bi)
+			if got := info.Brief(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyBuildInfo() = %+v, want %+v", got, tt.want)
+			}
+			if len(info.Settings) != len(tt.bi.Settings) {
+				t.Errorf("applyBuildInfo() settings = %v, want %d", info.Settings, len(tt.bi.Settings))
+			}
+		})
+	}
+}
+
+func TestApplyBuildInfoDeps(t *testing.T) {
+	var info Info
+	info.applyBuildInfo(&debug.BuildInfo{Deps: []*debug.Module{
+		{Path: "example.com/a", Version: "v1.0.0", Sum: "h1:a"},
+		{Path: "example.com/b", Version: "v1.0.0", Replace: &debug.Module{Path: "../b", Version: "(devel)"}},
+	}})
+	if len(info.Deps) != 2 {
+		t.Fatalf("applyBuildInfo() deps = %v, want 2", info.Deps)
+	}
+	if info.Deps[0].Sum != "h1:a" || info.Deps[0].Replace != nil {
+		t.Errorf("deps[0] = %+v", info.Deps[0])
+	}
+	if r := info.Deps[1].Replace; r == nil || r.Path != "../b" {
+		t.Errorf("deps[1].Replace = %+v, want ../b", r)
+	}
+}
@@ -0,0 +1,161 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxMinorSkew is the maximal difference of minor versions between a client
+// and a server which are considered compatible.
+const MaxMinorSkew = 1
+
+// Semver is a semantic version, see https://semver.org.
+type Semver struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease string
+	Metadata   string
+}
+
+// ParseSemver parses a semantic version with an optional "v" prefix,
+// e.g. "v1.2.3-rc.1+abcdef".
+func ParseSemver(s string) (*Semver, error) {
+	v := &Semver{}
+	rest := strings.TrimPrefix(s, "v")
+	if i := strings.Index(rest, "+"); i >= 0 {
+		rest, v.Metadata = rest[:i], rest[i+1:]
+	}
+	if i := strings.Index(rest, "-"); i >= 0 {
+		rest, v.PreRelease = rest[:i], rest[i+1:]
+		if v.PreRelease == "" {
+			return nil, fmt.Errorf("invalid semantic version %q: empty pre-release", s)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid semantic version %q: want MAJOR.MINOR.PATCH", s)
+	}
+	nums := make([]uint64, 3)
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil || (len(p) > 1 && p[0] == '0') {
+			return nil, fmt.Errorf("invalid semantic version %q: bad number %q", s, p)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// String returns the version with a "v" prefix.
+func (v Semver) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o
+// according to semantic version precedence. Build metadata is ignored.
+func (v Semver) Compare(o Semver) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	return comparePreRelease(v.PreRelease, o.PreRelease)
+}
+
+// Compatible reports whether a client of version v may talk to a server of
+// version o: the major versions must be equal and the minor versions may
+// differ by at most MaxMinorSkew.
+func (v Semver) Compatible(o Semver) bool {
+	if v.Major != o.Major {
+		return false
+	}
+	if v.Minor > o.Minor {
+		return v.Minor-o.Minor <= MaxMinorSkew
+	}
+
+	return o.Minor-v.Minor <= MaxMinorSkew
+}
+
+// CheckCompatibility returns an error if the client and server versions
+// cannot be parsed or are not compatible.
+func CheckCompatibility(client, server Info) error {
+	cv, err := ParseSemver(client.GitVersion)
+	if err != nil {
+		return fmt.Errorf("client: %w", err)
+	}
+	sv, err := ParseSemver(server.GitVersion)
+	if err != nil {
+		return fmt.Errorf("server: %w", err)
+	}
+	if !cv.Compatible(*sv) {
+		return fmt.Errorf("client version %s is incompatible with server version %s, "+
+			"major versions must match and minor versions differ by at most %d", cv, sv, MaxMinorSkew)
+	}
+
+	return nil
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// comparePreRelease compares dot separated pre-release identifiers. A version
+// without pre-release has a higher precedence than one with.
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			// numeric identifiers have lower precedence than alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
@@ -0,0 +1,117 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Semver
+		wantErr bool
+	}{
+		{in: "v1.2.3", want: Semver{Major: 1, Minor: 2, Patch: 3}},
+		{in: "1.2.3", want: Semver{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v0.0.0", want: Semver{}},
+		{in: "v1.2.3-rc.1", want: Semver{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1"}},
+		{in: "v1.2.3+abcdef", want: Semver{Major: 1, Minor: 2, Patch: 3, Metadata: "abcdef"}},
+		{in: "v1.2.3-rc.1+abc-def", want: Semver{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1", Metadata: "abc-def"}},
+		{in: "v0.0.0-master+$Format:%h$", want: Semver{PreRelease: "master", Metadata: "$Format:%h$"}},
+		{in: "", wantErr: true},
+		{in: "v1.2", wantErr: true},
+		{in: "v1.2.3.4", wantErr: true},
+		{in: "v1.02.3", wantErr: true},
+		{in: "v1.x.3", wantErr: true},
+		{in: "v-1.2.3", wantErr: true},
+		{in: "v1.2.3-", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSemver(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSemver(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("ParseSemver(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemverString(t *testing.T) {
+	for _, s := range []string{"v1.2.3", "v1.2.3-rc.1", "v1.2.3+abc", "v1.2.3-rc.1+abc"} {
+		v, err := ParseSemver(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.String(); got != s {
+			t.Errorf("ParseSemver(%q).String() = %q", s, got)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.3.0", "v1.2.9", 1},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.2.3+a", "v1.2.3+b", 0},
+		{"v1.0.0-alpha", "v1.0.0", -1},
+		{"v1.0.0", "v1.0.0-alpha", 1},
+		// precedence examples of https://semver.org/#spec-item-11
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-alpha.beta", "v1.0.0-beta", -1},
+		{"v1.0.0-beta", "v1.0.0-beta.2", -1},
+		{"v1.0.0-beta.2", "v1.0.0-beta.11", -1},
+		{"v1.0.0-beta.11", "v1.0.0-rc.1", -1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, err := ParseSemver(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ParseSemver(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a.Compare(*b); got != tt.want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := b.Compare(*a); got != -tt.want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		client, server string
+		wantErr        bool
+	}{
+		{client: "v1.2.3", server: "v1.2.0"},
+		{client: "v1.2.3", server: "v1.3.0"},
+		{client: "v1.3.0", server: "v1.2.3"},
+		{client: "v1.2.3-rc.1", server: "v1.2.3"},
+		{client: "v1.2.3", server: "v1.4.0", wantErr: true},
+		{client: "v1.4.0", server: "v1.2.3", wantErr: true},
+		{client: "v2.0.0", server: "v1.9.0", wantErr: true},
+		{client: "invalid", server: "v1.2.3", wantErr: true},
+		{client: "v1.2.3", server: "invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.client+"_"+tt.server, func(t *testing.T) {
+			err := CheckCompatibility(Info{GitVersion: tt.client}, Info{GitVersion: tt.server})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCompatibility(%s, %s) error = %v, wantErr %v", tt.client, tt.server, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ServerVersionPath is the path on which servers expose their version information.
+const ServerVersionPath = "/version"
+
+// ServerInfo is the version information servers expose at ServerVersionPath.
+// It leaves out the dependency list and build settings of Info, which would
+// let anyone fingerprint the binary.
+type ServerInfo struct {
+	GitVersion    string `json:"gitVersion"`
+	GitCommit     string `json:"gitCommit"`
+	GitTreeState  string `json:"gitTreeState"`
+	GitCommitDate string `json:"gitCommitDate,omitempty"`
+	BuildDate     string `json:"buildDate"`
+	GoVersion     string `json:"goVersion"`
+	Compiler      string `json:"compiler"`
+	Platform      string `json:"platform"`
+}
+
+// Server returns the ServerInfo of info.
+func (info Info) Server() ServerInfo {
+	return ServerInfo{
+		GitVersion:    info.GitVersion,
+		GitCommit:     info.GitCommit,
+		GitTreeState:  info.GitTreeState,
+		GitCommitDate: info.GitCommitDate,
+		BuildDate:     info.BuildDate,
+		GoVersion:     info.GoVersion,
+		Compiler:      info.Compiler,
+		Platform:      info.Platform,
+	}
+}
+
+// GetServerVersion queries the version information of the server listening
+// on the given base url, e.g. http://127.0.0.1:8080, with client,
+// http.DefaultClient if nil.
+func GetServerVersion(ctx context.Context, client *http.Client, server string) (*Info, error) {
+	url := strings.TrimSuffix(server, "/")
+	if !strings.HasSuffix(url, ServerVersionPath) {
+		url += ServerVersionPath
+	}
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get server version from %s failed: %s", url, resp.Status)
+	}
+
+	var info Info
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decode server version from %s failed: %w", url, err)
+	}
+
+	return &info, nil
+}
@@ -15,9 +15,15 @@ const (
 	VersionFalse versionValue = 0
 	VersionTrue  versionValue = 1
 	VersionRaw   versionValue = 2
+	VersionJSON  versionValue = 3
+	VersionYAML  versionValue = 4
 )
 
-const strRawVersion string = "raw"
+const (
+	strRawVersion  string = "raw"
+	strJSONVersion string = "json"
+	strYAMLVersion string = "yaml"
+)
 
 func (v *versionValue) IsBoolFlag() bool {
 	return true
@@ -28,9 +34,16 @@ func (v *versionValue) Get() interface{} {
 }
 
 func (v *versionValue) Set(s string) error {
-	if s == strRawVersion {
+	switch s {
+	case strRawVersion:
 		*v = VersionRaw
 		return nil
+	case strJSONVersion:
+		*v = VersionJSON
+		return nil
+	case strYAMLVersion:
+		*v = VersionYAML
+		return nil
 	}
 	boolVal, err := strconv.ParseBool(s)
 	if boolVal {
@@ -42,8 +55,13 @@ func (v *versionValue) Set(s string) error {
 }
 
 func (v *versionValue) String() string {
-	if *v == VersionRaw {
+	switch *v {
+	case VersionRaw:
 		return strRawVersion
+	case VersionJSON:
+		return strJSONVersion
+	case VersionYAML:
+		return strYAMLVersion
 	}
 	return fmt.Sprintf("%v", *v == VersionTrue)
 }
@@ -70,7 +88,8 @@ func Version(name string, value versionValue, usage string) *versionValue {
 
 const versionFlagName = "version"
 
-var versionFlag = Version(versionFlagName, VersionFalse, "Print version information and quit.")
+var versionFlag = Version(versionFlagName, VersionFalse, "Print version information and quit. "+
+	"Use --version=json or --version=yaml for structured output, --version=raw for Go syntax.")
 
 // AddFlags registers this package's flags on arbitrary FlagSets, such that they point to the
 // same value as the global flags.
@@ -81,11 +100,18 @@ func AddFlags(fs *pflag.FlagSet) {
 // PrintAndExitIfRequested will check if the -version flag was passed
 // and, if so, print the version and exit.
 func PrintAndExitIfRequested() {
-	if *versionFlag == VersionRaw {
-		fmt.Printf("%#v\n", version.Get())
-		os.Exit(0)
-	} else if *versionFlag == VersionTrue {
-		fmt.Printf("%s\n", version.Get())
-		os.Exit(0)
+	info := version.Get().Brief()
+	switch *versionFlag {
+	case VersionRaw:
+		fmt.Printf("%#v\n", info)
+	case VersionJSON:
+		fmt.Printf("%s\n", info.ToJSON())
+	case VersionYAML:
+		fmt.Printf("%s", info.ToYAML())
+	case VersionTrue:
+		fmt.Printf("%s\n", info)
+	default:
+		return
 	}
+	os.Exit(0)
 }
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gosuri/uitable"
+	"gopkg.in/yaml.v3"
 	"runtime"
+	"runtime/debug"
 )
 
 var (
@@ -20,13 +22,18 @@ var (
 
 // Info contains versioning information.
 type Info struct {
-	GitVersion   string `json:"gitVersion"`
-	GitCommit    string `json:"gitCommit"`
-	GitTreeState string `json:"gitTreeState"`
-	BuildDate    string `json:"buildDate"`
-	GoVersion    string `json:"goVersion"`
-	Compiler     string `json:"compiler"`
-	Platform     string `json:"platform"`
+	GitVersion    string `json:"gitVersion"   yaml:"gitVersion"`
+	GitCommit     string `json:"gitCommit"    yaml:"gitCommit"`
+	GitTreeState  string `json:"gitTreeState"            yaml:"gitTreeState"`
+	GitCommitDate string `json:"gitCommitDate,omitempty" yaml:"gitCommitDate,omitempty"`
+	BuildDate     string `json:"buildDate"               yaml:"buildDate"`
+	GoVersion     string `json:"goVersion"               yaml:"goVersion"`
+	Compiler      string `json:"compiler"                yaml:"compiler"`
+	Platform      string `json:"platform"                yaml:"platform"`
+	// Deps is the module dependency list the binary was built with.
+	Deps []Module `json:"deps,omitempty" yaml:"deps,omitempty"`
+	// Settings are the settings used to build the binary.
+	Settings []BuildSetting `json:"settings,omitempty" yaml:"settings,omitempty"`
 }
 
 // String returns info as a human-friendly version string.
@@ -38,6 +45,19 @@ func (info Info) String() string {
 	return info.GitVersion
 }
 
+// Brief returns info without the dependency list and build settings.
+func (info Info) Brief() Info {
+	info.Deps = nil
+	info.Settings = nil
+
+	return info
+}
+
+// Semver parses GitVersion as a semantic version.
+func (info Info) Semver() (*Semver, error) {
+	return ParseSemver(info.GitVersion)
+}
+
 // ToJSON returns the JSON string of version information.
 func (info Info) ToJSON() string {
 	s, _ := json.Marshal(info)
@@ -45,6 +65,13 @@ func (info Info) ToJSON() string {
 	return string(s)
 }
 
+// ToYAML returns the YAML string of version information.
+func (info Info) ToYAML() string {
+	s, _ := yaml.Marshal(info)
+
+	return string(s)
+}
+
 // Text encodes the version information into UTF-8-encoded text and
 // returns the result.
 func (info Info) Text() ([]byte, error) {
@@ -55,6 +82,9 @@ func (info Info) Text() ([]byte, error) {
 	table.AddRow("gitVersion:", info.GitVersion)
 	table.AddRow("gitCommit:", info.GitCommit)
 	table.AddRow("gitTreeState:", info.GitTreeState)
+	if info.GitCommitDate != "" {
+		table.AddRow("gitCommitDate:", info.GitCommitDate)
+	}
 	table.AddRow("buildDate:", info.BuildDate)
 	table.AddRow("goVersion:", info.GoVersion)
 	table.AddRow("compiler:", info.Compiler)
@@ -67,8 +97,8 @@ func (info Info) Text() ([]byte, error) {
 // what code a binary was built from.
 func Get() Info {
 	// These variables typically come from -ldflags settings and in
-	// their absence fallback to the settings in pkg/version/base.go
-	return Info{
+	// their absence fallback to the build information embedded by the go command.
+	info := Info{
 		GitVersion:   GitVersion,
 		GitCommit:    GitCommit,
 		GitTreeState: GitTreeState,
@@ -77,4 +107,9 @@ func Get() Info {
 		Compiler:     runtime.Compiler,
 		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.applyBuildInfo(bi)
+	}
+
+	return info
 }
@@ -0,0 +1,96 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBriefDropsDepsAndSettings(t *testing.T) {
+	info := Info{
+		GitVersion: "v1.2.3",
+		Deps:       []Module{{Path: "example.com/m", Version: "v1.0.0"}},
+		Settings:   []BuildSetting{{Key: "GOOS", Value: "linux"}},
+	}
+	brief := info.Brief()
+	if brief.Deps != nil || brief.Settings != nil {
+		t.Errorf("Brief() = %+v, want no deps and settings", brief)
+	}
+	if info.Deps == nil || info.Settings == nil {
+		t.Errorf("Brief() modified the receiver")
+	}
+	for _, s := range []string{brief.ToJSON(), brief.ToYAML()} {
+		if strings.Contains(s, "deps") || strings.Contains(s, "settings") {
+			t.Errorf("brief output %q contains deps or settings", s)
+		}
+	}
+}
+
+func TestGetServerVersion(t *testing.T) {
+	want := Info{GitVersion: "v1.2.3", Platform: "linux/amd64"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ServerVersionPath {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(want)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		server  string
+		wantErr bool
+	}{
+		{name: "base url", server: ts.URL},
+		{name: "trailing slash", server: ts.URL + "/"},
+		{name: "version path", server: ts.URL + ServerVersionPath},
+		{name: "no scheme", server: strings.TrimPrefix(ts.URL, "http://")},
+		{name: "not found", server: ts.URL + "/missing/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetServerVersion(context.Background(), nil, tt.server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetServerVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.GitVersion != want.GitVersion || got.Platform != want.Platform) {
+				t.Errorf("GetServerVersion() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestServerDropsDepsAndSettings(t *testing.T) {
+	info := Info{
+		GitVersion: "v1.2.3",
+		Platform:   "linux/amd64",
+		Deps:       []Module{{Path: "example.com/m", Version: "v1.0.0"}},
+		Settings:   []BuildSetting{{Key: "-ldflags", Value: "-X main.secret=1"}},
+	}
+	out, err := json.Marshal(info.Server())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"gitVersion", "v1.2.3"},
+		{"platform", "linux/amd64"},
+		{"deps", nil},
+		{"settings", nil},
+	}
+	for _, tt := range tests {
+		if got[tt.key] != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, got[tt.key], tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Package watch contains the events streamed to the watchers of resources.
+package watch
+
+// EventType is the kind of change of an event.
+type EventType string
+
+// Event types.
+const (
+	// Added is the type of events of resources which were created or, for
+	// watchers with selectors, started to match.
+	Added EventType = "ADDED"
+	// Modified is the type of events of resources which were updated.
+	Modified EventType = "MODIFIED"
+	// Deleted is the type of events of resources which were deleted or, for
+	// watchers with selectors, stopped to match.
+	Deleted EventType = "DELETED"
+)
+
+// Event is a change of a resource.
+type Event struct {
+	Type EventType `json:"type"`
+	// Object is the resource after the change, its last state if it was deleted.
+	Object interface{} `json:"object"`
+}
+
+// Interface is a stream of events.
+type Interface interface {
+	// ResultChan returns the events. It is closed when the watcher is
+	// stopped, or if it fell too far behind, by its source.
+	ResultChan() <-chan Event
+	// Stop stops the watcher and closes ResultChan. It may be called more than once.
+	Stop()
+}
@@ -0,0 +1,29 @@
+package watch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEventJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"added", Event{Type: Added, Object: map[string]string{"name": "a"}}, `{"type":"ADDED","object":{"name":"a"}}`},
+		{"modified", Event{Type: Modified, Object: 1}, `{"type":"MODIFIED","object":1}`},
+		{"deleted", Event{Type: Deleted}, `{"type":"DELETED","object":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}